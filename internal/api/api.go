@@ -17,6 +17,7 @@ import (
 
 	"github.com/bluenviron/mediamtx/internal/conf"
 	"github.com/bluenviron/mediamtx/internal/defs"
+	"github.com/bluenviron/mediamtx/internal/externalcmd"
 	"github.com/bluenviron/mediamtx/internal/logger"
 	"github.com/bluenviron/mediamtx/internal/protocols/httpserv"
 	"github.com/bluenviron/mediamtx/internal/restrictnetwork"
@@ -146,6 +147,11 @@ type WebRTCServer interface {
 	APISessionsKick(uuid.UUID) error
 }
 
+// ExternalCmdPool contains methods used by the API.
+type ExternalCmdPool interface {
+	Status() []*externalcmd.Status
+}
+
 type apiParent interface {
 	logger.Writer
 	APIConfigSet(conf *conf.Conf)
@@ -153,18 +159,19 @@ type apiParent interface {
 
 // API is an API server.
 type API struct {
-	Address      string
-	ReadTimeout  conf.StringDuration
-	Conf         *conf.Conf
-	PathManager  PathManager
-	RTSPServer   RTSPServer
-	RTSPSServer  RTSPServer
-	RTMPServer   RTMPServer
-	RTMPSServer  RTMPServer
-	HLSServer    HLSServer
-	WebRTCServer WebRTCServer
-	SRTServer    SRTServer
-	Parent       apiParent
+	Address         string
+	ReadTimeout     conf.StringDuration
+	Conf            *conf.Conf
+	PathManager     PathManager
+	RTSPServer      RTSPServer
+	RTSPSServer     RTSPServer
+	RTMPServer      RTMPServer
+	RTMPSServer     RTMPServer
+	HLSServer       HLSServer
+	WebRTCServer    WebRTCServer
+	SRTServer       SRTServer
+	ExternalCmdPool ExternalCmdPool
+	Parent          apiParent
 
 	httpServer *httpserv.WrappedServer
 	mutex      sync.Mutex
@@ -177,6 +184,8 @@ func (a *API) Initialize() error {
 
 	group := router.Group("/")
 
+	group.GET("/v3/config/schema", a.onConfigSchema)
+
 	group.GET("/v3/config/global/get", a.onConfigGlobalGet)
 	group.PATCH("/v3/config/global/patch", a.onConfigGlobalPatch)
 
@@ -193,6 +202,10 @@ func (a *API) Initialize() error {
 	group.GET("/v3/paths/list", a.onPathsList)
 	group.GET("/v3/paths/get/*name", a.onPathsGet)
 
+	if !interfaceIsEmpty(a.ExternalCmdPool) {
+		group.GET("/v3/hooks/list", a.onHooksList)
+	}
+
 	if !interfaceIsEmpty(a.HLSServer) {
 		group.GET("/v3/hlsmuxers/list", a.onHLSMuxersList)
 		group.GET("/v3/hlsmuxers/get/*name", a.onHLSMuxersGet)
@@ -280,6 +293,10 @@ func (a *API) writeError(ctx *gin.Context, status int, err error) {
 	})
 }
 
+func (a *API) onConfigSchema(ctx *gin.Context) {
+	ctx.Data(http.StatusOK, "application/schema+json", conf.Schema())
+}
+
 func (a *API) onConfigGlobalGet(ctx *gin.Context) {
 	a.mutex.Lock()
 	c := a.Conf
@@ -299,7 +316,11 @@ func (a *API) onConfigGlobalPatch(ctx *gin.Context) {
 	a.mutex.Lock()
 	defer a.mutex.Unlock()
 
-	newConf := a.Conf.Clone()
+	newConf, err := a.Conf.Clone()
+	if err != nil {
+		a.writeError(ctx, http.StatusInternalServerError, err)
+		return
+	}
 
 	newConf.PatchGlobal(&c)
 
@@ -337,7 +358,11 @@ func (a *API) onConfigPathDefaultsPatch(ctx *gin.Context) {
 	a.mutex.Lock()
 	defer a.mutex.Unlock()
 
-	newConf := a.Conf.Clone()
+	newConf, err := a.Conf.Clone()
+	if err != nil {
+		a.writeError(ctx, http.StatusInternalServerError, err)
+		return
+	}
 
 	newConf.PatchPathDefaults(&p)
 
@@ -414,7 +439,11 @@ func (a *API) onConfigPathsAdd(ctx *gin.Context) { //nolint:dupl
 	a.mutex.Lock()
 	defer a.mutex.Unlock()
 
-	newConf := a.Conf.Clone()
+	newConf, err := a.Conf.Clone()
+	if err != nil {
+		a.writeError(ctx, http.StatusInternalServerError, err)
+		return
+	}
 
 	err = newConf.AddPath(name, &p)
 	if err != nil {
@@ -451,7 +480,11 @@ func (a *API) onConfigPathsPatch(ctx *gin.Context) { //nolint:dupl
 	a.mutex.Lock()
 	defer a.mutex.Unlock()
 
-	newConf := a.Conf.Clone()
+	newConf, err := a.Conf.Clone()
+	if err != nil {
+		a.writeError(ctx, http.StatusInternalServerError, err)
+		return
+	}
 
 	err = newConf.PatchPath(name, &p)
 	if err != nil {
@@ -492,7 +525,11 @@ func (a *API) onConfigPathsReplace(ctx *gin.Context) { //nolint:dupl
 	a.mutex.Lock()
 	defer a.mutex.Unlock()
 
-	newConf := a.Conf.Clone()
+	newConf, err := a.Conf.Clone()
+	if err != nil {
+		a.writeError(ctx, http.StatusInternalServerError, err)
+		return
+	}
 
 	err = newConf.ReplacePath(name, &p)
 	if err != nil {
@@ -526,9 +563,13 @@ func (a *API) onConfigPathsDelete(ctx *gin.Context) {
 	a.mutex.Lock()
 	defer a.mutex.Unlock()
 
-	newConf := a.Conf.Clone()
+	newConf, err := a.Conf.Clone()
+	if err != nil {
+		a.writeError(ctx, http.StatusInternalServerError, err)
+		return
+	}
 
-	err := newConf.RemovePath(name)
+	err = newConf.RemovePath(name)
 	if err != nil {
 		if errors.Is(err, conf.ErrPathNotFound) {
 			a.writeError(ctx, http.StatusNotFound, err)
@@ -588,6 +629,47 @@ func (a *API) onPathsGet(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, data)
 }
 
+func (a *API) onHooksList(ctx *gin.Context) {
+	statuses := a.ExternalCmdPool.Status()
+
+	data := &defs.APIHookList{
+		Items: make([]*defs.APIHook, len(statuses)),
+	}
+
+	for i, st := range statuses {
+		item := &defs.APIHook{
+			Name:           st.Name,
+			Path:           st.Path,
+			Command:        st.Command,
+			Created:        st.Created,
+			Restarts:       st.Restarts,
+			LastExitStatus: st.LastExitStatus,
+		}
+
+		if st.PID != 0 {
+			pid := st.PID
+			item.PID = &pid
+		}
+
+		if !st.Started.IsZero() {
+			started := st.Started
+			item.Started = &started
+		}
+
+		data.Items[i] = item
+	}
+
+	data.ItemCount = len(data.Items)
+	pageCount, err := paginate(&data.Items, ctx.Query("itemsPerPage"), ctx.Query("page"))
+	if err != nil {
+		a.writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	data.PageCount = pageCount
+
+	ctx.JSON(http.StatusOK, data)
+}
+
 func (a *API) onRTSPConnsList(ctx *gin.Context) {
 	data, err := a.RTSPServer.APIConnsList()
 	if err != nil {
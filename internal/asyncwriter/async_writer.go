@@ -3,9 +3,12 @@ package asyncwriter
 
 import (
 	"fmt"
+	"sync"
+	"sync/atomic"
 
 	"github.com/bluenviron/gortsplib/v4/pkg/ringbuffer"
 
+	"github.com/bluenviron/mediamtx/internal/conf"
 	"github.com/bluenviron/mediamtx/internal/logger"
 )
 
@@ -13,6 +16,13 @@ import (
 type Writer struct {
 	writeErrLogger logger.Writer
 	buffer         *ringbuffer.RingBuffer
+	overflow       conf.WriteQueueOverflow
+
+	mutex           sync.Mutex
+	waitingKeyFrame bool
+	overflowed      bool
+	closeOnce       sync.Once
+	droppedUnits    *uint64
 
 	// out
 	err chan error
@@ -28,10 +38,17 @@ func New(
 	return &Writer{
 		writeErrLogger: logger.NewLimitedLogger(parent),
 		buffer:         buffer,
+		droppedUnits:   new(uint64),
 		err:            make(chan error),
 	}
 }
 
+// SetOverflowPolicy sets the action performed when the queue is full.
+// It must be called before Start().
+func (w *Writer) SetOverflowPolicy(overflow conf.WriteQueueOverflow) {
+	w.overflow = overflow
+}
+
 // Start starts the writer routine.
 func (w *Writer) Start() {
 	go w.run()
@@ -39,7 +56,7 @@ func (w *Writer) Start() {
 
 // Stop stops the writer routine.
 func (w *Writer) Stop() {
-	w.buffer.Close()
+	w.closeBuffer()
 	<-w.err
 }
 
@@ -48,6 +65,16 @@ func (w *Writer) Error() chan error {
 	return w.err
 }
 
+// DroppedUnits returns the number of units that have been discarded
+// because the queue was full or because a key frame was awaited.
+func (w *Writer) DroppedUnits() uint64 {
+	return atomic.LoadUint64(w.droppedUnits)
+}
+
+func (w *Writer) closeBuffer() {
+	w.closeOnce.Do(w.buffer.Close)
+}
+
 func (w *Writer) run() {
 	w.err <- w.runInner()
 }
@@ -56,6 +83,13 @@ func (w *Writer) runInner() error {
 	for {
 		cb, ok := w.buffer.Pull()
 		if !ok {
+			w.mutex.Lock()
+			overflowed := w.overflowed
+			w.mutex.Unlock()
+
+			if overflowed {
+				return fmt.Errorf("write queue is full")
+			}
 			return fmt.Errorf("terminated")
 		}
 
@@ -68,8 +102,56 @@ func (w *Writer) runInner() error {
 
 // Push appends an element to the queue.
 func (w *Writer) Push(cb func() error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	w.pushInner(cb, false)
+}
+
+// PushVideo appends a video element to the queue.
+// After an overflow, video elements are discarded until a random access one is pushed.
+func (w *Writer) PushVideo(cb func() error, randomAccess bool) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.waitingKeyFrame {
+		if !randomAccess {
+			atomic.AddUint64(w.droppedUnits, 1)
+			return
+		}
+		w.waitingKeyFrame = false
+	}
+
+	w.pushInner(cb, true)
+}
+
+func (w *Writer) pushInner(cb func() error, isVideo bool) {
+	if w.overflowed && w.overflow == conf.WriteQueueOverflowDisconnect {
+		atomic.AddUint64(w.droppedUnits, 1)
+		return
+	}
+
 	ok := w.buffer.Push(cb)
-	if !ok {
-		w.writeErrLogger.Log(logger.Warn, "write queue is full")
+	if ok {
+		return
+	}
+
+	atomic.AddUint64(w.droppedUnits, 1)
+
+	switch w.overflow {
+	case conf.WriteQueueOverflowDisconnect:
+		w.overflowed = true
+		w.closeBuffer()
+
+	default:
+		if !isVideo {
+			w.writeErrLogger.Log(logger.Warn, "write queue is full")
+			return
+		}
+
+		if !w.waitingKeyFrame {
+			w.writeErrLogger.Log(logger.Warn, "write queue is full, discarding video until next key frame")
+		}
+		w.waitingKeyFrame = true
 	}
 }
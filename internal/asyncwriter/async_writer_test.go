@@ -0,0 +1,102 @@
+package asyncwriter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bluenviron/mediamtx/internal/conf"
+	"github.com/bluenviron/mediamtx/internal/logger"
+)
+
+type nilLogger struct{}
+
+func (nilLogger) Log(_ logger.Level, _ string, _ ...interface{}) {
+}
+
+func TestWriterDropUntilKeyFrame(t *testing.T) {
+	out := make(chan string, 16)
+
+	cb := func(name string) func() error {
+		return func() error {
+			out <- name
+			return nil
+		}
+	}
+
+	w := New(2, nilLogger{})
+
+	w.PushVideo(cb("v1"), true)
+	w.PushVideo(cb("v2"), false)
+	w.PushVideo(cb("v3"), false) // queue is full
+	w.Push(cb("a1"))             // queue is full
+	require.Equal(t, uint64(2), w.DroppedUnits())
+
+	w.Start()
+	defer w.Stop()
+
+	require.Equal(t, "v1", <-out)
+	require.Equal(t, "v2", <-out)
+
+	// audio is not affected by the wait for a key frame
+	w.Push(cb("a2"))
+	require.Equal(t, "a2", <-out)
+
+	w.PushVideo(cb("v4"), false)
+	w.PushVideo(cb("v5"), true)
+	require.Equal(t, "v5", <-out)
+
+	w.PushVideo(cb("v6"), false)
+	require.Equal(t, "v6", <-out)
+	require.Equal(t, uint64(3), w.DroppedUnits())
+}
+
+func TestWriterAudioOverflow(t *testing.T) {
+	out := make(chan string, 16)
+
+	cb := func(name string) func() error {
+		return func() error {
+			out <- name
+			return nil
+		}
+	}
+
+	w := New(2, nilLogger{})
+
+	w.Push(cb("a1"))
+	w.Push(cb("a2"))
+	w.Push(cb("a3")) // queue is full
+	require.Equal(t, uint64(1), w.DroppedUnits())
+
+	w.Start()
+	defer w.Stop()
+
+	require.Equal(t, "a1", <-out)
+	require.Equal(t, "a2", <-out)
+
+	// an audio overflow must not cause video to be discarded
+	w.PushVideo(cb("v1"), false)
+	require.Equal(t, "v1", <-out)
+	require.Equal(t, uint64(1), w.DroppedUnits())
+}
+
+func TestWriterDisconnect(t *testing.T) {
+	w := New(2, nilLogger{})
+	w.SetOverflowPolicy(conf.WriteQueueOverflowDisconnect)
+
+	for i := 0; i < 2; i++ {
+		w.Push(func() error {
+			t.Errorf("should not happen")
+			return nil
+		})
+	}
+
+	w.Push(func() error { return nil }) // queue is full
+	w.PushVideo(func() error { return nil }, true)
+	require.Equal(t, uint64(2), w.DroppedUnits())
+
+	w.Start()
+
+	err := <-w.Error()
+	require.EqualError(t, err, "write queue is full")
+}
@@ -0,0 +1,21 @@
+// Package captions contains utilities to extract and decode CEA-608/708 closed captions.
+package captions
+
+// CCType is the type of a caption data pair.
+type CCType int
+
+// caption data types.
+const (
+	CCTypeNTSCField1 CCType = 0
+	CCTypeNTSCField2 CCType = 1
+	CCTypeDTVCCData  CCType = 2
+	CCTypeDTVCCStart CCType = 3
+)
+
+// CCData is a caption data pair.
+// Specification: ANSI/CTA-708-E, 4.4 Caption Data Packet
+type CCData struct {
+	Type  CCType
+	Data1 byte
+	Data2 byte
+}
@@ -0,0 +1,307 @@
+package captions
+
+import (
+	"strings"
+)
+
+const (
+	rowCount    = 15
+	columnCount = 32
+)
+
+type decoderMode int
+
+const (
+	decoderModePopOn decoderMode = iota
+	decoderModeRollUp
+	decoderModePaintOn
+)
+
+// rows addressed by preamble address codes, indexed by the first byte and by bit 5 of the second byte.
+var pacRows = map[byte][2]int{
+	0x10: {10, 10},
+	0x11: {0, 1},
+	0x12: {2, 3},
+	0x13: {11, 12},
+	0x14: {13, 14},
+	0x15: {4, 5},
+	0x16: {6, 7},
+	0x17: {8, 9},
+}
+
+// characters of the basic set that differ from ASCII.
+var basicChars = map[byte]rune{
+	0x2A: 'á',
+	0x5C: 'é',
+	0x5E: 'í',
+	0x5F: 'ó',
+	0x60: 'ú',
+	0x7B: 'ç',
+	0x7C: '÷',
+	0x7D: 'Ñ',
+	0x7E: 'ñ',
+	0x7F: '█',
+}
+
+var (
+	specialChars   = []rune("®°½¿™¢£♪à èâêîôû")
+	extendedChars1 = []rune("ÁÉÓÚÜü‘¡*’—©℠•“”ÀÂÇÈÊËëÎÏïÔÙùÛ«»")
+	extendedChars2 = []rune("ÃãÍÌìÒòÕõ{}\\^_|~ÄäÖöß¥¤¦ÅåØø┌┐└┘")
+)
+
+type memory [rowCount][]rune
+
+func (m *memory) clear() {
+	*m = memory{}
+}
+
+func (m *memory) text() string {
+	var lines []string
+
+	for _, row := range m {
+		line := strings.TrimSpace(string(row))
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Decoder decodes the first caption channel (CC1) of CEA-608 captions into text.
+// Pop-on, roll-up and paint-on captions are supported.
+// Specification: ANSI/CTA-608-E
+type Decoder struct {
+	mode         decoderMode
+	rollUpRows   int
+	displayed    memory
+	nonDisplayed memory
+	row          int
+	col          int
+	channel      int
+	lastControl  [2]byte
+	changed      bool
+}
+
+// Decode decodes caption data.
+// It returns the displayed text when it has changed.
+func (d *Decoder) Decode(data []CCData) (string, bool) {
+	for _, cc := range data {
+		if cc.Type == CCTypeNTSCField1 {
+			// remove parity bits
+			d.decodePair(cc.Data1&0x7F, cc.Data2&0x7F)
+		}
+	}
+
+	if !d.changed {
+		return "", false
+	}
+
+	d.changed = false
+	return d.displayed.text(), true
+}
+
+func (d *Decoder) decodePair(b1 byte, b2 byte) {
+	switch {
+	// padding
+	case b1 == 0 && b2 == 0:
+
+	// control codes
+	case b1 >= 0x10 && b1 <= 0x1F:
+		// control codes are transmitted twice
+		if d.lastControl == [2]byte{b1, b2} {
+			d.lastControl = [2]byte{}
+			return
+		}
+		d.lastControl = [2]byte{b1, b2}
+
+		d.channel = int(b1&0x08) >> 3
+		if d.channel == 0 {
+			d.decodeControl(b1&0xF7, b2)
+		}
+
+	// extended data services
+	case b1 < 0x10:
+		d.lastControl = [2]byte{}
+
+	// characters
+	default:
+		d.lastControl = [2]byte{}
+
+		if d.channel == 0 {
+			d.writeChar(basicChar(b1))
+			if b2 >= 0x20 {
+				d.writeChar(basicChar(b2))
+			}
+		}
+	}
+}
+
+func (d *Decoder) decodeControl(b1 byte, b2 byte) {
+	switch {
+	// miscellaneous control codes
+	case b1 == 0x14 && b2 >= 0x20 && b2 <= 0x2F:
+		d.decodeMiscControl(b2)
+
+	// tab offsets
+	case b1 == 0x17 && b2 >= 0x21 && b2 <= 0x23:
+		d.col = min(d.col+int(b2-0x20), columnCount-1)
+
+	// mid-row codes
+	case b1 == 0x11 && b2 >= 0x20 && b2 <= 0x2F:
+		d.writeChar(' ')
+
+	// special characters
+	case b1 == 0x11 && b2 >= 0x30 && b2 <= 0x3F:
+		d.writeChar(specialChars[b2-0x30])
+
+	// extended characters, that replace the previous character
+	case b1 == 0x12 && b2 >= 0x20 && b2 <= 0x3F:
+		d.backspace()
+		d.writeChar(extendedChars1[b2-0x20])
+
+	case b1 == 0x13 && b2 >= 0x20 && b2 <= 0x3F:
+		d.backspace()
+		d.writeChar(extendedChars2[b2-0x20])
+
+	// preamble address codes
+	case b2 >= 0x40:
+		d.decodePAC(b1, b2)
+	}
+}
+
+func (d *Decoder) decodeMiscControl(b2 byte) {
+	switch b2 {
+	case 0x20: // resume caption loading
+		d.mode = decoderModePopOn
+
+	case 0x21: // backspace
+		d.backspace()
+
+	case 0x24: // delete to end of row
+		mem := d.targetMemory()
+		if len(mem[d.row]) > d.col {
+			mem[d.row] = mem[d.row][:d.col]
+			d.setChanged()
+		}
+
+	case 0x25, 0x26, 0x27: // roll-up captions
+		if d.mode != decoderModeRollUp {
+			d.mode = decoderModeRollUp
+			d.displayed.clear()
+			d.nonDisplayed.clear()
+			d.row = rowCount - 1
+			d.col = 0
+			d.changed = true
+		}
+		d.rollUpRows = int(b2-0x25) + 2
+
+	case 0x29: // resume direct captioning
+		d.mode = decoderModePaintOn
+
+	case 0x2C: // erase displayed memory
+		d.displayed.clear()
+		d.changed = true
+
+	case 0x2D: // carriage return
+		if d.mode == decoderModeRollUp {
+			// move rows of the window up and clear the others
+			top := max(d.row-d.rollUpRows+1, 0)
+			var mem memory
+			for i := top; i < d.row; i++ {
+				mem[i] = d.displayed[i+1]
+			}
+			d.displayed = mem
+			d.col = 0
+			d.changed = true
+		}
+
+	case 0x2E: // erase non-displayed memory
+		d.nonDisplayed.clear()
+
+	case 0x2F: // end of caption
+		d.displayed, d.nonDisplayed = d.nonDisplayed, d.displayed
+		d.mode = decoderModePopOn
+		d.changed = true
+	}
+}
+
+func (d *Decoder) decodePAC(b1 byte, b2 byte) {
+	rows, ok := pacRows[b1]
+	if !ok {
+		return
+	}
+
+	row := rows[(b2&0x20)>>5]
+
+	// in roll-up mode, the window is moved to the new base row
+	if d.mode == decoderModeRollUp && row != d.row {
+		var mem memory
+		for i := 0; i < d.rollUpRows; i++ {
+			if d.row-i >= 0 && row-i >= 0 {
+				mem[row-i] = d.displayed[d.row-i]
+			}
+		}
+		d.displayed = mem
+		d.changed = true
+	}
+
+	d.row = row
+
+	if (b2 & 0x10) != 0 {
+		d.col = int((b2&0x0E)>>1) * 4
+	} else {
+		d.col = 0
+	}
+}
+
+func (d *Decoder) targetMemory() *memory {
+	if d.mode == decoderModePopOn {
+		return &d.nonDisplayed
+	}
+	return &d.displayed
+}
+
+func (d *Decoder) setChanged() {
+	if d.mode != decoderModePopOn {
+		d.changed = true
+	}
+}
+
+func (d *Decoder) writeChar(r rune) {
+	mem := d.targetMemory()
+	row := mem[d.row]
+
+	for len(row) <= d.col {
+		row = append(row, ' ')
+	}
+	row[d.col] = r
+	mem[d.row] = row
+
+	if d.col < columnCount-1 {
+		d.col++
+	}
+
+	d.setChanged()
+}
+
+func (d *Decoder) backspace() {
+	if d.col == 0 {
+		return
+	}
+
+	d.col--
+
+	mem := d.targetMemory()
+	if len(mem[d.row]) > d.col {
+		mem[d.row] = mem[d.row][:d.col]
+		d.setChanged()
+	}
+}
+
+func basicChar(b byte) rune {
+	if r, ok := basicChars[b]; ok {
+		return r
+	}
+	return rune(b)
+}
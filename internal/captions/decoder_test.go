@@ -0,0 +1,76 @@
+package captions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func field1(pairs ...[2]byte) []CCData {
+	ret := make([]CCData, len(pairs))
+	for i, p := range pairs {
+		ret[i] = CCData{Type: CCTypeNTSCField1, Data1: p[0], Data2: p[1]}
+	}
+	return ret
+}
+
+func TestDecoderPopOn(t *testing.T) {
+	d := &Decoder{}
+
+	_, changed := d.Decode(field1(
+		[2]byte{0x94, 0x20}, [2]byte{0x94, 0x20}, // RCL
+		[2]byte{0x94, 0xae}, [2]byte{0x94, 0xae}, // ENM
+		[2]byte{0x94, 0xd0}, [2]byte{0x94, 0xd0}, // PAC row 15
+		[2]byte{0xc8, 0xc5}, [2]byte{0x4c, 0x4c}, [2]byte{0x4f, 0x80}, // HELLO
+		[2]byte{0x11, 0x37}, [2]byte{0x11, 0x37}, // ♪
+	))
+	require.Equal(t, false, changed)
+
+	text, changed := d.Decode(field1(
+		[2]byte{0x94, 0x2f}, [2]byte{0x94, 0x2f}, // EOC
+	))
+	require.Equal(t, true, changed)
+	require.Equal(t, "HELLO♪", text)
+
+	text, changed = d.Decode(field1(
+		[2]byte{0x94, 0x2c}, [2]byte{0x94, 0x2c}, // EDM
+	))
+	require.Equal(t, true, changed)
+	require.Equal(t, "", text)
+}
+
+func TestDecoderRollUp(t *testing.T) {
+	d := &Decoder{}
+
+	text, changed := d.Decode(field1(
+		[2]byte{0x94, 0x25}, [2]byte{0x94, 0x25}, // RU2
+		[2]byte{0x94, 0x2d}, [2]byte{0x94, 0x2d}, // CR
+		[2]byte{0xc8, 0x49}, // HI
+	))
+	require.Equal(t, true, changed)
+	require.Equal(t, "HI", text)
+
+	text, changed = d.Decode(field1(
+		[2]byte{0x94, 0x2d}, [2]byte{0x94, 0x2d}, // CR
+		[2]byte{0x54, 0xc8}, [2]byte{0xc5, 0x52}, [2]byte{0xc5, 0x80}, // THERE
+	))
+	require.Equal(t, true, changed)
+	require.Equal(t, "HI\nTHERE", text)
+
+	text, changed = d.Decode(field1(
+		[2]byte{0x94, 0x2d}, [2]byte{0x94, 0x2d}, // CR
+		[2]byte{0xd9, 0x4f}, [2]byte{0x55, 0x80}, // YOU
+	))
+	require.Equal(t, true, changed)
+	require.Equal(t, "THERE\nYOU", text)
+}
+
+func TestDecoderIgnoreSecondChannel(t *testing.T) {
+	d := &Decoder{}
+
+	_, changed := d.Decode(field1(
+		[2]byte{0x1c, 0x29}, [2]byte{0x1c, 0x29}, // RDC, CC2
+		[2]byte{0xc8, 0x49}, // HI
+	))
+	require.Equal(t, false, changed)
+}
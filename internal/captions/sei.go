@@ -0,0 +1,136 @@
+package captions
+
+import (
+	"bytes"
+
+	"github.com/bluenviron/mediacommon/pkg/codecs/h264"
+	"github.com/bluenviron/mediacommon/pkg/codecs/h265"
+)
+
+const (
+	seiPayloadTypeUserDataRegistered = 4
+
+	t35CountryCodeUSA   = 0xB5
+	t35ProviderCodeATSC = 0x0031
+	atscUserDataTypeCC  = 0x03
+)
+
+var atscUserIdentifier = []byte("GA94")
+
+// FromH264 extracts caption data from SEI NALUs of a H264 access unit.
+func FromH264(au [][]byte) []CCData {
+	var ret []CCData
+
+	for _, nalu := range au {
+		if len(nalu) < 2 || h264.NALUType(nalu[0]&0x1F) != h264.NALUTypeSEI {
+			continue
+		}
+
+		ret = append(ret, fromSEI(h264.EmulationPreventionRemove(nalu[1:]))...)
+	}
+
+	return ret
+}
+
+// FromH265 extracts caption data from SEI NALUs of a H265 access unit.
+func FromH265(au [][]byte) []CCData {
+	var ret []CCData
+
+	for _, nalu := range au {
+		if len(nalu) < 3 || h265.NALUType((nalu[0]>>1)&0b111111) != h265.NALUType_PREFIX_SEI_NUT {
+			continue
+		}
+
+		ret = append(ret, fromSEI(h264.EmulationPreventionRemove(nalu[2:]))...)
+	}
+
+	return ret
+}
+
+// fromSEI extracts caption data from the RBSP of a SEI NALU.
+// Specification: ITU-T Rec. H.264, 7.3.2.3 Supplemental enhancement information RBSP syntax
+func fromSEI(buf []byte) []CCData {
+	var ret []CCData
+
+	// the last byte contains the RBSP trailing bits
+	for len(buf) > 1 {
+		payloadType := 0
+		for len(buf) > 0 && buf[0] == 0xFF {
+			payloadType += 255
+			buf = buf[1:]
+		}
+		if len(buf) == 0 {
+			return ret
+		}
+		payloadType += int(buf[0])
+		buf = buf[1:]
+
+		payloadSize := 0
+		for len(buf) > 0 && buf[0] == 0xFF {
+			payloadSize += 255
+			buf = buf[1:]
+		}
+		if len(buf) == 0 {
+			return ret
+		}
+		payloadSize += int(buf[0])
+		buf = buf[1:]
+
+		if payloadSize > len(buf) {
+			return ret
+		}
+
+		if payloadType == seiPayloadTypeUserDataRegistered {
+			ret = append(ret, fromUserDataRegistered(buf[:payloadSize])...)
+		}
+
+		buf = buf[payloadSize:]
+	}
+
+	return ret
+}
+
+// fromUserDataRegistered extracts caption data from a user_data_registered_itu_t_t35 SEI message.
+// Specification: ATSC A/72 Part 1, 6.4.2 Caption Data
+func fromUserDataRegistered(buf []byte) []CCData {
+	if len(buf) < 10 ||
+		buf[0] != t35CountryCodeUSA ||
+		(uint16(buf[1])<<8|uint16(buf[2])) != t35ProviderCodeATSC ||
+		!bytes.Equal(buf[3:7], atscUserIdentifier) ||
+		buf[7] != atscUserDataTypeCC {
+		return nil
+	}
+
+	processCCData := (buf[8] & 0b01000000) != 0
+	if !processCCData {
+		return nil
+	}
+
+	ccCount := int(buf[8] & 0b00011111)
+
+	// skip flags and em_data
+	buf = buf[10:]
+
+	if len(buf) < ccCount*3 {
+		return nil
+	}
+
+	var ret []CCData
+
+	for i := 0; i < ccCount; i++ {
+		b := buf[i*3:]
+
+		ccValid := (b[0] & 0b100) != 0
+		if !ccValid {
+			continue
+		}
+
+		ret = append(ret, CCData{
+			Type:  CCType(b[0] & 0b11),
+			Data1: b[1],
+			Data2: b[2],
+		})
+	}
+
+	return ret
+}
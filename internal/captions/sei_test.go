@@ -0,0 +1,56 @@
+package captions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var seiPayload = []byte{
+	0x04, 0x14, // user data registered, size
+	0xb5, 0x00, 0x31, 'G', 'A', '9', '4', 0x03,
+	0x43, 0xff, // process_cc_data_flag, cc_count = 3
+	0xfc, 0x94, 0x20, // field 1, valid
+	0xfd, 0x80, 0x80, // field 2, valid
+	0xfa, 0x00, 0x00, // DTVCC, not valid
+	0xff,
+	0x80, // RBSP trailing bits
+}
+
+func TestFromH264(t *testing.T) {
+	au := [][]byte{
+		{0x09, 0xf0}, // AUD
+		append([]byte{0x06}, seiPayload...),
+		{0x65, 0x88, 0x84, 0x00},
+	}
+
+	require.Equal(t, []CCData{
+		{Type: CCTypeNTSCField1, Data1: 0x94, Data2: 0x20},
+		{Type: CCTypeNTSCField2, Data1: 0x80, Data2: 0x80},
+	}, FromH264(au))
+}
+
+func TestFromH265(t *testing.T) {
+	au := [][]byte{
+		append([]byte{0x4e, 0x01}, seiPayload...),
+		{0x26, 0x01, 0xaf, 0x08},
+	}
+
+	require.Equal(t, []CCData{
+		{Type: CCTypeNTSCField1, Data1: 0x94, Data2: 0x20},
+		{Type: CCTypeNTSCField2, Data1: 0x80, Data2: 0x80},
+	}, FromH265(au))
+}
+
+func TestFromH264OtherUserData(t *testing.T) {
+	au := [][]byte{
+		{
+			0x06,
+			0x05, 0x03, 0x01, 0x02, 0x03, // user data unregistered
+			0x04, 0x08, 0xb5, 0x00, 0x2f, 0x03, 0x01, 0x02, 0x03, 0x04, // DirecTV
+			0x80,
+		},
+	}
+
+	require.Equal(t, []CCData(nil), FromH264(au))
+}
@@ -0,0 +1,53 @@
+package conf
+
+import (
+	"fmt"
+	"strings"
+)
+
+const composeSourcePrefix = "compose:"
+
+// ComposeSourceEntry is an entry of a compose source.
+type ComposeSourceEntry struct {
+	// name of the path to read.
+	Path string
+
+	// type of the medias to read (video, audio or application).
+	// When empty, all medias are read.
+	MediaType string
+}
+
+// ParseComposeSource parses a compose source, in the format
+// "compose:path1#video,path2#audio".
+func ParseComposeSource(source string) ([]ComposeSourceEntry, error) {
+	if !strings.HasPrefix(source, composeSourcePrefix) {
+		return nil, fmt.Errorf("'%s' is not a compose source", source)
+	}
+
+	var entries []ComposeSourceEntry //nolint:prealloc
+
+	for _, part := range strings.Split(source[len(composeSourcePrefix):], ",") {
+		var e ComposeSourceEntry
+
+		e.Path, e.MediaType, _ = strings.Cut(strings.TrimSpace(part), "#")
+
+		// skip validation of paths that contain regular expression groups
+		if !strings.Contains(e.Path, "$G") {
+			err := isValidPathName(e.Path)
+			if err != nil {
+				return nil, fmt.Errorf("invalid path name '%s' in compose source: %w", e.Path, err)
+			}
+		}
+
+		switch e.MediaType {
+		case "", "video", "audio", "application":
+
+		default:
+			return nil, fmt.Errorf("invalid media type '%s' in compose source", e.MediaType)
+		}
+
+		entries = append(entries, e)
+	}
+
+	return entries, nil
+}
@@ -6,7 +6,9 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	gourl "net/url"
 	"os"
+	"path/filepath"
 	"reflect"
 	"sort"
 	"strings"
@@ -55,6 +57,16 @@ func contains(list []headers.AuthMethod, item headers.AuthMethod) bool {
 	return false
 }
 
+func checkHook(name string, v string) error {
+	if strings.HasPrefix(v, "http://") || strings.HasPrefix(v, "https://") {
+		_, err := gourl.Parse(v)
+		if err != nil {
+			return fmt.Errorf("'%s' is not a valid URL: %w", name, err)
+		}
+	}
+	return nil
+}
+
 func copyStructFields(dest interface{}, source interface{}) {
 	rvsource := reflect.ValueOf(source).Elem()
 	rvdest := reflect.ValueOf(dest)
@@ -101,6 +113,15 @@ type Conf struct {
 	RunOnConnect              string          `json:"runOnConnect"`
 	RunOnConnectRestart       bool            `json:"runOnConnectRestart"`
 	RunOnDisconnect           string          `json:"runOnDisconnect"`
+	RunOnAuthFailure          string          `json:"runOnAuthFailure"`
+	HookOutputLevel           LogLevel        `json:"hookOutputLevel"`
+	HookTerminationTimeout    StringDuration  `json:"hookTerminationTimeout"`
+	HookRestartMaxPause       StringDuration  `json:"hookRestartMaxPause"`
+	HookRestartMaxRetries     int             `json:"hookRestartMaxRetries"`
+	WebhookSecret             Secret          `json:"webhookSecret"`
+	WebhookTimeout            StringDuration  `json:"webhookTimeout"`
+	WebhookMaxRetries         int             `json:"webhookMaxRetries"`
+	WebhookQueueSize          int             `json:"webhookQueueSize"`
 
 	// API
 	API        bool   `json:"api"`
@@ -127,54 +148,58 @@ type Conf struct {
 	AuthMethods       AuthMethods `json:"authMethods"`
 
 	// RTMP server
-	RTMP           bool       `json:"rtmp"`
-	RTMPDisable    *bool      `json:"rtmpDisable,omitempty"` // deprecated
-	RTMPAddress    string     `json:"rtmpAddress"`
-	RTMPEncryption Encryption `json:"rtmpEncryption"`
-	RTMPSAddress   string     `json:"rtmpsAddress"`
-	RTMPServerKey  string     `json:"rtmpServerKey"`
-	RTMPServerCert string     `json:"rtmpServerCert"`
+	RTMP                   bool               `json:"rtmp"`
+	RTMPDisable            *bool              `json:"rtmpDisable,omitempty"` // deprecated
+	RTMPAddress            string             `json:"rtmpAddress"`
+	RTMPEncryption         Encryption         `json:"rtmpEncryption"`
+	RTMPSAddress           string             `json:"rtmpsAddress"`
+	RTMPServerKey          string             `json:"rtmpServerKey"`
+	RTMPServerCert         string             `json:"rtmpServerCert"`
+	RTMPWriteQueueOverflow WriteQueueOverflow `json:"rtmpWriteQueueOverflow"`
 
 	// HLS server
-	HLS                bool           `json:"hls"`
-	HLSDisable         *bool          `json:"hlsDisable,omitempty"` // depreacted
-	HLSAddress         string         `json:"hlsAddress"`
-	HLSEncryption      bool           `json:"hlsEncryption"`
-	HLSServerKey       string         `json:"hlsServerKey"`
-	HLSServerCert      string         `json:"hlsServerCert"`
-	HLSAlwaysRemux     bool           `json:"hlsAlwaysRemux"`
-	HLSVariant         HLSVariant     `json:"hlsVariant"`
-	HLSSegmentCount    int            `json:"hlsSegmentCount"`
-	HLSSegmentDuration StringDuration `json:"hlsSegmentDuration"`
-	HLSPartDuration    StringDuration `json:"hlsPartDuration"`
-	HLSSegmentMaxSize  StringSize     `json:"hlsSegmentMaxSize"`
-	HLSAllowOrigin     string         `json:"hlsAllowOrigin"`
-	HLSTrustedProxies  IPsOrCIDRs     `json:"hlsTrustedProxies"`
-	HLSDirectory       string         `json:"hlsDirectory"`
+	HLS                   bool               `json:"hls"`
+	HLSDisable            *bool              `json:"hlsDisable,omitempty"` // deprecated
+	HLSAddress            string             `json:"hlsAddress"`
+	HLSEncryption         bool               `json:"hlsEncryption"`
+	HLSServerKey          string             `json:"hlsServerKey"`
+	HLSServerCert         string             `json:"hlsServerCert"`
+	HLSAlwaysRemux        bool               `json:"hlsAlwaysRemux"`
+	HLSVariant            HLSVariant         `json:"hlsVariant"`
+	HLSSegmentCount       int                `json:"hlsSegmentCount"`
+	HLSSegmentDuration    StringDuration     `json:"hlsSegmentDuration"`
+	HLSPartDuration       StringDuration     `json:"hlsPartDuration"`
+	HLSSegmentMaxSize     StringSize         `json:"hlsSegmentMaxSize"`
+	HLSAllowOrigin        string             `json:"hlsAllowOrigin"`
+	HLSTrustedProxies     IPsOrCIDRs         `json:"hlsTrustedProxies"`
+	HLSDirectory          string             `json:"hlsDirectory"`
+	HLSWriteQueueOverflow WriteQueueOverflow `json:"hlsWriteQueueOverflow"`
 
 	// WebRTC server
-	WebRTC                      bool              `json:"webrtc"`
-	WebRTCDisable               *bool             `json:"webrtcDisable,omitempty"` // deprecated
-	WebRTCAddress               string            `json:"webrtcAddress"`
-	WebRTCEncryption            bool              `json:"webrtcEncryption"`
-	WebRTCServerKey             string            `json:"webrtcServerKey"`
-	WebRTCServerCert            string            `json:"webrtcServerCert"`
-	WebRTCAllowOrigin           string            `json:"webrtcAllowOrigin"`
-	WebRTCTrustedProxies        IPsOrCIDRs        `json:"webrtcTrustedProxies"`
-	WebRTCLocalUDPAddress       string            `json:"webrtcLocalUDPAddress"`
-	WebRTCLocalTCPAddress       string            `json:"webrtcLocalTCPAddress"`
-	WebRTCIPsFromInterfaces     bool              `json:"webrtcIPsFromInterfaces"`
-	WebRTCIPsFromInterfacesList []string          `json:"webrtcIPsFromInterfacesList"`
-	WebRTCAdditionalHosts       []string          `json:"webrtcAdditionalHosts"`
-	WebRTCICEServers2           []WebRTCICEServer `json:"webrtcICEServers2"`
-	WebRTCICEUDPMuxAddress      *string           `json:"webrtcICEUDPMuxAddress,omitempty"`  // deprecated
-	WebRTCICETCPMuxAddress      *string           `json:"webrtcICETCPMuxAddress,omitempty"`  // deprecated
-	WebRTCICEHostNAT1To1IPs     *[]string         `json:"webrtcICEHostNAT1To1IPs,omitempty"` // deprecated
-	WebRTCICEServers            *[]string         `json:"webrtcICEServers,omitempty"`        // deprecated
+	WebRTC                      bool               `json:"webrtc"`
+	WebRTCDisable               *bool              `json:"webrtcDisable,omitempty"` // deprecated
+	WebRTCAddress               string             `json:"webrtcAddress"`
+	WebRTCEncryption            bool               `json:"webrtcEncryption"`
+	WebRTCServerKey             string             `json:"webrtcServerKey"`
+	WebRTCServerCert            string             `json:"webrtcServerCert"`
+	WebRTCAllowOrigin           string             `json:"webrtcAllowOrigin"`
+	WebRTCTrustedProxies        IPsOrCIDRs         `json:"webrtcTrustedProxies"`
+	WebRTCLocalUDPAddress       string             `json:"webrtcLocalUDPAddress"`
+	WebRTCLocalTCPAddress       string             `json:"webrtcLocalTCPAddress"`
+	WebRTCIPsFromInterfaces     bool               `json:"webrtcIPsFromInterfaces"`
+	WebRTCIPsFromInterfacesList []string           `json:"webrtcIPsFromInterfacesList"`
+	WebRTCAdditionalHosts       []string           `json:"webrtcAdditionalHosts"`
+	WebRTCICEServers2           []WebRTCICEServer  `json:"webrtcICEServers2"`
+	WebRTCWriteQueueOverflow    WriteQueueOverflow `json:"webrtcWriteQueueOverflow"`
+	WebRTCICEUDPMuxAddress      *string            `json:"webrtcICEUDPMuxAddress,omitempty"`  // deprecated
+	WebRTCICETCPMuxAddress      *string            `json:"webrtcICETCPMuxAddress,omitempty"`  // deprecated
+	WebRTCICEHostNAT1To1IPs     *[]string          `json:"webrtcICEHostNAT1To1IPs,omitempty"` // deprecated
+	WebRTCICEServers            *[]string          `json:"webrtcICEServers,omitempty"`        // deprecated
 
 	// SRT server
-	SRT        bool   `json:"srt"`
-	SRTAddress string `json:"srtAddress"`
+	SRT                   bool               `json:"srt"`
+	SRTAddress            string             `json:"srtAddress"`
+	SRTWriteQueueOverflow WriteQueueOverflow `json:"srtWriteQueueOverflow"`
 
 	// Record (deprecated)
 	Record                *bool           `json:"record,omitempty"`                // deprecated
@@ -187,9 +212,13 @@ type Conf struct {
 	// Path defaults
 	PathDefaults Path `json:"pathDefaults"`
 
+	// Path templates
+	PathTemplates map[string]*OptionalPath `json:"pathTemplates"`
+
 	// Paths
-	OptionalPaths map[string]*OptionalPath `json:"paths"`
-	Paths         map[string]*Path         `json:"-"` // filled by Check()
+	PathsDirectory string                   `json:"pathsDirectory"`
+	OptionalPaths  map[string]*OptionalPath `json:"paths"`
+	Paths          map[string]*Path         `json:"-"` // filled by Check()
 }
 
 func (conf *Conf) setDefaults() {
@@ -203,6 +232,12 @@ func (conf *Conf) setDefaults() {
 	conf.UDPMaxPayloadSize = 1472
 	conf.MetricsAddress = "127.0.0.1:9998"
 	conf.PPROFAddress = "127.0.0.1:9999"
+	conf.HookOutputLevel = LogLevel(logger.Info)
+	conf.HookTerminationTimeout = 10 * StringDuration(time.Second)
+	conf.HookRestartMaxPause = 5 * StringDuration(time.Minute)
+	conf.WebhookTimeout = 10 * StringDuration(time.Second)
+	conf.WebhookMaxRetries = 3
+	conf.WebhookQueueSize = 256
 
 	// API
 	conf.APIAddress = "127.0.0.1:9997"
@@ -275,6 +310,19 @@ func Load(fpath string, defaultConfPaths []string) (*Conf, string, error) {
 		return nil, "", err
 	}
 
+	// environment variables are loaded after the paths directory, in order to
+	// apply them to paths defined in the directory too. The directory itself can be
+	// set with environment variables, therefore it is read from them in advance.
+	err = conf.loadPathsDirectoryFromEnv()
+	if err != nil {
+		return nil, "", err
+	}
+
+	err = conf.loadPathsDirectory(fpath)
+	if err != nil {
+		return nil, "", err
+	}
+
 	err = env.Load("RTSP", conf) // legacy prefix
 	if err != nil {
 		return nil, "", err
@@ -310,18 +358,9 @@ func (conf *Conf) loadFromFile(fpath string, defaultConfPaths []string) (string,
 		return "", err
 	}
 
-	if key, ok := os.LookupEnv("RTSP_CONFKEY"); ok { // legacy format
-		byts, err = decrypt.Decrypt(key, byts)
-		if err != nil {
-			return "", err
-		}
-	}
-
-	if key, ok := os.LookupEnv("MTX_CONFKEY"); ok {
-		byts, err = decrypt.Decrypt(key, byts)
-		if err != nil {
-			return "", err
-		}
+	byts, err = decryptFile(byts, true)
+	if err != nil {
+		return "", err
 	}
 
 	err = yaml.Load(byts, conf)
@@ -332,26 +371,159 @@ func (conf *Conf) loadFromFile(fpath string, defaultConfPaths []string) (string,
 	return fpath, nil
 }
 
+// decryptFile decrypts a configuration file that is either entirely encrypted
+// or that contains values that are encrypted individually.
+// When requireEncryption is true, the file must be encrypted if a key is set.
+func decryptFile(byts []byte, requireEncryption bool) ([]byte, error) {
+	key := ""
+
+	for _, name := range []string{
+		"RTSP_CONFKEY", // legacy prefix
+		"MTX_CONFKEY",
+	} {
+		if v, ok := os.LookupEnv(name); ok {
+			key = v
+
+			if decrypt.IsEncrypted(byts) {
+				var err error
+				byts, err = decrypt.Decrypt(v, byts)
+				if err != nil {
+					return nil, err
+				}
+			} else if requireEncryption && !bytes.Contains(byts, []byte(decrypt.ValuePrefix)) {
+				return nil, fmt.Errorf("%s is set, but the configuration is not encrypted "+
+					"and doesn't contain encrypted values", name)
+			}
+		}
+	}
+
+	return decrypt.Values(key, byts)
+}
+
+// PathsDirectoryPath returns the absolute path of the paths directory.
+// Relative paths are relative to the directory of the configuration file.
+func PathsDirectoryPath(confPath string, pathsDirectory string) string {
+	if pathsDirectory == "" {
+		return ""
+	}
+
+	if !filepath.IsAbs(pathsDirectory) {
+		pathsDirectory = filepath.Join(filepath.Dir(confPath), pathsDirectory)
+	}
+
+	ret, _ := filepath.Abs(pathsDirectory)
+	return ret
+}
+
+func isPathsDirectoryFile(fpath string) bool {
+	ext := filepath.Ext(fpath)
+	return ext == ".yml" || ext == ".yaml"
+}
+
+func (conf *Conf) loadPathsDirectoryFromEnv() error {
+	tmp := &Conf{PathsDirectory: conf.PathsDirectory}
+
+	err := env.Load("RTSP", tmp) // legacy prefix
+	if err != nil {
+		return err
+	}
+
+	err = env.Load("MTX", tmp)
+	if err != nil {
+		return err
+	}
+
+	conf.PathsDirectory = tmp.PathsDirectory
+	return nil
+}
+
+func (conf *Conf) loadPathsDirectory(confPath string) error {
+	dir := PathsDirectoryPath(confPath, conf.PathsDirectory)
+	if dir == "" {
+		return nil
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	sources := make(map[string]string)
+	for name := range conf.OptionalPaths {
+		sources[name] = confPath
+	}
+
+	// entries are sorted by file name
+	for _, entry := range entries {
+		if entry.IsDir() || !isPathsDirectoryFile(entry.Name()) {
+			continue
+		}
+
+		fpath := filepath.Join(dir, entry.Name())
+
+		byts, err := os.ReadFile(fpath)
+		if err != nil {
+			return err
+		}
+
+		byts, err = decryptFile(byts, false)
+		if err != nil {
+			return fmt.Errorf("unable to load '%s': %w", fpath, err)
+		}
+
+		var paths map[string]*OptionalPath
+		err = yaml.Load(byts, &paths)
+		if err != nil {
+			return fmt.Errorf("unable to load '%s': %w", fpath, err)
+		}
+
+		for _, name := range sortedKeys(paths) {
+			if source, ok := sources[name]; ok {
+				return fmt.Errorf("path '%s' is defined in both '%s' and '%s'", name, source, fpath)
+			}
+			sources[name] = fpath
+
+			if conf.OptionalPaths == nil {
+				conf.OptionalPaths = make(map[string]*OptionalPath)
+			}
+			conf.OptionalPaths[name] = paths[name]
+		}
+	}
+
+	return nil
+}
+
 // Clone clones the configuration.
-func (conf Conf) Clone() *Conf {
+// Values of secret files are copied instead of being read again.
+func (conf Conf) Clone() (*Conf, error) {
 	enc, err := json.Marshal(conf)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 
 	var dest Conf
 	err = json.Unmarshal(enc, &dest)
 	if err != nil {
-		panic(err)
+		return nil, err
+	}
+
+	err = loadSecretFiles(&dest, secretFileValues(&conf), false)
+	if err != nil {
+		return nil, err
 	}
 
-	return &dest
+	return &dest, nil
 }
 
 // Validate checks the configuration for errors.
 func (conf *Conf) Validate() error {
 	// General
 
+	err := loadSecretFiles(conf, make(map[string]string), true)
+	if err != nil {
+		return err
+	}
+
 	if conf.ReadBufferCount != nil {
 		conf.WriteQueueSize = *conf.ReadBufferCount
 	}
@@ -371,6 +543,30 @@ func (conf *Conf) Validate() error {
 			return fmt.Errorf("'externalAuthenticationURL' can't be used when 'digest' is in authMethods")
 		}
 	}
+	err = checkHook("runOnConnect", conf.RunOnConnect)
+	if err != nil {
+		return err
+	}
+	err = checkHook("runOnDisconnect", conf.RunOnDisconnect)
+	if err != nil {
+		return err
+	}
+	err = checkHook("runOnAuthFailure", conf.RunOnAuthFailure)
+	if err != nil {
+		return err
+	}
+	if conf.HookRestartMaxPause <= 0 {
+		return fmt.Errorf("'hookRestartMaxPause' must be greater than zero")
+	}
+	if conf.HookRestartMaxRetries < 0 {
+		return fmt.Errorf("'hookRestartMaxRetries' must be greater or equal than zero")
+	}
+	if conf.WebhookMaxRetries < 0 {
+		return fmt.Errorf("'webhookMaxRetries' must be greater or equal than zero")
+	}
+	if conf.WebhookQueueSize <= 0 {
+		return fmt.Errorf("'webhookQueueSize' must be greater than zero")
+	}
 
 	// RTSP
 
@@ -477,6 +673,25 @@ func (conf *Conf) Validate() error {
 		}
 	}
 
+	if conf.PathDefaults.Template != "" {
+		return fmt.Errorf("'template' can't be used in path defaults")
+	}
+
+	if len(conf.PathDefaults.Aliases) != 0 {
+		return fmt.Errorf("'aliases' can't be used in path defaults")
+	}
+
+	if len(conf.PathDefaults.Push) != 0 {
+		return fmt.Errorf("'push' can't be used in path defaults")
+	}
+
+	for _, name := range sortedKeys(conf.PathTemplates) {
+		template := conf.PathTemplates[name]
+		if template != nil && !reflect.ValueOf(template.Values).Elem().FieldByName("Template").IsNil() {
+			return fmt.Errorf("template '%s' can't inherit from another template", name)
+		}
+	}
+
 	conf.Paths = make(map[string]*Path)
 
 	for _, name := range sortedKeys(conf.OptionalPaths) {
@@ -487,15 +702,32 @@ func (conf *Conf) Validate() error {
 			}
 		}
 
-		pconf := newPath(&conf.PathDefaults, optional)
+		pconf, err := newPath(&conf.PathDefaults, conf.PathTemplates, optional)
+		if err != nil {
+			return fmt.Errorf("path '%s': %w", name, err)
+		}
 		conf.Paths[name] = pconf
 
-		err := pconf.validate(conf, name)
+		err = pconf.validate(conf, name)
 		if err != nil {
 			return err
 		}
 	}
 
+	aliases := make(map[string]string)
+
+	for _, name := range sortedKeys(conf.OptionalPaths) {
+		for _, alias := range conf.Paths[name].Aliases {
+			if _, ok := conf.Paths[alias.Name]; ok {
+				return fmt.Errorf("alias '%s' of path '%s' is already the name of a path", alias.Name, name)
+			}
+			if other, ok := aliases[alias.Name]; ok {
+				return fmt.Errorf("alias '%s' is used by both path '%s' and path '%s'", alias.Name, other, name)
+			}
+			aliases[alias.Name] = name
+		}
+	}
+
 	return nil
 }
 
@@ -5,6 +5,7 @@ import (
 	"encoding/base64"
 	"io"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -12,6 +13,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"golang.org/x/crypto/nacl/secretbox"
 
+	"github.com/bluenviron/mediamtx/internal/conf/encrypt"
 	"github.com/bluenviron/mediamtx/internal/logger"
 )
 
@@ -50,8 +52,14 @@ func TestConfFromFile(t *testing.T) {
 		require.Equal(t, &Path{
 			Name:                       "cam1",
 			Source:                     "publisher",
+			SourceBackups:              []string{},
+			SourceFailoverAfter:        3,
 			SourceOnDemandStartTimeout: 10 * StringDuration(time.Second),
 			SourceOnDemandCloseAfter:   10 * StringDuration(time.Second),
+			Aliases:                    []PathAlias{},
+			SilenceThreshold:           -60,
+			SilenceDuration:            10 * StringDuration(time.Second),
+			Push:                       []PushTarget{},
 			Playback:                   true,
 			RecordPath:                 "./recordings/%path/%Y-%m-%d_%H-%M-%S-%f",
 			RecordFormat:               RecordFormatFMP4,
@@ -112,6 +120,172 @@ func TestConfFromFile(t *testing.T) {
 	}()
 }
 
+func TestConfPathsDirectory(t *testing.T) {
+	dir, err := os.MkdirTemp("", "mediamtx-conf")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	err = os.Mkdir(filepath.Join(dir, "paths.d"), 0o755)
+	require.NoError(t, err)
+
+	err = os.WriteFile(filepath.Join(dir, "mediamtx.yml"), []byte("pathsDirectory: paths.d\n"+
+		"paths:\n"+
+		"  cam1:\n"+
+		"    source: rtsp://cam1\n"), 0o644)
+	require.NoError(t, err)
+
+	err = os.WriteFile(filepath.Join(dir, "paths.d", "a.yml"), []byte("cam2:\n"+
+		"  source: rtsp://cam2\n"+
+		"cam3:\n"), 0o644)
+	require.NoError(t, err)
+
+	err = os.WriteFile(filepath.Join(dir, "paths.d", "b.yaml"), []byte("cam4:\n"+
+		"  source: rtsp://cam4\n"), 0o644)
+	require.NoError(t, err)
+
+	err = os.WriteFile(filepath.Join(dir, "paths.d", "README"), []byte("not a configuration file"), 0o644)
+	require.NoError(t, err)
+
+	conf, _, err := Load(filepath.Join(dir, "mediamtx.yml"), nil)
+	require.NoError(t, err)
+
+	require.Equal(t, "rtsp://cam1", conf.Paths["cam1"].Source)
+	require.Equal(t, "rtsp://cam2", conf.Paths["cam2"].Source)
+	require.Equal(t, "publisher", conf.Paths["cam3"].Source)
+	require.Equal(t, "rtsp://cam4", conf.Paths["cam4"].Source)
+
+	err = os.WriteFile(filepath.Join(dir, "paths.d", "c.yml"), []byte("cam2:\n"), 0o644)
+	require.NoError(t, err)
+
+	_, _, err = Load(filepath.Join(dir, "mediamtx.yml"), nil)
+	require.EqualError(t, err, "path 'cam2' is defined in both '"+
+		filepath.Join(dir, "paths.d", "a.yml")+"' and '"+filepath.Join(dir, "paths.d", "c.yml")+"'")
+
+	err = os.WriteFile(filepath.Join(dir, "paths.d", "c.yml"), []byte("cam1:\n"), 0o644)
+	require.NoError(t, err)
+
+	_, _, err = Load(filepath.Join(dir, "mediamtx.yml"), nil)
+	require.EqualError(t, err, "path 'cam1' is defined in both '"+
+		filepath.Join(dir, "mediamtx.yml")+"' and '"+filepath.Join(dir, "paths.d", "c.yml")+"'")
+}
+
+func TestConfPathsDirectoryEnv(t *testing.T) {
+	dir, err := os.MkdirTemp("", "mediamtx-conf")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	err = os.Mkdir(filepath.Join(dir, "paths.d"), 0o755)
+	require.NoError(t, err)
+
+	err = os.WriteFile(filepath.Join(dir, "mediamtx.yml"), []byte("paths:\n"+
+		"  cam1:\n"+
+		"    source: rtsp://cam1\n"), 0o644)
+	require.NoError(t, err)
+
+	err = os.WriteFile(filepath.Join(dir, "paths.d", "a.yml"), []byte("cam2:\n"+
+		"  source: rtsp://cam2\n"), 0o644)
+	require.NoError(t, err)
+
+	t.Setenv("MTX_PATHSDIRECTORY", "paths.d")
+	t.Setenv("MTX_PATHS_CAM2_SOURCEONDEMAND", "yes")
+
+	conf, _, err := Load(filepath.Join(dir, "mediamtx.yml"), nil)
+	require.NoError(t, err)
+
+	require.Equal(t, "paths.d", conf.PathsDirectory)
+	require.Equal(t, "rtsp://cam1", conf.Paths["cam1"].Source)
+	require.Equal(t, "rtsp://cam2", conf.Paths["cam2"].Source)
+	require.Equal(t, true, conf.Paths["cam2"].SourceOnDemand)
+}
+
+func TestConfPathTemplates(t *testing.T) {
+	tmpf, err := writeTempFile([]byte("pathDefaults:\n" +
+		"  maxReaders: 5\n" +
+		"  record: yes\n" +
+		"pathTemplates:\n" +
+		"  camera:\n" +
+		"    record: no\n" +
+		"    readUser: myuser\n" +
+		"    readPass: mypass\n" +
+		"paths:\n" +
+		"  cam1:\n" +
+		"    template: camera\n" +
+		"    readUser: otheruser\n" +
+		"  cam2:\n"))
+	require.NoError(t, err)
+	defer os.Remove(tmpf)
+
+	conf, _, err := Load(tmpf, nil)
+	require.NoError(t, err)
+
+	pa := conf.Paths["cam1"]
+	require.Equal(t, "camera", pa.Template)
+	require.Equal(t, 5, pa.MaxReaders)
+	require.Equal(t, false, pa.Record)
+	require.Equal(t, Credential{value: "otheruser"}, pa.ReadUser)
+	require.Equal(t, Credential{value: "mypass"}, pa.ReadPass)
+
+	pa = conf.Paths["cam2"]
+	require.Equal(t, "", pa.Template)
+	require.Equal(t, true, pa.Record)
+	require.Equal(t, Credential{}, pa.ReadUser)
+}
+
+func TestConfSourceList(t *testing.T) {
+	tmpf, err := writeTempFile([]byte("paths:\n" +
+		"  cam1:\n" +
+		"    source:\n" +
+		"      - rtsp://primary:8554/cam\n" +
+		"      - rtsp://backup1:8554/cam\n" +
+		"      - rtsp://backup2:8554/cam\n" +
+		"    sourceFailoverAfter: 2\n" +
+		"  cam2:\n" +
+		"    source: rtsp://primary:8554/cam\n" +
+		"    sourceBackups: [rtmp://backup:1935/cam]\n"))
+	require.NoError(t, err)
+	defer os.Remove(tmpf)
+
+	conf, _, err := Load(tmpf, nil)
+	require.NoError(t, err)
+
+	pa := conf.Paths["cam1"]
+	require.Equal(t, "rtsp://primary:8554/cam", pa.Source)
+	require.Equal(t, []string{"rtsp://backup1:8554/cam", "rtsp://backup2:8554/cam"}, pa.SourceBackups)
+	require.Equal(t, 2, pa.SourceFailoverAfter)
+
+	pa = conf.Paths["cam2"]
+	require.Equal(t, "rtsp://primary:8554/cam", pa.Source)
+	require.Equal(t, []string{"rtmp://backup:1935/cam"}, pa.SourceBackups)
+	require.Equal(t, 3, pa.SourceFailoverAfter)
+}
+
+func TestConfPush(t *testing.T) {
+	tmpf, err := writeTempFile([]byte("paths:\n" +
+		"  cam1:\n" +
+		"    push:\n" +
+		"      - rtmp://a.rtmp.youtube.com/live2/mykey\n" +
+		"      - url: srt://backup:8890?streamid=publish:cam1\n" +
+		"        retryPause: 2s\n" +
+		"        maxRetries: 3\n"))
+	require.NoError(t, err)
+	defer os.Remove(tmpf)
+
+	conf, _, err := Load(tmpf, nil)
+	require.NoError(t, err)
+
+	require.Equal(t, []PushTarget{
+		{
+			URL:        "rtmp://a.rtmp.youtube.com/live2/mykey",
+			RetryPause: 5 * StringDuration(time.Second),
+		},
+		{
+			URL:        "srt://backup:8890?streamid=publish:cam1",
+			RetryPause: 2 * StringDuration(time.Second),
+			MaxRetries: 3,
+		},
+	}, conf.Paths["cam1"].Push)
+}
+
 func TestConfFromFileAndEnv(t *testing.T) {
 	// global parameter
 	t.Setenv("RTSP_PROTOCOLS", "tcp")
@@ -192,6 +366,53 @@ func TestConfEncryption(t *testing.T) {
 	require.Equal(t, true, ok)
 }
 
+func TestConfEncryptedValues(t *testing.T) {
+	key, err := encrypt.GenerateKey()
+	require.NoError(t, err)
+
+	encryptedPass, err := encrypt.EncryptValue(key, "testpass")
+	require.NoError(t, err)
+
+	tmpf, err := writeTempFile([]byte("paths:\n" +
+		"  path1:\n" +
+		"    readUser: testuser\n" +
+		"    readPass: " + encryptedPass + "\n"))
+	require.NoError(t, err)
+	defer os.Remove(tmpf)
+
+	_, _, err = Load(tmpf, nil)
+	require.EqualError(t, err, "the configuration contains encrypted values, but MTX_CONFKEY is not set")
+
+	t.Setenv("MTX_CONFKEY", key)
+
+	conf, _, err := Load(tmpf, nil)
+	require.NoError(t, err)
+	require.Equal(t, "testuser", conf.Paths["path1"].ReadUser.GetValue())
+	require.Equal(t, "testpass", conf.Paths["path1"].ReadPass.GetValue())
+
+	// entirely encrypted configurations are still supported when the key is set
+	encryptedConf, err := encrypt.Encrypt(key, []byte("paths:\n  path2:\n"))
+	require.NoError(t, err)
+
+	tmpf2, err := writeTempFile(encryptedConf)
+	require.NoError(t, err)
+	defer os.Remove(tmpf2)
+
+	conf, _, err = Load(tmpf2, nil)
+	require.NoError(t, err)
+	_, ok := conf.Paths["path2"]
+	require.Equal(t, true, ok)
+
+	// the key can't be used with plain configurations
+	tmpf3, err := writeTempFile([]byte("paths:\n  path3:\n"))
+	require.NoError(t, err)
+	defer os.Remove(tmpf3)
+
+	_, _, err = Load(tmpf3, nil)
+	require.EqualError(t, err, "MTX_CONFKEY is set, but the configuration is not encrypted "+
+		"and doesn't contain encrypted values")
+}
+
 func TestConfErrors(t *testing.T) {
 	for _, ca := range []struct {
 		name string
@@ -224,6 +445,207 @@ func TestConfErrors(t *testing.T) {
 				"authMethods: [digest]\n",
 			"'externalAuthenticationURL' can't be used when 'digest' is in authMethods",
 		},
+		{
+			"template not found",
+			"paths:\n" +
+				"  cam1:\n" +
+				"    template: camera\n",
+			"path 'cam1': template 'camera' not found",
+		},
+		{
+			"nested template",
+			"pathTemplates:\n" +
+				"  camera:\n" +
+				"    template: other\n",
+			"template 'camera' can't inherit from another template",
+		},
+		{
+			"template in path defaults",
+			"pathDefaults:\n" +
+				"  template: camera\n",
+			"'template' can't be used in path defaults",
+		},
+		{
+			"aliases in path defaults",
+			"pathDefaults:\n" +
+				"  aliases: [cam2]\n",
+			"'aliases' can't be used in path defaults",
+		},
+		{
+			"push in path defaults",
+			"pathDefaults:\n" +
+				"  push: [rtmp://localhost/cam]\n",
+			"'push' can't be used in path defaults",
+		},
+		{
+			"invalid push target",
+			"paths:\n" +
+				"  cam1:\n" +
+				"    push: [http://localhost/cam]\n",
+			"invalid push target: 'http://localhost/cam'",
+		},
+		{
+			"negative push retries",
+			"paths:\n" +
+				"  cam1:\n" +
+				"    push:\n" +
+				"      - url: rtsp://localhost:8554/cam\n" +
+				"        maxRetries: -1\n",
+			"push target 'rtsp://localhost:8554/cam': 'maxRetries' can't be negative",
+		},
+		{
+			"alias with the name of a path",
+			"paths:\n" +
+				"  cam1:\n" +
+				"    aliases: [cam2]\n" +
+				"  cam2:\n",
+			"alias 'cam2' of path 'cam1' is already the name of a path",
+		},
+		{
+			"duplicate alias",
+			"paths:\n" +
+				"  cam1:\n" +
+				"    aliases: [live/cam]\n" +
+				"  cam2:\n" +
+				"    aliases: [live/cam]\n",
+			"alias 'live/cam' is used by both path 'cam1' and path 'cam2'",
+		},
+		{
+			"alias of regular expression path",
+			"paths:\n" +
+				"  ~^cam$:\n" +
+				"    aliases: [live/cam]\n",
+			"'aliases' can't be used with regular expression paths",
+		},
+		{
+			"invalid alias name",
+			"paths:\n" +
+				"  cam1:\n" +
+				"    aliases: [/live/cam]\n",
+			"invalid alias name '/live/cam': can't begin with a slash",
+		},
+		{
+			"alias with partial credentials",
+			"paths:\n" +
+				"  cam1:\n" +
+				"    aliases:\n" +
+				"      - name: live/cam\n" +
+				"        readUser: myuser\n",
+			"alias 'live/cam': read username and password must be both filled",
+		},
+		{
+			"backup sources of publisher",
+			"paths:\n" +
+				"  cam1:\n" +
+				"    sourceBackups: [rtsp://backup:8554/cam]\n",
+			"'sourceBackups' can only be used when source is a static source",
+		},
+		{
+			"invalid backup source",
+			"paths:\n" +
+				"  cam1:\n" +
+				"    source: rtsp://primary:8554/cam\n" +
+				"    sourceBackups: [publisher]\n",
+			"invalid backup source: 'publisher'",
+		},
+		{
+			"source list and backup sources",
+			"paths:\n" +
+				"  cam1:\n" +
+				"    source: [rtsp://primary:8554/cam]\n" +
+				"    sourceBackups: [rtsp://backup:8554/cam]\n",
+			"'sourceBackups' can't be used when 'source' is a list",
+		},
+		{
+			"invalid sourceFailoverAfter",
+			"paths:\n" +
+				"  cam1:\n" +
+				"    source: rtsp://primary:8554/cam\n" +
+				"    sourceFailoverAfter: 0\n",
+			"'sourceFailoverAfter' must be greater than zero",
+		},
+		{
+			"publisherReconnectGrace with static source",
+			"paths:\n" +
+				"  cam1:\n" +
+				"    source: rtsp://primary:8554/cam\n" +
+				"    publisherReconnectGrace: 5s\n",
+			"'publisherReconnectGrace' can only be used when source is 'publisher'",
+		},
+		{
+			"offlineSource with static source",
+			"paths:\n" +
+				"  cam1:\n" +
+				"    source: rtsp://primary:8554/cam\n" +
+				"    offlineSource: offline.ts\n",
+			"'offlineSource' can only be used when source is 'publisher'",
+		},
+		{
+			"offlineSource with runOnDemand",
+			"paths:\n" +
+				"  cam1:\n" +
+				"    runOnDemand: ffmpeg\n" +
+				"    offlineSource: offline.ts\n",
+			"'offlineSource' can't be used together with 'runOnDemand'",
+		},
+		{
+			"invalid compose media type",
+			"paths:\n" +
+				"  cam1:\n" +
+				"    source: compose:cam2#subtitles\n",
+			"invalid media type 'subtitles' in compose source",
+		},
+		{
+			"compose source of own path",
+			"paths:\n" +
+				"  cam1:\n" +
+				"    source: compose:cam1#video,cam2#audio\n",
+			"a compose source can't read from its own path",
+		},
+		{
+			"invalid trackFilter",
+			"paths:\n" +
+				"  cam1:\n" +
+				"    trackFilter: video,subtitles\n",
+			"invalid 'trackFilter': invalid track 'subtitles' (allowed are video, audio, application or a media index)",
+		},
+		{
+			"invalid stats bitrate thresholds",
+			"paths:\n" +
+				"  cam1:\n" +
+				"    statsMinBitrate: 2000000\n" +
+				"    statsMaxBitrate: 1000000\n",
+			"'statsMinBitrate' must be lower than 'statsMaxBitrate'",
+		},
+		{
+			"invalid silenceThreshold",
+			"paths:\n" +
+				"  cam1:\n" +
+				"    silenceThreshold: 10\n",
+			"'silenceThreshold' must be lower or equal than zero",
+		},
+		{
+			"invalid rtmpWriteQueueOverflow",
+			"rtmpWriteQueueOverflow: drop\n",
+			"invalid write queue overflow policy 'drop'",
+		},
+		{
+			"invalid webhookQueueSize",
+			"webhookQueueSize: 0\n",
+			"'webhookQueueSize' must be greater than zero",
+		},
+		{
+			"invalid hookRestartMaxRetries",
+			"hookRestartMaxRetries: -1\n",
+			"'hookRestartMaxRetries' must be greater or equal than zero",
+		},
+		{
+			"invalid hook URL",
+			"paths:\n" +
+				"  mypath:\n" +
+				"    runOnReady: http://[::1\n",
+			"'runOnReady' is not a valid URL: parse \"http://[::1\": missing ']' in host",
+		},
 		{
 			"invalid strict encryption 1",
 			"encryption: strict\n" +
@@ -19,12 +19,18 @@ var (
 const plainCredentialSupportedChars = "A-Z,0-9,!,$,(,),*,+,.,;,<,=,>,[,],^,_,-,\",\",@,#,&"
 
 // Credential is a parameter that is used as username or password.
+// The credential can be read from a file by using the "file:" prefix.
 type Credential struct {
-	value string
+	value  string
+	file   string
+	loaded bool
 }
 
 // MarshalJSON implements json.Marshaler.
 func (d Credential) MarshalJSON() ([]byte, error) {
+	if d.file != "" {
+		return json.Marshal(secretFilePrefix + d.file)
+	}
 	return json.Marshal(d.value)
 }
 
@@ -35,6 +41,14 @@ func (d *Credential) UnmarshalJSON(b []byte) error {
 		return err
 	}
 
+	// files are read and validated when the configuration is validated
+	if strings.HasPrefix(in, secretFilePrefix) {
+		*d = Credential{
+			file: in[len(secretFilePrefix):],
+		}
+		return nil
+	}
+
 	*d = Credential{
 		value: in,
 	}
@@ -44,7 +58,18 @@ func (d *Credential) UnmarshalJSON(b []byte) error {
 
 // UnmarshalEnv implements env.Unmarshaler.
 func (d *Credential) UnmarshalEnv(_ string, v string) error {
-	return d.UnmarshalJSON([]byte(`"` + v + `"`))
+	byts, _ := json.Marshal(v)
+	return d.UnmarshalJSON(byts)
+}
+
+func (d *Credential) fileState() (string, string, bool) {
+	return d.file, d.value, d.loaded
+}
+
+func (d *Credential) setFileValue(value string) error {
+	d.value = value
+	d.loaded = true
+	return d.validate()
 }
 
 // GetValue returns the value of the credential.
@@ -1,6 +1,8 @@
 package conf
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -24,6 +26,33 @@ func TestCredential(t *testing.T) {
 		assert.Equal(t, expectedCred, actualCred)
 	})
 
+	t.Run("UnmarshalJSON from file", func(t *testing.T) {
+		fpath := filepath.Join(t.TempDir(), "password")
+		err := os.WriteFile(fpath, []byte("password\n"), 0o644)
+		assert.NoError(t, err)
+
+		var cred Credential
+		err = cred.UnmarshalJSON([]byte(`"file:` + fpath + `"`))
+		assert.NoError(t, err)
+		assert.Equal(t, "", cred.GetValue())
+
+		// the file is read when the configuration is validated
+		err = loadSecretFiles(&cred, make(map[string]string), true)
+		assert.NoError(t, err)
+		assert.Equal(t, "password", cred.GetValue())
+
+		// the file reference is preserved
+		actualJSON, err := cred.MarshalJSON()
+		assert.NoError(t, err)
+		assert.Equal(t, []byte(`"file:`+fpath+`"`), actualJSON)
+
+		err = cred.UnmarshalJSON([]byte(`"file:/nonexisting"`))
+		assert.NoError(t, err)
+
+		err = loadSecretFiles(&cred, make(map[string]string), true)
+		assert.Error(t, err)
+	})
+
 	t.Run("UnmarshalEnv", func(t *testing.T) {
 		cred := Credential{}
 		err := cred.UnmarshalEnv("", "password")
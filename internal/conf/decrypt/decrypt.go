@@ -2,19 +2,40 @@
 package decrypt
 
 import (
+	"bytes"
 	"encoding/base64"
 	"fmt"
+	"strings"
 
 	"golang.org/x/crypto/nacl/secretbox"
+	"gopkg.in/yaml.v3"
 )
 
+// ValuePrefix is the prefix of values that are encrypted individually.
+const ValuePrefix = "enc:"
+
+// IsEncrypted checks whether the configuration is entirely encrypted.
+func IsEncrypted(byts []byte) bool {
+	byts = bytes.TrimSpace(byts)
+	if len(byts) == 0 {
+		return false
+	}
+
+	_, err := base64.StdEncoding.DecodeString(string(byts))
+	return err == nil
+}
+
 // Decrypt decrypts the configuration with the given key.
 func Decrypt(key string, byts []byte) ([]byte, error) {
-	enc, err := base64.StdEncoding.DecodeString(string(byts))
+	enc, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(byts)))
 	if err != nil {
 		return nil, err
 	}
 
+	if len(enc) < 24 {
+		return nil, fmt.Errorf("decryption error")
+	}
+
 	var secretKey [32]byte
 	copy(secretKey[:], key)
 
@@ -27,3 +48,91 @@ func Decrypt(key string, byts []byte) ([]byte, error) {
 
 	return decrypted, nil
 }
+
+// DecryptValue decrypts a value that is encrypted individually.
+func DecryptValue(key string, v string) (string, error) {
+	dec, err := Decrypt(key, []byte(strings.TrimPrefix(v, ValuePrefix)))
+	if err != nil {
+		return "", err
+	}
+	return string(dec), nil
+}
+
+func decryptValues(key string, node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for _, child := range node.Content {
+			err := decryptValues(key, child)
+			if err != nil {
+				return err
+			}
+		}
+
+	case yaml.MappingNode:
+		// content contains keys and values in alternate order. Keys are not decrypted.
+		for i := 1; i < len(node.Content); i += 2 {
+			err := decryptValues(key, node.Content[i])
+			if err != nil {
+				return err
+			}
+		}
+
+	case yaml.ScalarNode:
+		if node.ShortTag() != "!!str" || !strings.HasPrefix(node.Value, ValuePrefix) {
+			return nil
+		}
+
+		if key == "" {
+			return fmt.Errorf("the configuration contains encrypted values, but MTX_CONFKEY is not set")
+		}
+
+		dec, err := DecryptValue(key, node.Value)
+		if err != nil {
+			return err
+		}
+
+		// plain values are quoted, otherwise values like "yes" would not be loaded as strings.
+		node.Value = dec
+		node.Tag = "!!str"
+		if node.Style == 0 {
+			node.Style = yaml.DoubleQuotedStyle
+		}
+	}
+
+	return nil
+}
+
+// Values decrypts values of a YAML configuration that are encrypted individually.
+// Values are replaced in place, in order to preserve comments and order of keys.
+func Values(key string, byts []byte) ([]byte, error) {
+	if !bytes.Contains(byts, []byte(ValuePrefix)) {
+		return byts, nil
+	}
+
+	var node yaml.Node
+	err := yaml.Unmarshal(byts, &node)
+	if err != nil {
+		return nil, err
+	}
+
+	err = decryptValues(key, &node)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+
+	err = enc.Encode(&node)
+	if err != nil {
+		return nil, err
+	}
+
+	err = enc.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,63 @@
+package decrypt
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testKey = "vB3MFhtEhy3pIzVYLGY6xqYebOxI0t6l"
+
+func TestIsEncrypted(t *testing.T) {
+	require.True(t, IsEncrypted([]byte("/ihNPtCCNhe6CrJTt67TUL1RfS3mm4D+OX14z85gK0iXKxt7soYAu1gJdfZTKg==\n")))
+	require.False(t, IsEncrypted([]byte("logLevel: info\n")))
+	require.False(t, IsEncrypted([]byte("\n")))
+}
+
+func TestDecryptValue(t *testing.T) {
+	v, err := DecryptValue(testKey, "enc:/ihNPtCCNhe6CrJTt67TUL1RfS3mm4D+OX14z85gK0iXKxt7soYAu1gJdfZTKg==")
+	require.NoError(t, err)
+	require.Equal(t, "mypass", v)
+
+	_, err = DecryptValue("wrongkey", "enc:/ihNPtCCNhe6CrJTt67TUL1RfS3mm4D+OX14z85gK0iXKxt7soYAu1gJdfZTKg==")
+	require.EqualError(t, err, "decryption error")
+}
+
+func TestValues(t *testing.T) {
+	in := "# global settings\n" +
+		"logLevel: info\n" +
+		"\n" +
+		"paths:\n" +
+		"  # camera\n" +
+		"  cam:\n" +
+		"    source: rtsp://localhost:8554/cam # upstream\n" +
+		"    readUser: enc:/ihNPtCCNhe6CrJTt67TUL1RfS3mm4D+OX14z85gK0iXKxt7soYAu1gJdfZTKg==\n" +
+		"    readPass: \"enc:GOxn58tHBTVq9qY/hhlCPW/bz/AWUU/iO42DUA+Ar79TMsjaIn5FsA9YrNFsqBVHAokn\"\n" +
+		"    publishUser: enc:vnU5XAW6Vn+8AWj3O13A6BPBxN86AdE0XQJ3M+cxy/1W484o8712M4XZuQ==\n" +
+		"  all_others:\n"
+
+	out, err := Values(testKey, []byte(in))
+	require.NoError(t, err)
+	require.Equal(t, "# global settings\n"+
+		"logLevel: info\n"+
+		"paths:\n"+
+		"  # camera\n"+
+		"  cam:\n"+
+		"    source: rtsp://localhost:8554/cam # upstream\n"+
+		"    readUser: \"mypass\"\n"+
+		"    readPass: \"my: pass #1\"\n"+
+		"    publishUser: \"yes\"\n"+
+		"  all_others:\n", string(out))
+
+	_, err = Values("", []byte(in))
+	require.EqualError(t, err, "the configuration contains encrypted values, but MTX_CONFKEY is not set")
+
+	_, err = Values("wrongkey", []byte(in))
+	require.EqualError(t, err, "decryption error")
+
+	// configurations without encrypted values are returned as they are
+	in = "# comment\nlogLevel: info\n\n"
+	out, err = Values("", []byte(in))
+	require.NoError(t, err)
+	require.Equal(t, in, string(out))
+}
@@ -0,0 +1,61 @@
+// Package encrypt contains the Encrypt function.
+package encrypt
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+	"math/big"
+
+	"golang.org/x/crypto/nacl/secretbox"
+
+	"github.com/bluenviron/mediamtx/internal/conf/decrypt"
+)
+
+const (
+	keyLength   = 32
+	keyAlphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+)
+
+// GenerateKey generates a random key that can be used to encrypt the configuration.
+func GenerateKey() (string, error) {
+	ret := make([]byte, keyLength)
+	alphabetLen := big.NewInt(int64(len(keyAlphabet)))
+
+	for i := range ret {
+		n, err := rand.Int(rand.Reader, alphabetLen)
+		if err != nil {
+			return "", err
+		}
+		ret[i] = keyAlphabet[n.Int64()]
+	}
+
+	return string(ret), nil
+}
+
+// Encrypt encrypts the configuration with the given key.
+func Encrypt(key string, byts []byte) ([]byte, error) {
+	var secretKey [32]byte
+	copy(secretKey[:], key)
+
+	var nonce [24]byte
+	_, err := io.ReadFull(rand.Reader, nonce[:])
+	if err != nil {
+		return nil, err
+	}
+
+	encrypted := secretbox.Seal(nonce[:], byts, &nonce, &secretKey)
+
+	ret := make([]byte, base64.StdEncoding.EncodedLen(len(encrypted)))
+	base64.StdEncoding.Encode(ret, encrypted)
+	return ret, nil
+}
+
+// EncryptValue encrypts a single value, that can then be inserted into an otherwise plain configuration.
+func EncryptValue(key string, v string) (string, error) {
+	enc, err := Encrypt(key, []byte(v))
+	if err != nil {
+		return "", err
+	}
+	return decrypt.ValuePrefix + string(enc), nil
+}
@@ -0,0 +1,48 @@
+package encrypt
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bluenviron/mediamtx/internal/conf/decrypt"
+)
+
+func TestGenerateKey(t *testing.T) {
+	key, err := GenerateKey()
+	require.NoError(t, err)
+	require.Len(t, key, keyLength)
+
+	for _, c := range key {
+		require.True(t, strings.ContainsRune(keyAlphabet, c))
+	}
+}
+
+func TestEncrypt(t *testing.T) {
+	key, err := GenerateKey()
+	require.NoError(t, err)
+
+	plain := []byte("# comment\nlogLevel: info\n")
+
+	enc, err := Encrypt(key, plain)
+	require.NoError(t, err)
+	require.True(t, decrypt.IsEncrypted(enc))
+
+	dec, err := decrypt.Decrypt(key, enc)
+	require.NoError(t, err)
+	require.Equal(t, plain, dec)
+}
+
+func TestEncryptValue(t *testing.T) {
+	key, err := GenerateKey()
+	require.NoError(t, err)
+
+	enc, err := EncryptValue(key, "mypass")
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(enc, decrypt.ValuePrefix))
+
+	dec, err := decrypt.DecryptValue(key, enc)
+	require.NoError(t, err)
+	require.Equal(t, "mypass", dec)
+}
@@ -0,0 +1,315 @@
+// Package main contains a generator of the JSON Schema of the configuration.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/bluenviron/mediamtx/internal/conf"
+)
+
+const (
+	durationPattern = `^[-+]?(0|([0-9]*(\.[0-9]*)?(ns|us|µs|μs|ms|s|m|h))+)$`
+	sizePattern     = `^[0-9]+(\.[0-9]*)?([KkMmGgTtPpEe]([Ii]?[Bb])?|[Bb])$`
+)
+
+func enumSchema(values ...string) map[string]interface{} {
+	return map[string]interface{}{
+		"type": "string",
+		"enum": values,
+	}
+}
+
+func enumArraySchema(values ...string) map[string]interface{} {
+	return map[string]interface{}{
+		"type":        "array",
+		"items":       enumSchema(values...),
+		"uniqueItems": true,
+	}
+}
+
+// schemas of types that are unmarshaled from a format that differs from their Go type.
+var customSchemas = map[reflect.Type]map[string]interface{}{
+	reflect.TypeOf(conf.StringDuration(0)): {
+		"type":    "string",
+		"pattern": durationPattern,
+	},
+	reflect.TypeOf(conf.StringSize(0)): {
+		"type":    "string",
+		"pattern": sizePattern,
+	},
+	reflect.TypeOf(conf.Credential{}): {
+		"type": "string",
+	},
+	reflect.TypeOf(conf.Secret{}): {
+		"type": "string",
+	},
+	reflect.TypeOf(conf.IPsOrCIDRs{}): {
+		"type":  "array",
+		"items": map[string]interface{}{"type": "string"},
+	},
+	reflect.TypeOf(conf.LogLevel(0)):           enumSchema("error", "warn", "info", "debug"),
+	reflect.TypeOf(conf.LogDestinations{}):     enumArraySchema("stdout", "file", "syslog"),
+	reflect.TypeOf(conf.Protocols{}):           enumArraySchema("udp", "multicast", "tcp"),
+	reflect.TypeOf(conf.AuthMethods{}):         enumArraySchema("basic", "digest"),
+	reflect.TypeOf(conf.Encryption(0)):         enumSchema("no", "false", "optional", "strict", "yes", "true"),
+	reflect.TypeOf(conf.HLSVariant(0)):         enumSchema("mpegts", "fmp4", "lowLatency"),
+	reflect.TypeOf(conf.RecordFormat(0)):       enumSchema("fmp4", "mpegts"),
+	reflect.TypeOf(conf.RTSPTransport{}):       enumSchema("udp", "multicast", "tcp", "automatic"),
+	reflect.TypeOf(conf.RTSPRangeType(0)):      enumSchema("clock", "npt", "smpte", ""),
+	reflect.TypeOf(conf.WriteQueueOverflow(0)): enumSchema("dropUntilKeyFrame", "disconnect"),
+	reflect.TypeOf(conf.Path{}):                {"$ref": "#/$defs/path"},
+	reflect.TypeOf(conf.OptionalPath{}):        {"$ref": "#/$defs/path"},
+}
+
+func jsonName(f reflect.StructField) string {
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return ""
+	}
+	return strings.Split(tag, ",")[0]
+}
+
+// deprecatedFields returns, for each struct type of the package,
+// the fields that are marked as deprecated with a comment.
+func deprecatedFields(dir string) (map[string]map[string]struct{}, error) {
+	pkgs, err := parser.ParseDir(token.NewFileSet(), dir, func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make(map[string]map[string]struct{})
+
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Files {
+			ast.Inspect(file, func(n ast.Node) bool {
+				ts, ok := n.(*ast.TypeSpec)
+				if !ok {
+					return true
+				}
+
+				st, ok := ts.Type.(*ast.StructType)
+				if !ok {
+					return true
+				}
+
+				for _, field := range st.Fields.List {
+					if field.Comment == nil || !strings.HasPrefix(strings.TrimSpace(field.Comment.Text()), "deprecated") {
+						continue
+					}
+
+					for _, name := range field.Names {
+						if ret[ts.Name.Name] == nil {
+							ret[ts.Name.Name] = make(map[string]struct{})
+						}
+						ret[ts.Name.Name][name.Name] = struct{}{}
+					}
+				}
+
+				return true
+			})
+		}
+	}
+
+	return ret, nil
+}
+
+type generator struct {
+	deprecated map[string]map[string]struct{}
+}
+
+// types that can be unmarshaled from a string too.
+var stringOrStructTypes = map[reflect.Type]struct{}{
+	reflect.TypeOf(conf.PathAlias{}):  {},
+	reflect.TypeOf(conf.PushTarget{}): {},
+}
+
+// fields that accept a list in addition to their own type.
+var listFields = map[string]map[string]struct{}{
+	"Path": {"Source": {}},
+}
+
+func (g *generator) typeSchema(t reflect.Type) map[string]interface{} {
+	if s, ok := customSchemas[t]; ok {
+		return s
+	}
+
+	if _, ok := stringOrStructTypes[t]; ok {
+		return map[string]interface{}{
+			"anyOf": []interface{}{
+				map[string]interface{}{"type": "string"},
+				g.structSchema(t, nil),
+			},
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return g.typeSchema(t.Elem())
+
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+
+	case reflect.Slice:
+		return map[string]interface{}{
+			"type":  "array",
+			"items": g.typeSchema(t.Elem()),
+		}
+
+	case reflect.Map:
+		items := g.typeSchema(t.Elem())
+
+		// entries of optional maps can be empty
+		if t.Elem().Kind() == reflect.Ptr {
+			items = map[string]interface{}{
+				"anyOf": []interface{}{items, map[string]interface{}{"type": "null"}},
+			}
+		}
+
+		return map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": items,
+		}
+
+	case reflect.Struct:
+		return g.structSchema(t, nil)
+	}
+
+	panic(fmt.Errorf("unsupported type: %v", t))
+}
+
+func (g *generator) structSchema(t reflect.Type, defaults map[string]interface{}) map[string]interface{} {
+	properties := make(map[string]interface{})
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		name := jsonName(f)
+		if name == "" {
+			continue
+		}
+
+		s := make(map[string]interface{})
+		for k, v := range g.typeSchema(f.Type) {
+			s[k] = v
+		}
+
+		if _, ok := listFields[t.Name()][f.Name]; ok {
+			s = map[string]interface{}{
+				"anyOf": []interface{}{
+					s,
+					map[string]interface{}{"type": "array", "items": g.typeSchema(f.Type)},
+				},
+			}
+		}
+
+		if v, ok := defaults[name]; ok && v != nil {
+			if _, ok := s["$ref"]; !ok {
+				s["default"] = v
+			}
+		}
+
+		if _, ok := g.deprecated[t.Name()][f.Name]; ok {
+			s["deprecated"] = true
+		}
+
+		properties[name] = s
+	}
+
+	return map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+}
+
+func toMap(v interface{}) (map[string]interface{}, error) {
+	byts, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret map[string]interface{}
+	err = json.Unmarshal(byts, &ret)
+	return ret, err
+}
+
+func generate(dir string) ([]byte, error) {
+	deprecated, err := deprecatedFields(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &generator{deprecated: deprecated}
+
+	// load default values
+	var defaultConf conf.Conf
+	err = json.Unmarshal([]byte("{}"), &defaultConf)
+	if err != nil {
+		return nil, err
+	}
+
+	globalDefaults, err := toMap(defaultConf)
+	if err != nil {
+		return nil, err
+	}
+
+	pathDefaults, err := toMap(defaultConf.PathDefaults)
+	if err != nil {
+		return nil, err
+	}
+
+	schema := g.structSchema(reflect.TypeOf(conf.Conf{}), globalDefaults)
+	schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	schema["title"] = "MediaMTX configuration"
+	schema["$defs"] = map[string]interface{}{
+		"path": g.structSchema(reflect.TypeOf(conf.Path{}), pathDefaults),
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	err = enc.Encode(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func run() error {
+	byts, err := generate(".")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile("schema.json", byts, 0o644)
+}
+
+func main() {
+	err := run()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERR: %s\n", err)
+		os.Exit(1)
+	}
+}
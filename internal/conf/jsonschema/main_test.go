@@ -0,0 +1,60 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bluenviron/mediamtx/internal/conf"
+)
+
+func TestSchemaUpToDate(t *testing.T) {
+	byts, err := generate("..")
+	require.NoError(t, err)
+	require.Equal(t, string(byts), string(conf.Schema()),
+		"schema.json is out of date, run 'go generate ./internal/conf'")
+}
+
+func TestSchemaFields(t *testing.T) {
+	var schema struct {
+		Properties map[string]map[string]interface{} `json:"properties"`
+		Defs       struct {
+			Path struct {
+				Properties map[string]map[string]interface{} `json:"properties"`
+			} `json:"path"`
+		} `json:"$defs"`
+	}
+	err := json.Unmarshal(conf.Schema(), &schema)
+	require.NoError(t, err)
+
+	deprecated, err := deprecatedFields("..")
+	require.NoError(t, err)
+
+	for _, ca := range []struct {
+		typ        reflect.Type
+		properties map[string]map[string]interface{}
+	}{
+		{reflect.TypeOf(conf.Conf{}), schema.Properties},
+		{reflect.TypeOf(conf.Path{}), schema.Defs.Path.Properties},
+	} {
+		t.Run(ca.typ.Name(), func(t *testing.T) {
+			for i := 0; i < ca.typ.NumField(); i++ {
+				f := ca.typ.Field(i)
+
+				name := jsonName(f)
+				if name == "" {
+					continue
+				}
+
+				prop, ok := ca.properties[name]
+				require.True(t, ok, "field '%s' has no schema entry", name)
+
+				_, isDeprecated := deprecated[ca.typ.Name()][f.Name]
+				require.Equal(t, isDeprecated, prop["deprecated"] == true,
+					"field '%s' is not marked correctly as deprecated", name)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package conf
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"reflect"
 	"strings"
 
@@ -48,8 +49,42 @@ type OptionalPath struct {
 	Values interface{}
 }
 
+// expandSourceList converts a list of sources into a primary source and backup sources.
+func expandSourceList(b []byte) ([]byte, error) {
+	var fields map[string]json.RawMessage
+	err := json.Unmarshal(b, &fields)
+	if err != nil || !bytes.HasPrefix(bytes.TrimSpace(fields["source"]), []byte("[")) {
+		return b, nil //nolint:nilerr
+	}
+
+	var sources []string
+	err = json.Unmarshal(fields["source"], &sources)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("'source' can't be an empty list")
+	}
+
+	if _, ok := fields["sourceBackups"]; ok {
+		return nil, fmt.Errorf("'sourceBackups' can't be used when 'source' is a list")
+	}
+
+	fields["source"], _ = json.Marshal(sources[0])
+	fields["sourceBackups"], _ = json.Marshal(sources[1:])
+
+	return json.Marshal(fields)
+}
+
 // UnmarshalJSON implements json.Unmarshaler.
+// Source can be a list, whose first entry is the primary source and the others are backup sources.
 func (p *OptionalPath) UnmarshalJSON(b []byte) error {
+	b, err := expandSourceList(b)
+	if err != nil {
+		return err
+	}
+
 	p.Values = newOptionalPathValues()
 	d := json.NewDecoder(bytes.NewReader(b))
 	d.DisallowUnknownFields()
@@ -58,6 +58,13 @@ func FindPathConf(pathConfs map[string]*Path, name string) (string, *Path, []str
 		return name, pathConf, nil, nil
 	}
 
+	// alias
+	for pathConfName, pathConf := range pathConfs {
+		if pathConf.FindAlias(name) != nil {
+			return pathConfName, pathConf, nil, nil
+		}
+	}
+
 	// regular expression-based path
 	for pathConfName, pathConf := range pathConfs {
 		if pathConf.Regexp != nil && pathConfName != "all" && pathConfName != "all_others" {
@@ -87,14 +94,33 @@ type Path struct {
 	Name   string         `json:"name"` // filled by Check()
 
 	// General
+	Template                   string         `json:"template"`
 	Source                     string         `json:"source"`
+	SourceBackups              []string       `json:"sourceBackups"`
+	SourceFailoverAfter        int            `json:"sourceFailoverAfter"`
+	SourceFailback             bool           `json:"sourceFailback"`
 	SourceFingerprint          string         `json:"sourceFingerprint"`
 	SourceOnDemand             bool           `json:"sourceOnDemand"`
 	SourceOnDemandStartTimeout StringDuration `json:"sourceOnDemandStartTimeout"`
 	SourceOnDemandCloseAfter   StringDuration `json:"sourceOnDemandCloseAfter"`
 	MaxReaders                 int            `json:"maxReaders"`
-	SRTReadPassphrase          string         `json:"srtReadPassphrase"`
+	SRTReadPassphrase          Secret         `json:"srtReadPassphrase"`
 	Fallback                   string         `json:"fallback"`
+	Aliases                    []PathAlias    `json:"aliases"`
+	TrackFilter                string         `json:"trackFilter"`
+	GOPCache                   bool           `json:"gopCache"`
+	TimeshiftBuffer            StringDuration `json:"timeshiftBuffer"`
+	NoDataTimeout              StringDuration `json:"noDataTimeout"`
+	NoDataTimeoutClose         bool           `json:"noDataTimeoutClose"`
+	StatsMinBitrate            uint64         `json:"statsMinBitrate"`
+	StatsMaxBitrate            uint64         `json:"statsMaxBitrate"`
+	StatsMinFPS                float64        `json:"statsMinFPS"`
+	StatsMaxGOPDuration        StringDuration `json:"statsMaxGOPDuration"`
+	SilenceThreshold           float64        `json:"silenceThreshold"`
+	SilenceDuration            StringDuration `json:"silenceDuration"`
+
+	// Push
+	Push []PushTarget `json:"push"`
 
 	// Record and playback
 	Record                bool           `json:"record"`
@@ -114,9 +140,11 @@ type Path struct {
 	ReadIPs     IPsOrCIDRs `json:"readIPs"`
 
 	// Publisher source
-	OverridePublisher        bool   `json:"overridePublisher"`
-	DisablePublisherOverride *bool  `json:"disablePublisherOverride,omitempty"` // deprecated
-	SRTPublishPassphrase     string `json:"srtPublishPassphrase"`
+	OverridePublisher        bool           `json:"overridePublisher"`
+	DisablePublisherOverride *bool          `json:"disablePublisherOverride,omitempty"` // deprecated
+	SRTPublishPassphrase     Secret         `json:"srtPublishPassphrase"`
+	PublisherReconnectGrace  StringDuration `json:"publisherReconnectGrace"`
+	OfflineSource            string         `json:"offlineSource"`
 
 	// RTSP source
 	RTSPTransport       RTSPTransport  `json:"rtspTransport"`
@@ -179,13 +207,48 @@ type Path struct {
 	RunOnUnread                string         `json:"runOnUnread"`
 	RunOnRecordSegmentCreate   string         `json:"runOnRecordSegmentCreate"`
 	RunOnRecordSegmentComplete string         `json:"runOnRecordSegmentComplete"`
+	RunOnRecordSegmentDelete   string         `json:"runOnRecordSegmentDelete"`
+	RunOnPublisherChange       string         `json:"runOnPublisherChange"`
+	RunOnNoData                string         `json:"runOnNoData"`
+	RunOnStatsThreshold        string         `json:"runOnStatsThreshold"`
+	RunOnSilence               string         `json:"runOnSilence"`
+	RunOnSound                 string         `json:"runOnSound"`
+	RunOnPathCreate            string         `json:"runOnPathCreate"`
+	RunOnPathDestroy           string         `json:"runOnPathDestroy"`
+}
+
+// FindAlias returns the alias with the given name, or nil if it doesn't exist.
+func (pconf *Path) FindAlias(name string) *PathAlias {
+	for i, alias := range pconf.Aliases {
+		if alias.Name == name {
+			return &pconf.Aliases[i]
+		}
+	}
+	return nil
+}
+
+// CanonicalName returns the name of the path that is reachable with the given name.
+// It differs from the given name when the latter is an alias.
+func (pconf *Path) CanonicalName(name string) string {
+	if pconf.FindAlias(name) != nil {
+		return pconf.Name
+	}
+	return name
 }
 
 func (pconf *Path) setDefaults() {
 	// General
 	pconf.Source = "publisher"
+	pconf.SourceBackups = []string{}
+	pconf.SourceFailoverAfter = 3
 	pconf.SourceOnDemandStartTimeout = 10 * StringDuration(time.Second)
 	pconf.SourceOnDemandCloseAfter = 10 * StringDuration(time.Second)
+	pconf.Aliases = []PathAlias{}
+	pconf.SilenceThreshold = -60
+	pconf.SilenceDuration = 10 * StringDuration(time.Second)
+
+	// Push
+	pconf.Push = []PushTarget{}
 
 	// Record and playback
 	pconf.Playback = true
@@ -223,74 +286,68 @@ func (pconf *Path) setDefaults() {
 	pconf.RunOnDemandCloseAfter = 10 * StringDuration(time.Second)
 }
 
-func newPath(defaults *Path, partial *OptionalPath) *Path {
+func newPath(defaults *Path, templates map[string]*OptionalPath, partial *OptionalPath) (*Path, error) {
 	pconf := &Path{}
 	copyStructFields(pconf, defaults)
 	copyStructFields(pconf, partial.Values)
-	return pconf
+
+	if pconf.Template != "" {
+		template, ok := templates[pconf.Template]
+		if !ok {
+			return nil, fmt.Errorf("template '%s' not found", pconf.Template)
+		}
+
+		pconf = &Path{}
+		copyStructFields(pconf, defaults)
+		if template != nil {
+			copyStructFields(pconf, template.Values)
+		}
+		copyStructFields(pconf, partial.Values)
+	}
+
+	return pconf, nil
 }
 
 // Clone clones the configuration.
-func (pconf Path) Clone() *Path {
+// Values of secret files are copied instead of being read again.
+func (pconf Path) Clone() (*Path, error) {
 	enc, err := json.Marshal(pconf)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 
 	var dest Path
 	err = json.Unmarshal(enc, &dest)
 	if err != nil {
-		panic(err)
+		return nil, err
+	}
+
+	err = loadSecretFiles(&dest, secretFileValues(&pconf), false)
+	if err != nil {
+		return nil, err
 	}
 
 	dest.Regexp = pconf.Regexp
 
-	return &dest
+	return &dest, nil
 }
 
-func (pconf *Path) validate(conf *Conf, name string) error {
-	pconf.Name = name
-
-	switch {
-	case name == "all_others", name == "all":
-		pconf.Regexp = regexp.MustCompile("^.*$")
-
-	case name == "" || name[0] != '~': // normal path
-		err := isValidPathName(name)
-		if err != nil {
-			return fmt.Errorf("invalid path name '%s': %w", name, err)
-		}
-
-	default: // regular expression-based path
-		regexp, err := regexp.Compile(name[1:])
-		if err != nil {
-			return fmt.Errorf("invalid regular expression: %s", name[1:])
-		}
-		pconf.Regexp = regexp
-	}
-
-	// General
-
-	if pconf.Source != "publisher" && pconf.Source != "redirect" &&
-		pconf.Regexp != nil && !pconf.SourceOnDemand {
-		return fmt.Errorf("a path with a regular expression (or path 'all') and a static source" +
-			" must have 'sourceOnDemand' set to true")
-	}
+func validateSource(source string) error {
 	switch {
-	case pconf.Source == "publisher":
+	case source == "publisher":
 
-	case strings.HasPrefix(pconf.Source, "rtsp://") ||
-		strings.HasPrefix(pconf.Source, "rtsps://"):
-		_, err := base.ParseURL(pconf.Source)
+	case strings.HasPrefix(source, "rtsp://") ||
+		strings.HasPrefix(source, "rtsps://"):
+		_, err := base.ParseURL(source)
 		if err != nil {
-			return fmt.Errorf("'%s' is not a valid URL", pconf.Source)
+			return fmt.Errorf("'%s' is not a valid URL", source)
 		}
 
-	case strings.HasPrefix(pconf.Source, "rtmp://") ||
-		strings.HasPrefix(pconf.Source, "rtmps://"):
-		u, err := gourl.Parse(pconf.Source)
+	case strings.HasPrefix(source, "rtmp://") ||
+		strings.HasPrefix(source, "rtmps://"):
+		u, err := gourl.Parse(source)
 		if err != nil {
-			return fmt.Errorf("'%s' is not a valid URL", pconf.Source)
+			return fmt.Errorf("'%s' is not a valid URL", source)
 		}
 
 		if u.User != nil {
@@ -302,14 +359,14 @@ func (pconf *Path) validate(conf *Conf, name string) error {
 			}
 		}
 
-	case strings.HasPrefix(pconf.Source, "http://") ||
-		strings.HasPrefix(pconf.Source, "https://"):
-		u, err := gourl.Parse(pconf.Source)
+	case strings.HasPrefix(source, "http://") ||
+		strings.HasPrefix(source, "https://"):
+		u, err := gourl.Parse(source)
 		if err != nil {
-			return fmt.Errorf("'%s' is not a valid URL", pconf.Source)
+			return fmt.Errorf("'%s' is not a valid URL", source)
 		}
 		if u.Scheme != "http" && u.Scheme != "https" {
-			return fmt.Errorf("'%s' is not a valid URL", pconf.Source)
+			return fmt.Errorf("'%s' is not a valid URL", source)
 		}
 
 		if u.User != nil {
@@ -321,40 +378,105 @@ func (pconf *Path) validate(conf *Conf, name string) error {
 			}
 		}
 
-	case strings.HasPrefix(pconf.Source, "udp://"):
-		_, _, err := net.SplitHostPort(pconf.Source[len("udp://"):])
+	case strings.HasPrefix(source, "udp://"):
+		_, _, err := net.SplitHostPort(source[len("udp://"):])
 		if err != nil {
-			return fmt.Errorf("'%s' is not a valid UDP URL", pconf.Source)
+			return fmt.Errorf("'%s' is not a valid UDP URL", source)
 		}
 
-	case strings.HasPrefix(pconf.Source, "srt://"):
+	case strings.HasPrefix(source, "srt://"):
 
-		_, err := gourl.Parse(pconf.Source)
+		_, err := gourl.Parse(source)
 		if err != nil {
-			return fmt.Errorf("'%s' is not a valid URL", pconf.Source)
+			return fmt.Errorf("'%s' is not a valid URL", source)
 		}
 
-	case strings.HasPrefix(pconf.Source, "whep://") ||
-		strings.HasPrefix(pconf.Source, "wheps://"):
-		_, err := gourl.Parse(pconf.Source)
+	case strings.HasPrefix(source, "whep://") ||
+		strings.HasPrefix(source, "wheps://"):
+		_, err := gourl.Parse(source)
 		if err != nil {
-			return fmt.Errorf("'%s' is not a valid URL", pconf.Source)
+			return fmt.Errorf("'%s' is not a valid URL", source)
 		}
 
-	case pconf.Source == "redirect":
+	case strings.HasPrefix(source, composeSourcePrefix):
+		_, err := ParseComposeSource(source)
+		if err != nil {
+			return err
+		}
 
-	case pconf.Source == "rpiCamera":
+	case source == "redirect":
+
+	case source == "rpiCamera":
 
 	default:
-		return fmt.Errorf("invalid source: '%s'", pconf.Source)
+		return fmt.Errorf("invalid source: '%s'", source)
+	}
+
+	return nil
+}
+
+func (pconf *Path) validate(conf *Conf, name string) error {
+	pconf.Name = name
+
+	switch {
+	case name == "all_others", name == "all":
+		pconf.Regexp = regexp.MustCompile("^.*$")
+
+	case name == "" || name[0] != '~': // normal path
+		err := isValidPathName(name)
+		if err != nil {
+			return fmt.Errorf("invalid path name '%s': %w", name, err)
+		}
+
+	default: // regular expression-based path
+		regexp, err := regexp.Compile(name[1:])
+		if err != nil {
+			return fmt.Errorf("invalid regular expression: %s", name[1:])
+		}
+		pconf.Regexp = regexp
+	}
+
+	// General
+
+	if pconf.Source != "publisher" && pconf.Source != "redirect" &&
+		pconf.Regexp != nil && !pconf.SourceOnDemand {
+		return fmt.Errorf("a path with a regular expression (or path 'all') and a static source" +
+			" must have 'sourceOnDemand' set to true")
+	}
+	err := validateSource(pconf.Source)
+	if err != nil {
+		return err
+	}
+	if strings.HasPrefix(pconf.Source, composeSourcePrefix) {
+		entries, _ := ParseComposeSource(pconf.Source)
+		for _, e := range entries {
+			if e.Path == name {
+				return fmt.Errorf("a compose source can't read from its own path")
+			}
+		}
+	}
+	for _, source := range pconf.SourceBackups {
+		if !isStaticSource(pconf.Source) {
+			return fmt.Errorf("'sourceBackups' can only be used when source is a static source")
+		}
+		if !isStaticSource(source) {
+			return fmt.Errorf("invalid backup source: '%s'", source)
+		}
+		err := validateSource(source)
+		if err != nil {
+			return err
+		}
+	}
+	if pconf.SourceFailoverAfter < 1 {
+		return fmt.Errorf("'sourceFailoverAfter' must be greater than zero")
 	}
 	if pconf.SourceOnDemand {
 		if pconf.Source == "publisher" {
 			return fmt.Errorf("'sourceOnDemand' is useless when source is 'publisher'")
 		}
 	}
-	if pconf.SRTReadPassphrase != "" {
-		err := srtCheckPassphrase(pconf.SRTReadPassphrase)
+	if !pconf.SRTReadPassphrase.IsEmpty() {
+		err := srtCheckPassphrase(pconf.SRTReadPassphrase.GetValue())
 		if err != nil {
 			return fmt.Errorf("invalid 'readRTPassphrase': %w", err)
 		}
@@ -373,6 +495,45 @@ func (pconf *Path) validate(conf *Conf, name string) error {
 		}
 	}
 
+	for _, alias := range pconf.Aliases {
+		if pconf.Regexp != nil {
+			return fmt.Errorf("'aliases' can't be used with regular expression paths")
+		}
+		err := isValidPathName(alias.Name)
+		if err != nil {
+			return fmt.Errorf("invalid alias name '%s': %w", alias.Name, err)
+		}
+	}
+
+	// Push
+
+	for i := range pconf.Push {
+		err := pconf.Push[i].validate()
+		if err != nil {
+			return err
+		}
+	}
+
+	if pconf.TrackFilter != "" {
+		_, err := ParseTrackSelection(pconf.TrackFilter)
+		if err != nil {
+			return fmt.Errorf("invalid 'trackFilter': %w", err)
+		}
+	}
+
+	if pconf.StatsMaxBitrate != 0 && pconf.StatsMinBitrate > pconf.StatsMaxBitrate {
+		return fmt.Errorf("'statsMinBitrate' must be lower than 'statsMaxBitrate'")
+	}
+	if pconf.StatsMinFPS < 0 {
+		return fmt.Errorf("'statsMinFPS' can't be negative")
+	}
+	if pconf.SilenceThreshold > 0 {
+		return fmt.Errorf("'silenceThreshold' must be lower or equal than zero")
+	}
+	if pconf.SilenceDuration <= 0 {
+		return fmt.Errorf("'silenceDuration' must be greater than zero")
+	}
+
 	// Authentication
 
 	if (!pconf.PublishUser.IsEmpty() && pconf.PublishPass.IsEmpty()) ||
@@ -391,6 +552,20 @@ func (pconf *Path) validate(conf *Conf, name string) error {
 		(pconf.ReadUser.IsEmpty() && !pconf.ReadPass.IsEmpty()) {
 		return fmt.Errorf("read username and password must be both filled")
 	}
+	for _, alias := range pconf.Aliases {
+		if (!alias.ReadUser.IsEmpty() && alias.ReadPass.IsEmpty()) ||
+			(alias.ReadUser.IsEmpty() && !alias.ReadPass.IsEmpty()) {
+			return fmt.Errorf("alias '%s': read username and password must be both filled", alias.Name)
+		}
+		if contains(conf.AuthMethods, headers.AuthDigest) &&
+			(alias.ReadUser.IsHashed() || alias.ReadPass.IsHashed()) {
+			return fmt.Errorf("hashed credentials can't be used when the digest auth method is available")
+		}
+		if conf.ExternalAuthenticationURL != "" &&
+			(!alias.ReadUser.IsEmpty() || len(alias.ReadIPs) > 0) {
+			return fmt.Errorf("credentials or IPs can't be used together with 'externalAuthenticationURL'")
+		}
+	}
 	if contains(conf.AuthMethods, headers.AuthDigest) {
 		if pconf.PublishUser.IsHashed() ||
 			pconf.PublishPass.IsHashed() ||
@@ -413,12 +588,23 @@ func (pconf *Path) validate(conf *Conf, name string) error {
 	if pconf.DisablePublisherOverride != nil {
 		pconf.OverridePublisher = !*pconf.DisablePublisherOverride
 	}
-	if pconf.SRTPublishPassphrase != "" {
+	if pconf.PublisherReconnectGrace != 0 && pconf.Source != "publisher" {
+		return fmt.Errorf("'publisherReconnectGrace' can only be used when source is 'publisher'")
+	}
+	if pconf.OfflineSource != "" {
+		if pconf.Source != "publisher" {
+			return fmt.Errorf("'offlineSource' can only be used when source is 'publisher'")
+		}
+		if pconf.RunOnDemand != "" {
+			return fmt.Errorf("'offlineSource' can't be used together with 'runOnDemand'")
+		}
+	}
+	if !pconf.SRTPublishPassphrase.IsEmpty() {
 		if pconf.Source != "publisher" {
 			return fmt.Errorf("'srtPublishPassphase' can only be used when source is 'publisher'")
 		}
 
-		err := srtCheckPassphrase(pconf.SRTPublishPassphrase)
+		err := srtCheckPassphrase(pconf.SRTPublishPassphrase.GetValue())
 		if err != nil {
 			return fmt.Errorf("invalid 'srtPublishPassphrase': %w", err)
 		}
@@ -502,6 +688,33 @@ func (pconf *Path) validate(conf *Conf, name string) error {
 	if (pconf.RunOnDemand != "" || pconf.RunOnUnDemand != "") && pconf.Source != "publisher" {
 		return fmt.Errorf("'runOnDemand' and 'runOnUnDemand' can be used only when source is 'publisher'")
 	}
+	for _, hook := range []struct {
+		name string
+		v    string
+	}{
+		{"runOnInit", pconf.RunOnInit},
+		{"runOnDemand", pconf.RunOnDemand},
+		{"runOnUnDemand", pconf.RunOnUnDemand},
+		{"runOnReady", pconf.RunOnReady},
+		{"runOnNotReady", pconf.RunOnNotReady},
+		{"runOnRead", pconf.RunOnRead},
+		{"runOnUnread", pconf.RunOnUnread},
+		{"runOnRecordSegmentCreate", pconf.RunOnRecordSegmentCreate},
+		{"runOnRecordSegmentComplete", pconf.RunOnRecordSegmentComplete},
+		{"runOnRecordSegmentDelete", pconf.RunOnRecordSegmentDelete},
+		{"runOnPublisherChange", pconf.RunOnPublisherChange},
+		{"runOnNoData", pconf.RunOnNoData},
+		{"runOnStatsThreshold", pconf.RunOnStatsThreshold},
+		{"runOnSilence", pconf.RunOnSilence},
+		{"runOnSound", pconf.RunOnSound},
+		{"runOnPathCreate", pconf.RunOnPathCreate},
+		{"runOnPathDestroy", pconf.RunOnPathDestroy},
+	} {
+		err := checkHook(hook.name, hook.v)
+		if err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
@@ -511,19 +724,24 @@ func (pconf *Path) Equal(other *Path) bool {
 	return reflect.DeepEqual(pconf, other)
 }
 
+func isStaticSource(source string) bool {
+	return strings.HasPrefix(source, "rtsp://") ||
+		strings.HasPrefix(source, "rtsps://") ||
+		strings.HasPrefix(source, "rtmp://") ||
+		strings.HasPrefix(source, "rtmps://") ||
+		strings.HasPrefix(source, "http://") ||
+		strings.HasPrefix(source, "https://") ||
+		strings.HasPrefix(source, "udp://") ||
+		strings.HasPrefix(source, "srt://") ||
+		strings.HasPrefix(source, "whep://") ||
+		strings.HasPrefix(source, "wheps://") ||
+		strings.HasPrefix(source, composeSourcePrefix) ||
+		source == "rpiCamera"
+}
+
 // HasStaticSource checks whether the path has a static source.
 func (pconf Path) HasStaticSource() bool {
-	return strings.HasPrefix(pconf.Source, "rtsp://") ||
-		strings.HasPrefix(pconf.Source, "rtsps://") ||
-		strings.HasPrefix(pconf.Source, "rtmp://") ||
-		strings.HasPrefix(pconf.Source, "rtmps://") ||
-		strings.HasPrefix(pconf.Source, "http://") ||
-		strings.HasPrefix(pconf.Source, "https://") ||
-		strings.HasPrefix(pconf.Source, "udp://") ||
-		strings.HasPrefix(pconf.Source, "srt://") ||
-		strings.HasPrefix(pconf.Source, "whep://") ||
-		strings.HasPrefix(pconf.Source, "wheps://") ||
-		pconf.Source == "rpiCamera"
+	return isStaticSource(pconf.Source)
 }
 
 // HasOnDemandStaticSource checks whether the path has a on demand static source.
@@ -0,0 +1,29 @@
+package conf
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// PathAlias is an additional name of a path.
+type PathAlias struct {
+	Name     string     `json:"name"`
+	ReadUser Credential `json:"readUser"`
+	ReadPass Credential `json:"readPass"`
+	ReadIPs  IPsOrCIDRs `json:"readIPs"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+// An alias can be expressed with its name only.
+func (d *PathAlias) UnmarshalJSON(b []byte) error {
+	var name string
+	if err := json.Unmarshal(b, &name); err == nil {
+		*d = PathAlias{Name: name}
+		return nil
+	}
+
+	type alias PathAlias
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	return dec.Decode((*alias)(d))
+}
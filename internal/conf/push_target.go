@@ -0,0 +1,74 @@
+package conf
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	gourl "net/url"
+	"strings"
+	"time"
+
+	"github.com/bluenviron/gortsplib/v4/pkg/base"
+)
+
+// PushTarget is a destination the stream of a path is pushed to.
+type PushTarget struct {
+	URL         string         `json:"url"`
+	RetryPause  StringDuration `json:"retryPause"`
+	MaxRetries  int            `json:"maxRetries"`
+	Fingerprint string         `json:"fingerprint"`
+}
+
+const defaultPushRetryPause = 5 * StringDuration(time.Second)
+
+// UnmarshalJSON implements json.Unmarshaler.
+// A push target can be expressed with its URL only.
+func (d *PushTarget) UnmarshalJSON(b []byte) error {
+	var u string
+	if err := json.Unmarshal(b, &u); err == nil {
+		*d = PushTarget{URL: u}
+		return nil
+	}
+
+	type alias PushTarget
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	return dec.Decode((*alias)(d))
+}
+
+func (d *PushTarget) validate() error {
+	switch {
+	case strings.HasPrefix(d.URL, "rtsp://") ||
+		strings.HasPrefix(d.URL, "rtsps://"):
+		_, err := base.ParseURL(d.URL)
+		if err != nil {
+			return fmt.Errorf("'%s' is not a valid URL", d.URL)
+		}
+
+	case strings.HasPrefix(d.URL, "rtmp://") ||
+		strings.HasPrefix(d.URL, "rtmps://"),
+		strings.HasPrefix(d.URL, "srt://"),
+		strings.HasPrefix(d.URL, "whip://") ||
+			strings.HasPrefix(d.URL, "whips://"):
+		_, err := gourl.Parse(d.URL)
+		if err != nil {
+			return fmt.Errorf("'%s' is not a valid URL", d.URL)
+		}
+
+	default:
+		return fmt.Errorf("invalid push target: '%s'", d.URL)
+	}
+
+	if d.RetryPause < 0 {
+		return fmt.Errorf("push target '%s': 'retryPause' can't be negative", d.URL)
+	}
+	if d.RetryPause == 0 {
+		d.RetryPause = defaultPushRetryPause
+	}
+
+	if d.MaxRetries < 0 {
+		return fmt.Errorf("push target '%s': 'maxRetries' can't be negative", d.URL)
+	}
+
+	return nil
+}
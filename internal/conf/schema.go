@@ -0,0 +1,15 @@
+package conf
+
+import (
+	_ "embed"
+)
+
+//go:generate go run ./jsonschema
+
+//go:embed schema.json
+var schema []byte
+
+// Schema returns the JSON Schema of the configuration.
+func Schema() []byte {
+	return schema
+}
@@ -0,0 +1,181 @@
+package conf
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+)
+
+const secretFilePrefix = "file:"
+
+// readSecretFile reads a secret from a file, like a Docker or Kubernetes secret.
+func readSecretFile(fpath string) (string, error) {
+	byts, err := os.ReadFile(fpath)
+	if err != nil {
+		return "", fmt.Errorf("unable to read secret file: %w", err)
+	}
+
+	return strings.TrimRight(string(byts), "\r\n"), nil
+}
+
+// secretFile is implemented by parameters whose value can be read from a file.
+type secretFile interface {
+	// fileState returns the path of the file, its value and whether it has been read.
+	fileState() (string, string, bool)
+
+	// setFileValue sets the value read from the file.
+	setFileValue(value string) error
+}
+
+func walkSecretFiles(v reflect.Value, cb func(secretFile) error) error {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return walkSecretFiles(v.Elem(), cb)
+
+	case reflect.Struct:
+		if v.CanAddr() {
+			if s, ok := v.Addr().Interface().(secretFile); ok {
+				return cb(s)
+			}
+		}
+
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				err := walkSecretFiles(v.Field(i), cb)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			err := walkSecretFiles(v.Index(i), cb)
+			if err != nil {
+				return err
+			}
+		}
+
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			err := walkSecretFiles(iter.Value(), cb)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// secretFileValues returns values of secret files that have already been read.
+func secretFileValues(v interface{}) map[string]string {
+	ret := make(map[string]string)
+
+	walkSecretFiles(reflect.ValueOf(v), func(s secretFile) error { //nolint:errcheck
+		fpath, value, loaded := s.fileState()
+		if loaded {
+			ret[fpath] = value
+		}
+		return nil
+	})
+
+	return ret
+}
+
+// loadSecretFiles fills secrets that reference a file which has not been read yet.
+// Values are taken from the given map; if read is true, files that are not in the map are read from disk.
+func loadSecretFiles(v interface{}, values map[string]string, read bool) error {
+	return walkSecretFiles(reflect.ValueOf(v), func(s secretFile) error {
+		fpath, _, loaded := s.fileState()
+		if fpath == "" || loaded {
+			return nil
+		}
+
+		value, ok := values[fpath]
+		if !ok {
+			if !read {
+				return nil
+			}
+
+			var err error
+			value, err = readSecretFile(fpath)
+			if err != nil {
+				return err
+			}
+
+			values[fpath] = value
+		}
+
+		return s.setFileValue(value)
+	})
+}
+
+// Secret is a parameter that contains a secret.
+// The secret can be read from a file by using the "file:" prefix.
+// The file is read when the configuration is validated, and its value is preserved by Clone().
+type Secret struct {
+	value  string
+	file   string
+	loaded bool
+}
+
+// MarshalJSON implements json.Marshaler.
+func (d Secret) MarshalJSON() ([]byte, error) {
+	if d.file != "" {
+		return json.Marshal(secretFilePrefix + d.file)
+	}
+	return json.Marshal(d.value)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (d *Secret) UnmarshalJSON(b []byte) error {
+	var in string
+	if err := json.Unmarshal(b, &in); err != nil {
+		return err
+	}
+
+	if strings.HasPrefix(in, secretFilePrefix) {
+		*d = Secret{
+			file: in[len(secretFilePrefix):],
+		}
+		return nil
+	}
+
+	*d = Secret{
+		value: in,
+	}
+	return nil
+}
+
+// UnmarshalEnv implements env.Unmarshaler.
+func (d *Secret) UnmarshalEnv(_ string, v string) error {
+	byts, _ := json.Marshal(v)
+	return d.UnmarshalJSON(byts)
+}
+
+func (d *Secret) fileState() (string, string, bool) {
+	return d.file, d.value, d.loaded
+}
+
+func (d *Secret) setFileValue(value string) error {
+	d.value = value
+	d.loaded = true
+	return nil
+}
+
+// GetValue returns the value of the secret.
+func (d *Secret) GetValue() string {
+	return d.value
+}
+
+// IsEmpty returns true if the secret is not configured.
+func (d *Secret) IsEmpty() bool {
+	return d.value == ""
+}
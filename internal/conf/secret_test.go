@@ -0,0 +1,81 @@
+package conf
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretUnmarshalEnv(t *testing.T) {
+	for _, ca := range []string{
+		`my"secret`,
+		`my\secret`,
+		`my\"secret`,
+	} {
+		t.Run(ca, func(t *testing.T) {
+			var s Secret
+			err := s.UnmarshalEnv("", ca)
+			require.NoError(t, err)
+			require.Equal(t, ca, s.GetValue())
+		})
+	}
+}
+
+func TestSecretFileClone(t *testing.T) {
+	dir := t.TempDir()
+
+	secretPath := filepath.Join(dir, "secret")
+	err := os.WriteFile(secretPath, []byte("mysecret\n"), 0o644)
+	require.NoError(t, err)
+
+	passPath := filepath.Join(dir, "pass")
+	err = os.WriteFile(passPath, []byte("mypass\n"), 0o644)
+	require.NoError(t, err)
+
+	confPath := filepath.Join(dir, "mediamtx.yml")
+	err = os.WriteFile(confPath, []byte("webhookSecret: file:"+secretPath+"\n"+
+		"paths:\n"+
+		"  cam1:\n"+
+		"    readUser: myuser\n"+
+		"    readPass: file:"+passPath+"\n"), 0o644)
+	require.NoError(t, err)
+
+	conf, _, err := Load(confPath, nil)
+	require.NoError(t, err)
+	require.Equal(t, "mysecret", conf.WebhookSecret.GetValue())
+	require.Equal(t, "mypass", conf.Paths["cam1"].ReadPass.GetValue())
+
+	t.Run("rotated", func(t *testing.T) {
+		err = os.WriteFile(secretPath, []byte("othersecret\n"), 0o644)
+		require.NoError(t, err)
+
+		clone, err2 := conf.Clone()
+		require.NoError(t, err2)
+
+		err2 = clone.Validate()
+		require.NoError(t, err2)
+		require.Equal(t, "mysecret", clone.WebhookSecret.GetValue())
+	})
+
+	t.Run("removed", func(t *testing.T) {
+		err = os.Remove(secretPath)
+		require.NoError(t, err)
+		err = os.Remove(passPath)
+		require.NoError(t, err)
+
+		clone, err2 := conf.Clone()
+		require.NoError(t, err2)
+
+		err2 = clone.Validate()
+		require.NoError(t, err2)
+		require.Equal(t, "mysecret", clone.WebhookSecret.GetValue())
+		require.Equal(t, "mypass", clone.Paths["cam1"].ReadPass.GetValue())
+
+		pathClone, err2 := conf.Paths["cam1"].Clone()
+		require.NoError(t, err2)
+		require.Equal(t, "mypass", pathClone.ReadPass.GetValue())
+		require.True(t, conf.Paths["cam1"].Equal(pathClone))
+	})
+}
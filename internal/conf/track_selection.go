@@ -0,0 +1,120 @@
+package conf
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bluenviron/gortsplib/v4/pkg/description"
+)
+
+// TrackSelection is a list of tracks, selected by media type or by media index,
+// in the format "video,audio,2".
+type TrackSelection struct {
+	mediaTypes map[description.MediaType]struct{}
+	indexes    map[int]struct{}
+}
+
+// ParseTrackSelection parses a track selection.
+func ParseTrackSelection(v string) (*TrackSelection, error) {
+	ts := &TrackSelection{
+		mediaTypes: make(map[description.MediaType]struct{}),
+		indexes:    make(map[int]struct{}),
+	}
+
+	for _, entry := range strings.Split(v, ",") {
+		entry = strings.TrimSpace(entry)
+
+		switch entry {
+		case "video", "audio", "application":
+			ts.mediaTypes[description.MediaType(entry)] = struct{}{}
+
+		default:
+			index, err := strconv.ParseUint(entry, 10, 31)
+			if err != nil {
+				return nil, fmt.Errorf("invalid track '%s' (allowed are video, audio, application or a media index)", entry)
+			}
+			ts.indexes[int(index)] = struct{}{}
+		}
+	}
+
+	return ts, nil
+}
+
+// TrackSelectionFromQuery returns the track selection contained in the 'tracks' parameter of a query.
+// It returns nil when the parameter is not present.
+func TrackSelectionFromQuery(rawQuery string) (*TrackSelection, error) {
+	q, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	v := q.Get("tracks")
+	if v == "" {
+		return nil, nil
+	}
+
+	return ParseTrackSelection(v)
+}
+
+// String returns the track selection in a normalized form,
+// that is the same for selections that contain the same entries.
+func (ts *TrackSelection) String() string {
+	var entries []string
+
+	for _, typ := range []description.MediaType{
+		description.MediaTypeVideo,
+		description.MediaTypeAudio,
+		description.MediaTypeApplication,
+	} {
+		if _, ok := ts.mediaTypes[typ]; ok {
+			entries = append(entries, string(typ))
+		}
+	}
+
+	indexes := make([]int, 0, len(ts.indexes))
+	for index := range ts.indexes {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+
+	for _, index := range indexes {
+		entries = append(entries, strconv.FormatInt(int64(index), 10))
+	}
+
+	return strings.Join(entries, ",")
+}
+
+// Apply returns a description that contains only the selected medias.
+// When the track selection is nil, the description is returned unchanged.
+func (ts *TrackSelection) Apply(desc *description.Session) (*description.Session, error) {
+	if ts == nil {
+		return desc, nil
+	}
+
+	var medias []*description.Media
+
+	for i, medi := range desc.Medias {
+		_, ok1 := ts.mediaTypes[medi.Type]
+		_, ok2 := ts.indexes[i]
+		if ok1 || ok2 {
+			medias = append(medias, medi)
+		}
+	}
+
+	if len(medias) == 0 {
+		return nil, fmt.Errorf("none of the selected tracks is available")
+	}
+
+	if len(medias) == len(desc.Medias) {
+		return desc, nil
+	}
+
+	return &description.Session{
+		Title:     desc.Title,
+		FECGroups: desc.FECGroups,
+		Medias:    medias,
+	}, nil
+}
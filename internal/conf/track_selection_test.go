@@ -0,0 +1,75 @@
+package conf
+
+import (
+	"testing"
+
+	"github.com/bluenviron/gortsplib/v4/pkg/description"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrackSelection(t *testing.T) {
+	desc := &description.Session{
+		Medias: []*description.Media{
+			{Type: description.MediaTypeVideo},
+			{Type: description.MediaTypeAudio},
+			{Type: description.MediaTypeVideo},
+			{Type: description.MediaTypeApplication},
+		},
+	}
+
+	for _, ca := range []struct {
+		name    string
+		query   string
+		indexes []int
+	}{
+		{
+			"none",
+			"key=val",
+			[]int{0, 1, 2, 3},
+		},
+		{
+			"media type",
+			"tracks=video",
+			[]int{0, 2},
+		},
+		{
+			"media index",
+			"tracks=1,3",
+			[]int{1, 3},
+		},
+		{
+			"mixed",
+			"tracks=audio,2&key=val",
+			[]int{1, 2},
+		},
+	} {
+		t.Run(ca.name, func(t *testing.T) {
+			ts, err := TrackSelectionFromQuery(ca.query)
+			require.NoError(t, err)
+
+			filtered, err := ts.Apply(desc)
+			require.NoError(t, err)
+
+			var indexes []int
+			for _, medi := range filtered.Medias {
+				for i, medi2 := range desc.Medias {
+					if medi == medi2 {
+						indexes = append(indexes, i)
+					}
+				}
+			}
+			require.Equal(t, ca.indexes, indexes)
+		})
+	}
+
+	ts, err := ParseTrackSelection("7")
+	require.NoError(t, err)
+	_, err = ts.Apply(desc)
+	require.EqualError(t, err, "none of the selected tracks is available")
+}
+
+func TestTrackSelectionString(t *testing.T) {
+	ts, err := ParseTrackSelection("3, audio,1,video,audio")
+	require.NoError(t, err)
+	require.Equal(t, "video,audio,1,3", ts.String())
+}
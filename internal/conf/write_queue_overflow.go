@@ -0,0 +1,56 @@
+package conf
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// WriteQueueOverflow is the action performed when the write queue of a reader is full.
+type WriteQueueOverflow int
+
+// supported values.
+const (
+	WriteQueueOverflowDropUntilKeyFrame WriteQueueOverflow = iota
+	WriteQueueOverflowDisconnect
+)
+
+// MarshalJSON implements json.Marshaler.
+func (d WriteQueueOverflow) MarshalJSON() ([]byte, error) {
+	var out string
+
+	switch d {
+	case WriteQueueOverflowDisconnect:
+		out = "disconnect"
+
+	default:
+		out = "dropUntilKeyFrame"
+	}
+
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (d *WriteQueueOverflow) UnmarshalJSON(b []byte) error {
+	var in string
+	if err := json.Unmarshal(b, &in); err != nil {
+		return err
+	}
+
+	switch in {
+	case "dropUntilKeyFrame":
+		*d = WriteQueueOverflowDropUntilKeyFrame
+
+	case "disconnect":
+		*d = WriteQueueOverflowDisconnect
+
+	default:
+		return fmt.Errorf("invalid write queue overflow policy '%s'", in)
+	}
+
+	return nil
+}
+
+// UnmarshalEnv implements env.Unmarshaler.
+func (d *WriteQueueOverflow) UnmarshalEnv(_ string, v string) error {
+	return d.UnmarshalJSON([]byte(`"` + v + `"`))
+}
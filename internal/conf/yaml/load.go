@@ -4,10 +4,62 @@ package yaml
 import (
 	"encoding/json"
 	"fmt"
+	"os"
+	"regexp"
 
 	"gopkg.in/yaml.v2"
 )
 
+var reVariable = regexp.MustCompile(`\$\$\{|\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+// interpolate replaces ${VAR} and ${VAR:-default} with the value of environment variables.
+// Variables that are not set and have no default value are left untouched,
+// in order not to alter commands that make use of the same syntax.
+// $${ can be used to insert a literal ${.
+func interpolate(v string) string {
+	return reVariable.ReplaceAllStringFunc(v, func(m string) string {
+		if m == "$${" {
+			return "${"
+		}
+
+		sub := reVariable.FindStringSubmatch(m)
+		val, ok := os.LookupEnv(sub[1])
+
+		if sub[2] != "" {
+			if val == "" {
+				return sub[3]
+			}
+			return val
+		}
+
+		if !ok {
+			return m
+		}
+		return val
+	})
+}
+
+func interpolateValues(i interface{}) interface{} {
+	switch x := i.(type) {
+	case map[string]interface{}:
+		for k, v := range x {
+			x[k] = interpolateValues(v)
+		}
+		return x
+
+	case []interface{}:
+		for i, v := range x {
+			x[i] = interpolateValues(v)
+		}
+		return x
+
+	case string:
+		return interpolate(x)
+	}
+
+	return i
+}
+
 func convertKeys(i interface{}) (interface{}, error) {
 	switch x := i.(type) {
 	case map[interface{}]interface{}:
@@ -56,6 +108,9 @@ func Load(buf []byte, dest interface{}) error {
 		return err
 	}
 
+	// replace environment variables inside values
+	temp = interpolateValues(temp)
+
 	// convert the generic map into JSON
 	buf, err = json.Marshal(temp)
 	if err != nil {
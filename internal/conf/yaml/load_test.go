@@ -0,0 +1,36 @@
+package yaml
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadInterpolation(t *testing.T) {
+	t.Setenv("MTX_TEST_USER", "myuser")
+	t.Setenv("MTX_TEST_EMPTY", "")
+
+	var dest map[string]interface{}
+	err := Load([]byte("user: ${MTX_TEST_USER}\n"+
+		"source: rtsp://${MTX_TEST_USER}@${MTX_TEST_HOST:-localhost}:8554/mypath\n"+
+		"empty: ${MTX_TEST_EMPTY:-default}\n"+
+		"unset: ${MTX_TEST_UNSET}\n"+
+		"escaped: $${MTX_TEST_USER}\n"+
+		"list:\n"+
+		"  - ${MTX_TEST_USER}\n"+
+		"nested:\n"+
+		"  value: ${MTX_TEST_USER}\n"+
+		"number: 5\n"), &dest)
+	require.NoError(t, err)
+
+	require.Equal(t, map[string]interface{}{
+		"user":    "myuser",
+		"source":  "rtsp://myuser@localhost:8554/mypath",
+		"empty":   "default",
+		"unset":   "${MTX_TEST_UNSET}",
+		"escaped": "${MTX_TEST_USER}",
+		"list":    []interface{}{"myuser"},
+		"nested":  map[string]interface{}{"value": "myuser"},
+		"number":  float64(5),
+	}, dest)
+}
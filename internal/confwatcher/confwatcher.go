@@ -4,6 +4,7 @@ package confwatcher
 import (
 	"os"
 	"path/filepath"
+	"sync"
 	"time"
 
 	"github.com/fsnotify/fsnotify"
@@ -15,10 +16,14 @@ const (
 )
 
 // ConfWatcher is a configuration file watcher.
+// It can also watch a directory that contains additional configuration files.
 type ConfWatcher struct {
 	inner       *fsnotify.Watcher
 	watchedPath string
 
+	mutex      sync.Mutex
+	watchedDir string
+
 	// in
 	terminate chan struct{}
 
@@ -61,6 +66,47 @@ func New(confPath string) (*ConfWatcher, error) {
 	return w, nil
 }
 
+// WatchDirectory sets the directory to watch, in addition to the configuration file.
+// An empty path disables directory watching.
+func (w *ConfWatcher) WatchDirectory(dirPath string) error {
+	if dirPath != "" {
+		dirPath, _ = filepath.Abs(dirPath)
+	}
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if dirPath == w.watchedDir {
+		return nil
+	}
+
+	if w.watchedDir != "" && w.watchedDir != filepath.Dir(w.watchedPath) {
+		w.inner.Remove(w.watchedDir) //nolint:errcheck
+	}
+
+	if dirPath != "" && dirPath != filepath.Dir(w.watchedPath) {
+		err := w.inner.Add(dirPath)
+		if err != nil {
+			w.watchedDir = ""
+			return err
+		}
+	}
+
+	w.watchedDir = dirPath
+	return nil
+}
+
+func (w *ConfWatcher) isInWatchedDir(eventPath string) bool {
+	ext := filepath.Ext(eventPath)
+	if ext != ".yml" && ext != ".yaml" {
+		return false
+	}
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.watchedDir != "" && filepath.Dir(eventPath) == w.watchedDir
+}
+
 // Close closes a ConfWatcher.
 func (w *ConfWatcher) Close() {
 	close(w.terminate)
@@ -82,10 +128,22 @@ outer:
 			}
 
 			currentWatchedPath, _ := filepath.EvalSymlinks(w.watchedPath)
-			eventPath, _ := filepath.Abs(event.Name)
-			eventPath, _ = filepath.EvalSymlinks(eventPath)
-
-			if currentWatchedPath == "" {
+			absEventPath, _ := filepath.Abs(event.Name)
+			eventPath, _ := filepath.EvalSymlinks(absEventPath)
+
+			if absEventPath != w.watchedPath && w.isInWatchedDir(absEventPath) {
+				// a file in the watched directory was created, changed or removed
+				if event.Op != fsnotify.Chmod {
+					time.Sleep(additionalWait)
+					lastCalled = time.Now()
+
+					select {
+					case w.signal <- struct{}{}:
+					case <-w.terminate:
+						break outer
+					}
+				}
+			} else if currentWatchedPath == "" {
 				// watched file was removed; wait for write event to trigger reload
 				previousWatchedPath = ""
 			} else if currentWatchedPath != previousWatchedPath ||
@@ -2,6 +2,7 @@ package confwatcher
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -153,3 +154,44 @@ func TestSymlinkDeleteCreate(t *testing.T) {
 		return
 	}
 }
+
+func TestDirectory(t *testing.T) {
+	dir, err := os.MkdirTemp("", "confwatcher-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	fpath := filepath.Join(dir, "mediamtx.yml")
+	err = os.WriteFile(fpath, []byte("{}"), 0o644)
+	require.NoError(t, err)
+
+	err = os.Mkdir(filepath.Join(dir, "paths.d"), 0o755)
+	require.NoError(t, err)
+
+	w, err := New(fpath)
+	require.NoError(t, err)
+	defer w.Close()
+
+	err = w.WatchDirectory(filepath.Join(dir, "paths.d"))
+	require.NoError(t, err)
+
+	// files without a configuration extension are ignored
+	err = os.WriteFile(filepath.Join(dir, "paths.d", "test.txt"), []byte("{}"), 0o644)
+	require.NoError(t, err)
+
+	select {
+	case <-w.Watch():
+		t.Errorf("should not happen")
+		return
+	case <-time.After(500 * time.Millisecond):
+	}
+
+	err = os.WriteFile(filepath.Join(dir, "paths.d", "cam1.yml"), []byte("{}"), 0o644)
+	require.NoError(t, err)
+
+	select {
+	case <-w.Watch():
+	case <-time.After(500 * time.Millisecond):
+		t.Errorf("timed out")
+		return
+	}
+}
@@ -114,6 +114,19 @@ func TestAPIConfigGlobalGet(t *testing.T) {
 	require.Equal(t, true, out["api"])
 }
 
+func TestAPIConfigSchema(t *testing.T) {
+	p, ok := newInstance("api: yes\n")
+	require.Equal(t, true, ok)
+	defer p.Close()
+
+	hc := &http.Client{Transport: &http.Transport{}}
+
+	var out map[string]interface{}
+	httpRequest(t, hc, http.MethodGet, "http://localhost:9997/v3/config/schema", nil, &out)
+	require.Equal(t, "object", out["type"])
+	require.Contains(t, out["properties"], "readTimeout")
+}
+
 func TestAPIConfigGlobalPatch(t *testing.T) {
 	p, ok := newInstance("api: yes\n")
 	require.Equal(t, true, ok)
@@ -325,6 +338,34 @@ func TestAPIConfigPathsPatch(t *testing.T) { //nolint:dupl
 	require.Equal(t, true, out["rpiCameraVFlip"])
 }
 
+func TestAPIConfigPathsPatchTemplate(t *testing.T) {
+	p, ok := newInstance("api: yes\n" +
+		"pathTemplates:\n" +
+		"  camera:\n" +
+		"    sourceOnDemand: yes\n" +
+		"    maxReaders: 5\n")
+	require.Equal(t, true, ok)
+	defer p.Close()
+
+	hc := &http.Client{Transport: &http.Transport{}}
+
+	httpRequest(t, hc, http.MethodPost, "http://localhost:9997/v3/config/paths/add/mypath", map[string]interface{}{
+		"template": "camera",
+		"source":   "rtsp://127.0.0.1:9999/mypath",
+	}, nil)
+
+	httpRequest(t, hc, http.MethodPatch, "http://localhost:9997/v3/config/paths/patch/mypath", map[string]interface{}{
+		"source": "rtsp://127.0.0.1:9998/mypath",
+	}, nil)
+
+	var out map[string]interface{}
+	httpRequest(t, hc, http.MethodGet, "http://localhost:9997/v3/config/paths/get/mypath", nil, &out)
+	require.Equal(t, "camera", out["template"])
+	require.Equal(t, "rtsp://127.0.0.1:9998/mypath", out["source"])
+	require.Equal(t, true, out["sourceOnDemand"])
+	require.Equal(t, float64(5), out["maxReaders"])
+}
+
 func TestAPIConfigPathsReplace(t *testing.T) { //nolint:dupl
 	p, ok := newInstance("api: yes\n")
 	require.Equal(t, true, ok)
@@ -637,6 +678,54 @@ func TestAPIPathsGet(t *testing.T) {
 	}
 }
 
+func TestAPIHooksList(t *testing.T) {
+	p, ok := newInstance("api: yes\n" +
+		"paths:\n" +
+		"  mypath:\n" +
+		"    runOnInit: sleep 30\n")
+	require.Equal(t, true, ok)
+	defer p.Close()
+
+	hc := &http.Client{Transport: &http.Transport{}}
+
+	type hook struct {
+		Name           string `json:"name"`
+		Path           string `json:"path"`
+		Command        string `json:"command"`
+		PID            *int   `json:"pid"`
+		Restarts       int    `json:"restarts"`
+		LastExitStatus *int   `json:"lastExitStatus"`
+	}
+
+	type hookList struct {
+		ItemCount int    `json:"itemCount"`
+		PageCount int    `json:"pageCount"`
+		Items     []hook `json:"items"`
+	}
+
+	var out hookList
+
+	for {
+		httpRequest(t, hc, http.MethodGet, "http://localhost:9997/v3/hooks/list", nil, &out)
+		if len(out.Items) == 1 && out.Items[0].PID != nil {
+			break
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	out.Items[0].PID = nil
+
+	require.Equal(t, hookList{
+		ItemCount: 1,
+		PageCount: 1,
+		Items: []hook{{
+			Name:    "runOnInit",
+			Path:    "mypath",
+			Command: "sleep 30",
+		}},
+	}, out)
+}
+
 func TestAPIProtocolList(t *testing.T) {
 	serverCertFpath, err := writeTempFile(serverCert)
 	require.NoError(t, err)
@@ -96,6 +96,17 @@ func doAuthentication(
 		pathIPs = pathConf.ReadIPs
 		pathUser = pathConf.ReadUser
 		pathPass = pathConf.ReadPass
+
+		// aliases can override read credentials
+		if alias := pathConf.FindAlias(accessRequest.Name); alias != nil {
+			if len(alias.ReadIPs) != 0 {
+				pathIPs = alias.ReadIPs
+			}
+			if !alias.ReadUser.IsEmpty() {
+				pathUser = alias.ReadUser
+				pathPass = alias.ReadPass
+			}
+		}
 	}
 
 	if pathIPs != nil {
@@ -124,3 +135,39 @@ func doAuthentication(
 
 	return nil
 }
+
+// isAuthChallenge checks whether an authentication failure is used to ask
+// the client for credentials, instead of being an actual failure.
+func isAuthChallenge(accessRequest defs.PathAccessRequest) bool {
+	switch accessRequest.Proto {
+	case defs.AuthProtocolRTSP:
+		if accessRequest.RTSPRequest == nil {
+			return false
+		}
+		_, ok := accessRequest.RTSPRequest.Header["Authorization"]
+		return !ok
+
+	case defs.AuthProtocolHLS, defs.AuthProtocolWebRTC:
+		return accessRequest.User == "" && accessRequest.Pass == ""
+	}
+
+	return false
+}
+
+// accessRequestUser returns the user of an access request.
+func accessRequestUser(accessRequest defs.PathAccessRequest) string {
+	if accessRequest.RTSPRequest != nil {
+		var rtspAuth headers.Authorization
+		err := rtspAuth.Unmarshal(accessRequest.RTSPRequest.Header["Authorization"])
+		if err == nil {
+			if rtspAuth.Method == headers.AuthBasic {
+				return rtspAuth.BasicUser
+			}
+			if rtspAuth.DigestValues.Username != nil {
+				return *rtspAuth.DigestValues.Username
+			}
+		}
+	}
+
+	return accessRequest.User
+}
@@ -0,0 +1,88 @@
+package core
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/bluenviron/mediamtx/internal/conf/decrypt"
+	"github.com/bluenviron/mediamtx/internal/conf/encrypt"
+)
+
+type encryptConfigCmd struct {
+	Key    string `help:"encryption key. If empty, a new key is generated." env:"MTX_CONFKEY"`
+	Value  string `help:"encrypt a single value instead of a file. The result can be inserted into a plain configuration."`
+	Output string `short:"o" help:"write the result into this file instead of the standard output."`
+	Input  string `arg:"" optional:"" help:"configuration file to encrypt."`
+}
+
+func (c *encryptConfigCmd) run() error {
+	key := c.Key
+
+	if key == "" {
+		var err error
+		key, err = encrypt.GenerateKey()
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(os.Stderr, "generated key (pass it to the server with MTX_CONFKEY): %s\n", key)
+	}
+
+	if c.Value != "" {
+		enc, err := encrypt.EncryptValue(key, c.Value)
+		if err != nil {
+			return err
+		}
+
+		return writeCommandOutput(c.Output, []byte(enc+"\n"))
+	}
+
+	if c.Input == "" {
+		return fmt.Errorf("either a configuration file or a value must be provided")
+	}
+
+	byts, err := os.ReadFile(c.Input)
+	if err != nil {
+		return err
+	}
+
+	enc, err := encrypt.Encrypt(key, byts)
+	if err != nil {
+		return err
+	}
+
+	return writeCommandOutput(c.Output, append(enc, '\n'))
+}
+
+type decryptConfigCmd struct {
+	Key    string `help:"encryption key." env:"MTX_CONFKEY" required:""`
+	Output string `short:"o" help:"write the result into this file instead of the standard output."`
+	Input  string `arg:"" help:"configuration file to decrypt."`
+}
+
+func (c *decryptConfigCmd) run() error {
+	byts, err := os.ReadFile(c.Input)
+	if err != nil {
+		return err
+	}
+
+	if decrypt.IsEncrypted(byts) {
+		byts, err = decrypt.Decrypt(c.Key, byts)
+	} else {
+		byts, err = decrypt.Values(c.Key, byts)
+	}
+	if err != nil {
+		return err
+	}
+
+	return writeCommandOutput(c.Output, byts)
+}
+
+func writeCommandOutput(fpath string, byts []byte) error {
+	if fpath == "" {
+		_, err := os.Stdout.Write(byts)
+		return err
+	}
+
+	return os.WriteFile(fpath, byts, 0o600)
+}
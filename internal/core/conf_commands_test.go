@@ -0,0 +1,93 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testConfKey = "vB3MFhtEhy3pIzVYLGY6xqYebOxI0t6l"
+
+func TestEncryptDecryptConfigFile(t *testing.T) {
+	dir, err := os.MkdirTemp("", "mediamtx-conf-commands")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	plain := "# comment\n" +
+		"logLevel: debug\n" +
+		"\n" +
+		"paths:\n" +
+		"  cam: # camera\n" +
+		"    readUser: myuser\n"
+
+	err = os.WriteFile(filepath.Join(dir, "plain.yml"), []byte(plain), 0o644)
+	require.NoError(t, err)
+
+	err = (&encryptConfigCmd{
+		Key:    testConfKey,
+		Input:  filepath.Join(dir, "plain.yml"),
+		Output: filepath.Join(dir, "enc.yml"),
+	}).run()
+	require.NoError(t, err)
+
+	err = (&decryptConfigCmd{
+		Key:    testConfKey,
+		Input:  filepath.Join(dir, "enc.yml"),
+		Output: filepath.Join(dir, "dec.yml"),
+	}).run()
+	require.NoError(t, err)
+
+	byts, err := os.ReadFile(filepath.Join(dir, "dec.yml"))
+	require.NoError(t, err)
+	require.Equal(t, plain, string(byts))
+
+	err = (&decryptConfigCmd{
+		Key:    "wrongkey",
+		Input:  filepath.Join(dir, "enc.yml"),
+		Output: filepath.Join(dir, "dec.yml"),
+	}).run()
+	require.EqualError(t, err, "decryption error")
+}
+
+func TestEncryptDecryptConfigValue(t *testing.T) {
+	dir, err := os.MkdirTemp("", "mediamtx-conf-commands")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	err = (&encryptConfigCmd{
+		Key:    testConfKey,
+		Value:  "mypass",
+		Output: filepath.Join(dir, "value"),
+	}).run()
+	require.NoError(t, err)
+
+	enc, err := os.ReadFile(filepath.Join(dir, "value"))
+	require.NoError(t, err)
+
+	err = os.WriteFile(filepath.Join(dir, "conf.yml"), []byte("# comment\n"+
+		"paths:\n"+
+		"  cam: # camera\n"+
+		"    readUser: myuser\n"+
+		"    readPass: "+strings.TrimSpace(string(enc))+"\n"+
+		"    # end of path\n"), 0o644)
+	require.NoError(t, err)
+
+	err = (&decryptConfigCmd{
+		Key:    testConfKey,
+		Input:  filepath.Join(dir, "conf.yml"),
+		Output: filepath.Join(dir, "dec.yml"),
+	}).run()
+	require.NoError(t, err)
+
+	byts, err := os.ReadFile(filepath.Join(dir, "dec.yml"))
+	require.NoError(t, err)
+	require.Equal(t, "# comment\n"+
+		"paths:\n"+
+		"  cam: # camera\n"+
+		"    readUser: myuser\n"+
+		"    readPass: \"mypass\"\n"+
+		"    # end of path\n", string(byts))
+}
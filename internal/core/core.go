@@ -20,6 +20,7 @@ import (
 	"github.com/bluenviron/mediamtx/internal/conf"
 	"github.com/bluenviron/mediamtx/internal/confwatcher"
 	"github.com/bluenviron/mediamtx/internal/externalcmd"
+	"github.com/bluenviron/mediamtx/internal/hooks"
 	"github.com/bluenviron/mediamtx/internal/logger"
 	"github.com/bluenviron/mediamtx/internal/metrics"
 	"github.com/bluenviron/mediamtx/internal/playback"
@@ -31,6 +32,7 @@ import (
 	"github.com/bluenviron/mediamtx/internal/servers/rtsp"
 	"github.com/bluenviron/mediamtx/internal/servers/srt"
 	"github.com/bluenviron/mediamtx/internal/servers/webrtc"
+	"github.com/bluenviron/mediamtx/internal/webhook"
 )
 
 var version = "v0.0.0"
@@ -75,9 +77,43 @@ func gatherCleanerEntries(paths map[string]*conf.Path) []record.CleanerEntry {
 	return out2
 }
 
+// gatherCleanerPathConfs returns the fields of path configurations
+// that are needed to run runOnRecordSegmentDelete.
+func gatherCleanerPathConfs(paths map[string]*conf.Path) map[string]*conf.Path {
+	hasHook := false
+	for _, pa := range paths {
+		if pa.RunOnRecordSegmentDelete != "" {
+			hasHook = true
+			break
+		}
+	}
+
+	if !hasHook {
+		return nil
+	}
+
+	// all paths are needed in order to find the configuration of a segment
+	out := make(map[string]*conf.Path, len(paths))
+
+	for name, pa := range paths {
+		out[name] = &conf.Path{
+			Regexp:                   pa.Regexp,
+			Name:                     pa.Name,
+			Aliases:                  pa.Aliases,
+			RunOnRecordSegmentDelete: pa.RunOnRecordSegmentDelete,
+		}
+	}
+
+	return out
+}
+
 var cli struct {
-	Version  bool   `help:"print version"`
-	Confpath string `arg:"" default:""`
+	Version bool `help:"print version"`
+	Run     struct {
+		Confpath string `arg:"" default:""`
+	} `cmd:"" default:"withargs" help:"run the server (default)."`
+	EncryptConfig encryptConfigCmd `cmd:"" help:"encrypt a configuration file or a single value."`
+	DecryptConfig decryptConfigCmd `cmd:"" help:"decrypt a configuration file."`
 }
 
 // Core is an instance of MediaMTX.
@@ -88,6 +124,7 @@ type Core struct {
 	conf            *conf.Conf
 	logger          *logger.Logger
 	externalCmdPool *externalcmd.Pool
+	webhookSender   *webhook.Sender
 	metrics         *metrics.Metrics
 	pprof           *pprof.PPROF
 	recordCleaner   *record.Cleaner
@@ -128,7 +165,7 @@ func New(args []string) (*Core, bool) {
 		panic(err)
 	}
 
-	_, err = parser.Parse(args)
+	ctx, err := parser.Parse(args)
 	parser.FatalIfErrorf(err)
 
 	if cli.Version {
@@ -136,6 +173,27 @@ func New(args []string) (*Core, bool) {
 		os.Exit(0)
 	}
 
+	switch ctx.Command() {
+	case "encrypt-config", "encrypt-config <input>":
+		err = cli.EncryptConfig.run()
+
+	case "decrypt-config <input>":
+		err = cli.DecryptConfig.run()
+
+	default:
+		return newCore(cli.Run.Confpath)
+	}
+
+	if err != nil {
+		fmt.Printf("ERR: %s\n", err)
+		return nil, false
+	}
+
+	os.Exit(0)
+	return nil, false
+}
+
+func newCore(confPath string) (*Core, bool) {
 	ctx, ctxCancel := context.WithCancel(context.Background())
 
 	p := &Core{
@@ -145,7 +203,8 @@ func New(args []string) (*Core, bool) {
 		done:           make(chan struct{}),
 	}
 
-	p.conf, p.confPath, err = conf.Load(cli.Confpath, defaultConfPaths)
+	var err error
+	p.conf, p.confPath, err = conf.Load(confPath, defaultConfPaths)
 	if err != nil {
 		fmt.Printf("ERR: %s\n", err)
 		return nil, false
@@ -278,6 +337,24 @@ func (p *Core) createResources(initial bool) error {
 		p.externalCmdPool = externalcmd.NewPool()
 	}
 
+	p.externalCmdPool.SetConf(externalcmd.PoolConf{
+		OutputLevel:        logger.Level(p.conf.HookOutputLevel),
+		TerminationTimeout: time.Duration(p.conf.HookTerminationTimeout),
+		RestartMaxPause:    time.Duration(p.conf.HookRestartMaxPause),
+		RestartMaxRetries:  p.conf.HookRestartMaxRetries,
+	})
+
+	if p.webhookSender == nil {
+		p.webhookSender = &webhook.Sender{
+			Secret:     p.conf.WebhookSecret.GetValue(),
+			Timeout:    p.conf.WebhookTimeout,
+			MaxRetries: p.conf.WebhookMaxRetries,
+			QueueSize:  p.conf.WebhookQueueSize,
+			Parent:     p,
+		}
+		p.webhookSender.Initialize()
+	}
+
 	if p.conf.Metrics &&
 		p.metrics == nil {
 		p.metrics = &metrics.Metrics{
@@ -308,8 +385,9 @@ func (p *Core) createResources(initial bool) error {
 	if len(cleanerEntries) != 0 &&
 		p.recordCleaner == nil {
 		p.recordCleaner = &record.Cleaner{
-			Entries: cleanerEntries,
-			Parent:  p,
+			Entries:         cleanerEntries,
+			Parent:          p,
+			OnSegmentDelete: p.onRecordSegmentDelete(p.conf.RTSPAddress, gatherCleanerPathConfs(p.conf.Paths)),
 		}
 		p.recordCleaner.Initialize()
 	}
@@ -339,7 +417,9 @@ func (p *Core) createResources(initial bool) error {
 			writeQueueSize:            p.conf.WriteQueueSize,
 			udpMaxPayloadSize:         p.conf.UDPMaxPayloadSize,
 			pathConfs:                 p.conf.Paths,
+			runOnAuthFailure:          p.conf.RunOnAuthFailure,
 			externalCmdPool:           p.externalCmdPool,
+			webhookSender:             p.webhookSender,
 			parent:                    p,
 		}
 		p.pathManager.initialize()
@@ -378,6 +458,7 @@ func (p *Core) createResources(initial bool) error {
 			RunOnConnectRestart: p.conf.RunOnConnectRestart,
 			RunOnDisconnect:     p.conf.RunOnDisconnect,
 			ExternalCmdPool:     p.externalCmdPool,
+			WebhookSender:       p.webhookSender,
 			PathManager:         p.pathManager,
 			Parent:              p,
 		}
@@ -417,6 +498,7 @@ func (p *Core) createResources(initial bool) error {
 			RunOnConnectRestart: p.conf.RunOnConnectRestart,
 			RunOnDisconnect:     p.conf.RunOnDisconnect,
 			ExternalCmdPool:     p.externalCmdPool,
+			WebhookSender:       p.webhookSender,
 			PathManager:         p.pathManager,
 			Parent:              p,
 		}
@@ -439,6 +521,7 @@ func (p *Core) createResources(initial bool) error {
 			ReadTimeout:         p.conf.ReadTimeout,
 			WriteTimeout:        p.conf.WriteTimeout,
 			WriteQueueSize:      p.conf.WriteQueueSize,
+			WriteQueueOverflow:  p.conf.RTMPWriteQueueOverflow,
 			IsTLS:               false,
 			ServerCert:          "",
 			ServerKey:           "",
@@ -447,6 +530,7 @@ func (p *Core) createResources(initial bool) error {
 			RunOnConnectRestart: p.conf.RunOnConnectRestart,
 			RunOnDisconnect:     p.conf.RunOnDisconnect,
 			ExternalCmdPool:     p.externalCmdPool,
+			WebhookSender:       p.webhookSender,
 			PathManager:         p.pathManager,
 			Parent:              p,
 		}
@@ -469,6 +553,7 @@ func (p *Core) createResources(initial bool) error {
 			ReadTimeout:         p.conf.ReadTimeout,
 			WriteTimeout:        p.conf.WriteTimeout,
 			WriteQueueSize:      p.conf.WriteQueueSize,
+			WriteQueueOverflow:  p.conf.RTMPWriteQueueOverflow,
 			IsTLS:               true,
 			ServerCert:          p.conf.RTMPServerCert,
 			ServerKey:           p.conf.RTMPServerKey,
@@ -477,6 +562,7 @@ func (p *Core) createResources(initial bool) error {
 			RunOnConnectRestart: p.conf.RunOnConnectRestart,
 			RunOnDisconnect:     p.conf.RunOnDisconnect,
 			ExternalCmdPool:     p.externalCmdPool,
+			WebhookSender:       p.webhookSender,
 			PathManager:         p.pathManager,
 			Parent:              p,
 		}
@@ -509,6 +595,7 @@ func (p *Core) createResources(initial bool) error {
 			Directory:                 p.conf.HLSDirectory,
 			ReadTimeout:               p.conf.ReadTimeout,
 			WriteQueueSize:            p.conf.WriteQueueSize,
+			WriteQueueOverflow:        p.conf.HLSWriteQueueOverflow,
 			PathManager:               p.pathManager,
 			Parent:                    p,
 		}
@@ -535,6 +622,7 @@ func (p *Core) createResources(initial bool) error {
 			TrustedProxies:        p.conf.WebRTCTrustedProxies,
 			ReadTimeout:           p.conf.ReadTimeout,
 			WriteQueueSize:        p.conf.WriteQueueSize,
+			WriteQueueOverflow:    p.conf.WebRTCWriteQueueOverflow,
 			LocalUDPAddress:       p.conf.WebRTCLocalUDPAddress,
 			LocalTCPAddress:       p.conf.WebRTCLocalTCPAddress,
 			IPsFromInterfaces:     p.conf.WebRTCIPsFromInterfaces,
@@ -542,6 +630,7 @@ func (p *Core) createResources(initial bool) error {
 			AdditionalHosts:       p.conf.WebRTCAdditionalHosts,
 			ICEServers:            p.conf.WebRTCICEServers2,
 			ExternalCmdPool:       p.externalCmdPool,
+			WebhookSender:         p.webhookSender,
 			PathManager:           p.pathManager,
 			Parent:                p,
 		}
@@ -564,11 +653,13 @@ func (p *Core) createResources(initial bool) error {
 			ReadTimeout:         p.conf.ReadTimeout,
 			WriteTimeout:        p.conf.WriteTimeout,
 			WriteQueueSize:      p.conf.WriteQueueSize,
+			WriteQueueOverflow:  p.conf.SRTWriteQueueOverflow,
 			UDPMaxPayloadSize:   p.conf.UDPMaxPayloadSize,
 			RunOnConnect:        p.conf.RunOnConnect,
 			RunOnConnectRestart: p.conf.RunOnConnectRestart,
 			RunOnDisconnect:     p.conf.RunOnDisconnect,
 			ExternalCmdPool:     p.externalCmdPool,
+			WebhookSender:       p.webhookSender,
 			PathManager:         p.pathManager,
 			Parent:              p,
 		}
@@ -585,18 +676,19 @@ func (p *Core) createResources(initial bool) error {
 	if p.conf.API &&
 		p.api == nil {
 		p.api = &api.API{
-			Address:      p.conf.APIAddress,
-			ReadTimeout:  p.conf.ReadTimeout,
-			Conf:         p.conf,
-			PathManager:  p.pathManager,
-			RTSPServer:   p.rtspServer,
-			RTSPSServer:  p.rtspsServer,
-			RTMPServer:   p.rtmpServer,
-			RTMPSServer:  p.rtmpsServer,
-			HLSServer:    p.hlsServer,
-			WebRTCServer: p.webRTCServer,
-			SRTServer:    p.srtServer,
-			Parent:       p,
+			Address:         p.conf.APIAddress,
+			ReadTimeout:     p.conf.ReadTimeout,
+			Conf:            p.conf,
+			PathManager:     p.pathManager,
+			RTSPServer:      p.rtspServer,
+			RTSPSServer:     p.rtspsServer,
+			RTMPServer:      p.rtmpServer,
+			RTMPSServer:     p.rtmpsServer,
+			HLSServer:       p.hlsServer,
+			WebRTCServer:    p.webRTCServer,
+			SRTServer:       p.srtServer,
+			ExternalCmdPool: p.externalCmdPool,
+			Parent:          p,
 		}
 		err := p.api.Initialize()
 		if err != nil {
@@ -611,15 +703,54 @@ func (p *Core) createResources(initial bool) error {
 		}
 	}
 
+	if p.confWatcher != nil {
+		err = p.confWatcher.WatchDirectory(conf.PathsDirectoryPath(p.confPath, p.conf.PathsDirectory))
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
+func (p *Core) onRecordSegmentDelete(
+	rtspAddress string,
+	pathConfs map[string]*conf.Path,
+) func(string, string) {
+	return func(pathName string, segmentPath string) {
+		if pathName == "" || pathConfs == nil {
+			return
+		}
+
+		_, pathConf, pathMatches, err := conf.FindPathConf(pathConfs, pathName)
+		if err != nil {
+			return
+		}
+
+		hooks.OnRecordSegmentDelete(hooks.OnRecordSegmentParams{
+			Logger:          p,
+			ExternalCmdPool: p.externalCmdPool,
+			WebhookSender:   p.webhookSender,
+			Conf:            pathConf,
+			ExternalCmdEnv:  pathExternalCmdEnv(rtspAddress, pathName, pathMatches),
+			SegmentPath:     segmentPath,
+		})
+	}
+}
+
 func (p *Core) closeResources(newConf *conf.Conf, calledByAPI bool) {
 	closeLogger := newConf == nil ||
 		newConf.LogLevel != p.conf.LogLevel ||
 		!reflect.DeepEqual(newConf.LogDestinations, p.conf.LogDestinations) ||
 		newConf.LogFile != p.conf.LogFile
 
+	closeWebhookSender := newConf == nil ||
+		newConf.WebhookSecret != p.conf.WebhookSecret ||
+		newConf.WebhookTimeout != p.conf.WebhookTimeout ||
+		newConf.WebhookMaxRetries != p.conf.WebhookMaxRetries ||
+		newConf.WebhookQueueSize != p.conf.WebhookQueueSize ||
+		closeLogger
+
 	closeMetrics := newConf == nil ||
 		newConf.Metrics != p.conf.Metrics ||
 		newConf.MetricsAddress != p.conf.MetricsAddress ||
@@ -634,6 +765,9 @@ func (p *Core) closeResources(newConf *conf.Conf, calledByAPI bool) {
 
 	closeRecorderCleaner := newConf == nil ||
 		!reflect.DeepEqual(gatherCleanerEntries(newConf.Paths), gatherCleanerEntries(p.conf.Paths)) ||
+		!reflect.DeepEqual(gatherCleanerPathConfs(newConf.Paths), gatherCleanerPathConfs(p.conf.Paths)) ||
+		newConf.RTSPAddress != p.conf.RTSPAddress ||
+		closeWebhookSender ||
 		closeLogger
 
 	closePlaybackServer := newConf == nil ||
@@ -654,7 +788,9 @@ func (p *Core) closeResources(newConf *conf.Conf, calledByAPI bool) {
 		newConf.WriteTimeout != p.conf.WriteTimeout ||
 		newConf.WriteQueueSize != p.conf.WriteQueueSize ||
 		newConf.UDPMaxPayloadSize != p.conf.UDPMaxPayloadSize ||
+		newConf.RunOnAuthFailure != p.conf.RunOnAuthFailure ||
 		closeMetrics ||
+		closeWebhookSender ||
 		closeLogger
 	if !closePathManager && !reflect.DeepEqual(newConf.Paths, p.conf.Paths) {
 		p.pathManager.ReloadPathConfs(newConf.Paths)
@@ -681,6 +817,7 @@ func (p *Core) closeResources(newConf *conf.Conf, calledByAPI bool) {
 		newConf.RunOnDisconnect != p.conf.RunOnDisconnect ||
 		closeMetrics ||
 		closePathManager ||
+		closeWebhookSender ||
 		closeLogger
 
 	closeRTSPSServer := newConf == nil ||
@@ -700,6 +837,7 @@ func (p *Core) closeResources(newConf *conf.Conf, calledByAPI bool) {
 		newConf.RunOnDisconnect != p.conf.RunOnDisconnect ||
 		closeMetrics ||
 		closePathManager ||
+		closeWebhookSender ||
 		closeLogger
 
 	closeRTMPServer := newConf == nil ||
@@ -709,12 +847,14 @@ func (p *Core) closeResources(newConf *conf.Conf, calledByAPI bool) {
 		newConf.ReadTimeout != p.conf.ReadTimeout ||
 		newConf.WriteTimeout != p.conf.WriteTimeout ||
 		newConf.WriteQueueSize != p.conf.WriteQueueSize ||
+		newConf.RTMPWriteQueueOverflow != p.conf.RTMPWriteQueueOverflow ||
 		newConf.RTSPAddress != p.conf.RTSPAddress ||
 		newConf.RunOnConnect != p.conf.RunOnConnect ||
 		newConf.RunOnConnectRestart != p.conf.RunOnConnectRestart ||
 		newConf.RunOnDisconnect != p.conf.RunOnDisconnect ||
 		closeMetrics ||
 		closePathManager ||
+		closeWebhookSender ||
 		closeLogger
 
 	closeRTMPSServer := newConf == nil ||
@@ -724,6 +864,7 @@ func (p *Core) closeResources(newConf *conf.Conf, calledByAPI bool) {
 		newConf.ReadTimeout != p.conf.ReadTimeout ||
 		newConf.WriteTimeout != p.conf.WriteTimeout ||
 		newConf.WriteQueueSize != p.conf.WriteQueueSize ||
+		newConf.RTMPWriteQueueOverflow != p.conf.RTMPWriteQueueOverflow ||
 		newConf.RTMPServerCert != p.conf.RTMPServerCert ||
 		newConf.RTMPServerKey != p.conf.RTMPServerKey ||
 		newConf.RTSPAddress != p.conf.RTSPAddress ||
@@ -732,6 +873,7 @@ func (p *Core) closeResources(newConf *conf.Conf, calledByAPI bool) {
 		newConf.RunOnDisconnect != p.conf.RunOnDisconnect ||
 		closeMetrics ||
 		closePathManager ||
+		closeWebhookSender ||
 		closeLogger
 
 	closeHLSServer := newConf == nil ||
@@ -752,6 +894,7 @@ func (p *Core) closeResources(newConf *conf.Conf, calledByAPI bool) {
 		newConf.HLSDirectory != p.conf.HLSDirectory ||
 		newConf.ReadTimeout != p.conf.ReadTimeout ||
 		newConf.WriteQueueSize != p.conf.WriteQueueSize ||
+		newConf.HLSWriteQueueOverflow != p.conf.HLSWriteQueueOverflow ||
 		closePathManager ||
 		closeMetrics ||
 		closeLogger
@@ -766,6 +909,7 @@ func (p *Core) closeResources(newConf *conf.Conf, calledByAPI bool) {
 		!reflect.DeepEqual(newConf.WebRTCTrustedProxies, p.conf.WebRTCTrustedProxies) ||
 		newConf.ReadTimeout != p.conf.ReadTimeout ||
 		newConf.WriteQueueSize != p.conf.WriteQueueSize ||
+		newConf.WebRTCWriteQueueOverflow != p.conf.WebRTCWriteQueueOverflow ||
 		newConf.WebRTCLocalUDPAddress != p.conf.WebRTCLocalUDPAddress ||
 		newConf.WebRTCLocalTCPAddress != p.conf.WebRTCLocalTCPAddress ||
 		newConf.WebRTCIPsFromInterfaces != p.conf.WebRTCIPsFromInterfaces ||
@@ -774,6 +918,7 @@ func (p *Core) closeResources(newConf *conf.Conf, calledByAPI bool) {
 		!reflect.DeepEqual(newConf.WebRTCICEServers2, p.conf.WebRTCICEServers2) ||
 		closeMetrics ||
 		closePathManager ||
+		closeWebhookSender ||
 		closeLogger
 
 	closeSRTServer := newConf == nil ||
@@ -783,11 +928,13 @@ func (p *Core) closeResources(newConf *conf.Conf, calledByAPI bool) {
 		newConf.ReadTimeout != p.conf.ReadTimeout ||
 		newConf.WriteTimeout != p.conf.WriteTimeout ||
 		newConf.WriteQueueSize != p.conf.WriteQueueSize ||
+		newConf.SRTWriteQueueOverflow != p.conf.SRTWriteQueueOverflow ||
 		newConf.UDPMaxPayloadSize != p.conf.UDPMaxPayloadSize ||
 		newConf.RunOnConnect != p.conf.RunOnConnect ||
 		newConf.RunOnConnectRestart != p.conf.RunOnConnectRestart ||
 		newConf.RunOnDisconnect != p.conf.RunOnDisconnect ||
 		closePathManager ||
+		closeWebhookSender ||
 		closeLogger
 
 	closeAPI := newConf == nil ||
@@ -911,6 +1058,11 @@ func (p *Core) closeResources(newConf *conf.Conf, calledByAPI bool) {
 		p.metrics = nil
 	}
 
+	if closeWebhookSender && p.webhookSender != nil {
+		p.webhookSender.Close()
+		p.webhookSender = nil
+	}
+
 	if newConf == nil && p.externalCmdPool != nil {
 		p.Log(logger.Info, "waiting for running hooks")
 		p.externalCmdPool.Close()
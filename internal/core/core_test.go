@@ -3,12 +3,15 @@ package core
 import (
 	"os"
 	"path/filepath"
+	"regexp"
 	"testing"
 	"time"
 
 	"github.com/bluenviron/gortsplib/v4"
 	"github.com/bluenviron/gortsplib/v4/pkg/description"
 	"github.com/stretchr/testify/require"
+
+	"github.com/bluenviron/mediamtx/internal/conf"
 )
 
 var serverCert = []byte(`-----BEGIN CERTIFICATE-----
@@ -133,3 +136,30 @@ func TestCoreHotReloading(t *testing.T) {
 		defer conn.Close()
 	}()
 }
+
+func TestGatherCleanerPathConfs(t *testing.T) {
+	newPaths := func(source string, runOnDelete string) map[string]*conf.Path {
+		return map[string]*conf.Path{
+			"cam": {
+				Name:   "cam",
+				Source: source,
+			},
+			"~^rec_(.+)$": {
+				Regexp:                   regexp.MustCompile("^rec_(.+)$"),
+				Name:                     "~^rec_(.+)$",
+				RunOnRecordSegmentDelete: runOnDelete,
+			},
+		}
+	}
+
+	require.Nil(t, gatherCleanerPathConfs(newPaths("", "")))
+
+	// fields that are not used by the cleaner are ignored
+	require.Equal(t,
+		gatherCleanerPathConfs(newPaths("", "rm $MTX_SEGMENT_PATH")),
+		gatherCleanerPathConfs(newPaths("rtsp://localhost:8554/cam", "rm $MTX_SEGMENT_PATH")))
+
+	require.NotEqual(t,
+		gatherCleanerPathConfs(newPaths("", "rm $MTX_SEGMENT_PATH")),
+		gatherCleanerPathConfs(newPaths("", "ls $MTX_SEGMENT_PATH")))
+}
@@ -220,3 +220,70 @@ func TestHLSRead(t *testing.T) {
 		Payload: []byte{0x01, 0x02, 0x03, 0x04},
 	}, pkt)*/
 }
+
+func TestHLSReadTrackSelection(t *testing.T) {
+	p, ok := newInstance("paths:\n" +
+		"  all_others:\n")
+	require.Equal(t, true, ok)
+	defer p.Close()
+
+	medi := &description.Media{
+		Type: description.MediaTypeVideo,
+		Formats: []format.Format{&format.H264{
+			PayloadTyp:        96,
+			PacketizationMode: 1,
+			SPS: []byte{ // 1920x1080 baseline
+				0x67, 0x42, 0xc0, 0x28, 0xd9, 0x00, 0x78, 0x02,
+				0x27, 0xe5, 0x84, 0x00, 0x00, 0x03, 0x00, 0x04,
+				0x00, 0x00, 0x03, 0x00, 0xf0, 0x3c, 0x60, 0xc9, 0x20,
+			},
+			PPS: []byte{0x08, 0x06, 0x07, 0x08},
+		}},
+	}
+
+	v := gortsplib.TransportTCP
+	source := gortsplib.Client{
+		Transport: &v,
+	}
+	err := source.StartRecording("rtsp://localhost:8554/stream",
+		&description.Session{Medias: []*description.Media{medi, testMediaAAC}})
+	require.NoError(t, err)
+	defer source.Close()
+
+	hc := &http.Client{Transport: &http.Transport{}}
+
+	go func() {
+		time.Sleep(500 * time.Millisecond)
+
+		for i := 0; i < 2; i++ {
+			source.WritePacketRTP(medi, &rtp.Packet{ //nolint:errcheck
+				Header: rtp.Header{
+					Version:        2,
+					Marker:         true,
+					PayloadType:    96,
+					SequenceNumber: 123 + uint16(i),
+					Timestamp:      45343 + uint32(i*90000),
+					SSRC:           563423,
+				},
+				Payload: []byte{
+					0x05, 0x02, 0x03, 0x04, // IDR
+				},
+			})
+		}
+	}()
+
+	// the audio track is excluded, and the selection is kept in URLs
+	cnt := httpPullFile(t, hc, "http://localhost:8888/stream/index.m3u8?tracks=video")
+	require.Equal(t, "#EXTM3U\n"+
+		"#EXT-X-VERSION:9\n"+
+		"#EXT-X-INDEPENDENT-SEGMENTS\n"+
+		"\n"+
+		"#EXT-X-STREAM-INF:BANDWIDTH=1192,AVERAGE-BANDWIDTH=1192,"+
+		"CODECS=\"avc1.42c028\",RESOLUTION=1920x1080,FRAME-RATE=30.000\n"+
+		"stream.m3u8?tracks=video\n", string(cnt))
+
+	res, err := hc.Get("http://localhost:8888/stream/index.m3u8?tracks=subtitles")
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusBadRequest, res.StatusCode)
+}
@@ -230,21 +230,33 @@ webrtc_sessions_bytes_sent 0
 			`^paths\{name=".*?",state="ready"\} 1`+"\n"+
 				`paths_bytes_received\{name=".*?",state="ready"\} [0-9]+`+"\n"+
 				`paths_bytes_sent\{name=".*?",state="ready"\} [0-9]+`+"\n"+
+				`paths_timeshift_buffer_bytes\{name=".*?",state="ready"\} 0`+"\n"+
+				`(paths_track_[a-z0-9_]+\{name=".*?",state="ready",track="[0-9]+",codec=".*?"\} [0-9.]+`+"\n"+`)+`+
 				`paths\{name=".*?",state="ready"\} 1`+"\n"+
 				`paths_bytes_received\{name=".*?",state="ready"\} [0-9]+`+"\n"+
 				`paths_bytes_sent\{name=".*?",state="ready"\} [0-9]+`+"\n"+
+				`paths_timeshift_buffer_bytes\{name=".*?",state="ready"\} 0`+"\n"+
+				`(paths_track_[a-z0-9_]+\{name=".*?",state="ready",track="[0-9]+",codec=".*?"\} [0-9.]+`+"\n"+`)+`+
 				`paths\{name=".*?",state="ready"\} 1`+"\n"+
 				`paths_bytes_received\{name=".*?",state="ready"\} [0-9]+`+"\n"+
 				`paths_bytes_sent\{name=".*?",state="ready"\} [0-9]+`+"\n"+
+				`paths_timeshift_buffer_bytes\{name=".*?",state="ready"\} 0`+"\n"+
+				`(paths_track_[a-z0-9_]+\{name=".*?",state="ready",track="[0-9]+",codec=".*?"\} [0-9.]+`+"\n"+`)+`+
 				`paths\{name=".*?",state="ready"\} 1`+"\n"+
 				`paths_bytes_received\{name=".*?",state="ready"\} [0-9]+`+"\n"+
 				`paths_bytes_sent\{name=".*?",state="ready"\} [0-9]+`+"\n"+
+				`paths_timeshift_buffer_bytes\{name=".*?",state="ready"\} 0`+"\n"+
+				`(paths_track_[a-z0-9_]+\{name=".*?",state="ready",track="[0-9]+",codec=".*?"\} [0-9.]+`+"\n"+`)+`+
 				`paths\{name=".*?",state="ready"\} 1`+"\n"+
 				`paths_bytes_received\{name=".*?",state="ready"\} [0-9]+`+"\n"+
 				`paths_bytes_sent\{name=".*?",state="ready"\} [0-9]+`+"\n"+
+				`paths_timeshift_buffer_bytes\{name=".*?",state="ready"\} 0`+"\n"+
+				`(paths_track_[a-z0-9_]+\{name=".*?",state="ready",track="[0-9]+",codec=".*?"\} [0-9.]+`+"\n"+`)+`+
 				`paths\{name=".*?",state="ready"\} 1`+"\n"+
 				`paths_bytes_received\{name=".*?",state="ready"\} [0-9]+`+"\n"+
 				`paths_bytes_sent\{name=".*?",state="ready"\} [0-9]+`+"\n"+
+				`paths_timeshift_buffer_bytes\{name=".*?",state="ready"\} 0`+"\n"+
+				`(paths_track_[a-z0-9_]+\{name=".*?",state="ready",track="[0-9]+",codec=".*?"\} [0-9.]+`+"\n"+`)+`+
 				`hls_muxers\{name=".*?"\} 1`+"\n"+
 				`hls_muxers_bytes_sent\{name=".*?"\} 0`+"\n"+
 				`hls_muxers\{name=".*?"\} 1`+"\n"+
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"reflect"
 	"strconv"
 	"strings"
 	"sync"
@@ -17,10 +18,49 @@ import (
 	"github.com/bluenviron/mediamtx/internal/externalcmd"
 	"github.com/bluenviron/mediamtx/internal/hooks"
 	"github.com/bluenviron/mediamtx/internal/logger"
+	"github.com/bluenviron/mediamtx/internal/offlinesource"
+	"github.com/bluenviron/mediamtx/internal/push"
 	"github.com/bluenviron/mediamtx/internal/record"
 	"github.com/bluenviron/mediamtx/internal/stream"
+	"github.com/bluenviron/mediamtx/internal/webhook"
 )
 
+// noDataCheckPeriod returns the period of the check performed by the stall detector.
+func noDataCheckPeriod(timeout time.Duration) time.Duration {
+	if timeout < time.Second {
+		return timeout
+	}
+	return time.Second
+}
+
+const (
+	// period of the check of track statistics.
+	statsCheckPeriod = 1 * time.Second
+
+	// track statistics are computed over a 10 seconds window,
+	// therefore they are not checked until the window is filled.
+	statsCheckWarmup = 10 * time.Second
+
+	// once a threshold is exceeded, the statistic must be back within it by this fraction
+	// of the threshold, in order not to launch hooks repeatedly when a value oscillates.
+	statsHysteresis = 0.1
+)
+
+// statExceeded returns whether a statistic exceeds its threshold.
+func statExceeded(value float64, threshold float64, isMin bool, wasExceeded bool) bool {
+	if isMin {
+		if wasExceeded {
+			threshold *= 1 + statsHysteresis
+		}
+		return value < threshold
+	}
+
+	if wasExceeded {
+		threshold *= 1 - statsHysteresis
+	}
+	return value > threshold
+}
+
 func newEmptyTimer() *time.Timer {
 	t := time.NewTimer(0)
 	<-t.C
@@ -77,6 +117,8 @@ type path struct {
 	matches           []string
 	wg                *sync.WaitGroup
 	externalCmdPool   *externalcmd.Pool
+	webhookSender     *webhook.Sender
+	pathManager       defs.PathManager
 	parent            pathParent
 
 	ctx                            context.Context
@@ -86,6 +128,7 @@ type path struct {
 	publisherQuery                 string
 	stream                         *stream.Stream
 	recordAgent                    *record.Agent
+	pushTargets                    []*push.Target
 	readyTime                      time.Time
 	onUnDemandHook                 func(string)
 	onNotReadyHook                 func()
@@ -98,6 +141,14 @@ type path struct {
 	onDemandPublisherState         pathOnDemandState
 	onDemandPublisherReadyTimer    *time.Timer
 	onDemandPublisherCloseTimer    *time.Timer
+	publisherGraceTimer            *time.Timer
+	noDataTicker                   *time.Ticker
+	noDataStalled                  bool
+	statsTicker                    *time.Ticker
+	statsExceeded                  map[string]bool
+	silenceStates                  map[int]*pathSilenceState
+	offlineSource                  *offlinesource.Source
+	streamGenerateRTPPackets       bool
 
 	// in
 	chReloadConf              chan *conf.Path
@@ -126,6 +177,15 @@ func (pa *path) initialize() {
 	pa.onDemandStaticSourceCloseTimer = newEmptyTimer()
 	pa.onDemandPublisherReadyTimer = newEmptyTimer()
 	pa.onDemandPublisherCloseTimer = newEmptyTimer()
+	pa.publisherGraceTimer = newEmptyTimer()
+	pa.noDataTicker = &time.Ticker{}
+	if pa.conf.NoDataTimeout != 0 {
+		pa.noDataTicker = time.NewTicker(noDataCheckPeriod(time.Duration(pa.conf.NoDataTimeout)))
+	}
+	pa.statsTicker = &time.Ticker{}
+	if pa.statsThresholdsEnabled() || pa.silenceHooksEnabled() {
+		pa.statsTicker = time.NewTicker(statsCheckPeriod)
+	}
 	pa.chReloadConf = make(chan *conf.Path)
 	pa.chStaticSourceSetReady = make(chan defs.PathSourceStaticSetReadyReq)
 	pa.chStaticSourceSetNotReady = make(chan defs.PathSourceStaticSetNotReadyReq)
@@ -169,21 +229,24 @@ func (pa *path) run() {
 	if pa.conf.Source == "redirect" {
 		pa.source = &sourceRedirect{}
 	} else if pa.conf.HasStaticSource() {
-		resolvedSource := pa.conf.Source
+		resolvedSources := append([]string{pa.conf.Source}, pa.conf.SourceBackups...)
 		if len(pa.matches) > 1 {
-			for i, ma := range pa.matches[1:] {
-				resolvedSource = strings.ReplaceAll(resolvedSource, "$G"+strconv.FormatInt(int64(i+1), 10), ma)
+			for j := range resolvedSources {
+				for i, ma := range pa.matches[1:] {
+					resolvedSources[j] = strings.ReplaceAll(resolvedSources[j], "$G"+strconv.FormatInt(int64(i+1), 10), ma)
+				}
 			}
 		}
 
 		pa.source = &staticSourceHandler{
-			conf:           pa.conf,
-			logLevel:       pa.logLevel,
-			readTimeout:    pa.readTimeout,
-			writeTimeout:   pa.writeTimeout,
-			writeQueueSize: pa.writeQueueSize,
-			resolvedSource: resolvedSource,
-			parent:         pa,
+			conf:            pa.conf,
+			logLevel:        pa.logLevel,
+			readTimeout:     pa.readTimeout,
+			writeTimeout:    pa.writeTimeout,
+			writeQueueSize:  pa.writeQueueSize,
+			resolvedSources: resolvedSources,
+			pathManager:     pa.pathManager,
+			parent:          pa,
 		}
 		pa.source.(*staticSourceHandler).initialize()
 
@@ -192,9 +255,22 @@ func (pa *path) run() {
 		}
 	}
 
+	if pa.conf.OfflineSource != "" {
+		pa.startOfflineSource()
+	}
+
 	onUnInitHook := hooks.OnInit(hooks.OnInitParams{
 		Logger:          pa,
 		ExternalCmdPool: pa.externalCmdPool,
+		WebhookSender:   pa.webhookSender,
+		Conf:            pa.conf,
+		ExternalCmdEnv:  pa.ExternalCmdEnv(),
+	})
+
+	onPathDestroyHook := hooks.OnPathCreate(hooks.OnPathCreateParams{
+		Logger:          pa,
+		ExternalCmdPool: pa.externalCmdPool,
+		WebhookSender:   pa.webhookSender,
 		Conf:            pa.conf,
 		ExternalCmdEnv:  pa.ExternalCmdEnv(),
 	})
@@ -210,8 +286,11 @@ func (pa *path) run() {
 	pa.onDemandStaticSourceCloseTimer.Stop()
 	pa.onDemandPublisherReadyTimer.Stop()
 	pa.onDemandPublisherCloseTimer.Stop()
+	pa.noDataTicker.Stop()
+	pa.statsTicker.Stop()
 
 	onUnInitHook()
+	onPathDestroyHook()
 
 	for _, req := range pa.describeRequestsOnHold {
 		req.Res <- defs.PathDescribeRes{Err: fmt.Errorf("terminated")}
@@ -269,6 +348,24 @@ func (pa *path) runInner() error {
 		case <-pa.onDemandPublisherCloseTimer.C:
 			pa.doOnDemandPublisherCloseTimer()
 
+		case <-pa.publisherGraceTimer.C:
+			pa.doPublisherGraceTimer()
+
+			if pa.shouldClose() {
+				return fmt.Errorf("not in use")
+			}
+
+		case <-pa.noDataTicker.C:
+			pa.doNoDataCheck()
+
+			if pa.shouldClose() {
+				return fmt.Errorf("not in use")
+			}
+
+		case <-pa.statsTicker.C:
+			pa.doStatsCheck()
+			pa.doSilenceCheck()
+
 		case newConf := <-pa.chReloadConf:
 			pa.doReloadConf(newConf)
 
@@ -366,6 +463,8 @@ func (pa *path) doOnDemandPublisherCloseTimer() {
 }
 
 func (pa *path) doReloadConf(newConf *conf.Path) {
+	pushChanged := !reflect.DeepEqual(pa.conf.Push, newConf.Push)
+
 	pa.confMutex.Lock()
 	pa.conf = newConf
 	pa.confMutex.Unlock()
@@ -382,6 +481,11 @@ func (pa *path) doReloadConf(newConf *conf.Path) {
 		pa.recordAgent.Close()
 		pa.recordAgent = nil
 	}
+
+	if pushChanged && pa.stream != nil {
+		pa.stopPushing()
+		pa.startPushing()
+	}
 }
 
 func (pa *path) doSourceStaticSetReady(req defs.PathSourceStaticSetReadyReq) {
@@ -465,9 +569,311 @@ func (pa *path) doDescribe(req defs.PathDescribeReq) {
 	req.Res <- defs.PathDescribeRes{Err: defs.PathNoOnePublishingError{PathName: pa.name}}
 }
 
+func (pa *path) doPublisherGraceTimer() {
+	pa.publisherGraceTimer = newEmptyTimer()
+
+	pa.Log(logger.Info, "publisher did not reconnect within %v",
+		time.Duration(pa.conf.PublisherReconnectGrace))
+
+	if pa.stream != nil {
+		pa.setNotReady()
+	}
+}
+
+func (pa *path) doNoDataCheck() {
+	if pa.stream == nil || pa.source == nil || pa.offlineSource != nil {
+		pa.noDataStalled = false
+		return
+	}
+
+	medias := pa.stream.StalledMedias(time.Duration(pa.conf.NoDataTimeout))
+
+	if len(medias) == 0 {
+		if pa.noDataStalled {
+			pa.noDataStalled = false
+			pa.Log(logger.Info, "data is flowing again")
+		}
+		return
+	}
+
+	// report the stall once
+	if pa.noDataStalled {
+		return
+	}
+	pa.noDataStalled = true
+
+	pa.Log(logger.Warn, "no data received in the last %v from %s",
+		time.Duration(pa.conf.NoDataTimeout), defs.MediasInfo(medias))
+
+	hooks.OnNoData(hooks.OnNoDataParams{
+		Logger:          pa,
+		ExternalCmdPool: pa.externalCmdPool,
+		WebhookSender:   pa.webhookSender,
+		Conf:            pa.conf,
+		ExternalCmdEnv:  pa.ExternalCmdEnv(),
+		Desc:            pa.source.APISourceDescribe(),
+		Tracks:          strings.Join(defs.MediasToCodecs(medias), ","),
+	})
+
+	if !pa.conf.NoDataTimeoutClose {
+		return
+	}
+
+	switch source := pa.source.(type) {
+	case *staticSourceHandler:
+		source.restart()
+
+	case defs.Publisher:
+		pa.Log(logger.Info, "closing stalled publisher")
+		source.Close()
+		pa.removePublisher()
+	}
+}
+
+func (pa *path) statsThresholdsEnabled() bool {
+	return pa.conf.StatsMinBitrate != 0 ||
+		pa.conf.StatsMaxBitrate != 0 ||
+		pa.conf.StatsMinFPS != 0 ||
+		pa.conf.StatsMaxGOPDuration != 0
+}
+
+// trackStats returns statistics of tracks of the stream.
+func (pa *path) trackStats() []defs.APIPathTrackStats {
+	codecs := defs.MediasToCodecs(pa.stream.Desc().Medias)
+	ret := []defs.APIPathTrackStats{}
+
+	for i, st := range pa.stream.TrackStats() {
+		ret = append(ret, defs.APIPathTrackStats{
+			Codec:        codecs[i],
+			Bitrate1s:    st.Bitrate1s,
+			Bitrate10s:   st.Bitrate10s,
+			FPS:          st.FPS,
+			GOPLength:    st.GOPLength,
+			GOPDuration:  st.GOPDuration.Seconds(),
+			MaxFrameSize: st.MaxFrameSize,
+			DecodeErrors: st.DecodeErrors,
+			RMSLevel: func() *float64 {
+				if st.AudioLevel == nil {
+					return nil
+				}
+				return &st.AudioLevel.RMS
+			}(),
+			PeakLevel: func() *float64 {
+				if st.AudioLevel == nil {
+					return nil
+				}
+				return &st.AudioLevel.Peak
+			}(),
+		})
+	}
+
+	return ret
+}
+
+func (pa *path) doStatsCheck() {
+	if !pa.statsThresholdsEnabled() {
+		return
+	}
+
+	if pa.stream == nil || pa.offlineSource != nil {
+		pa.statsExceeded = nil
+		return
+	}
+
+	if time.Since(pa.readyTime) < statsCheckWarmup {
+		return
+	}
+
+	if pa.statsExceeded == nil {
+		pa.statsExceeded = make(map[string]bool)
+	}
+
+	var isVideo []bool
+	for _, medi := range pa.stream.Desc().Medias {
+		for range medi.Formats {
+			isVideo = append(isVideo, medi.Type == description.MediaTypeVideo)
+		}
+	}
+
+	for i, st := range pa.trackStats() {
+		type check struct {
+			stat      string
+			enabled   bool
+			isMin     bool
+			value     float64
+			threshold float64
+			format    func(float64) string
+		}
+
+		formatBitrate := func(v float64) string { return strconv.FormatUint(uint64(v), 10) }
+		formatFPS := func(v float64) string { return strconv.FormatFloat(v, 'f', 2, 64) }
+		formatDuration := func(v float64) string { return strconv.FormatFloat(v, 'f', 3, 64) }
+
+		checks := []check{
+			{
+				stat:      "minBitrate",
+				enabled:   pa.conf.StatsMinBitrate != 0,
+				isMin:     true,
+				value:     float64(st.Bitrate10s),
+				threshold: float64(pa.conf.StatsMinBitrate),
+				format:    formatBitrate,
+			},
+			{
+				stat:      "maxBitrate",
+				enabled:   pa.conf.StatsMaxBitrate != 0,
+				value:     float64(st.Bitrate10s),
+				threshold: float64(pa.conf.StatsMaxBitrate),
+				format:    formatBitrate,
+			},
+			{
+				stat:      "minFPS",
+				enabled:   isVideo[i] && pa.conf.StatsMinFPS != 0,
+				isMin:     true,
+				value:     st.FPS,
+				threshold: pa.conf.StatsMinFPS,
+				format:    formatFPS,
+			},
+			{
+				stat:      "maxGOPDuration",
+				enabled:   isVideo[i] && st.GOPLength != 0 && pa.conf.StatsMaxGOPDuration != 0,
+				value:     st.GOPDuration,
+				threshold: time.Duration(pa.conf.StatsMaxGOPDuration).Seconds(),
+				format:    formatDuration,
+			},
+		}
+
+		for _, c := range checks {
+			if !c.enabled {
+				continue
+			}
+
+			key := strconv.FormatInt(int64(i), 10) + "/" + c.stat
+			wasExceeded := pa.statsExceeded[key]
+			exceeded := statExceeded(c.value, c.threshold, c.isMin, wasExceeded)
+			if exceeded == wasExceeded {
+				continue
+			}
+			pa.statsExceeded[key] = exceeded
+
+			value := c.format(c.value)
+			threshold := c.format(c.threshold)
+
+			if exceeded {
+				pa.Log(logger.Warn, "track %d (%s): %s threshold exceeded (value %s, threshold %s)",
+					i+1, st.Codec, c.stat, value, threshold)
+			} else {
+				pa.Log(logger.Info, "track %d (%s): %s is within threshold again (value %s, threshold %s)",
+					i+1, st.Codec, c.stat, value, threshold)
+			}
+
+			hooks.OnStatsThreshold(hooks.OnStatsThresholdParams{
+				Logger:          pa,
+				ExternalCmdPool: pa.externalCmdPool,
+				WebhookSender:   pa.webhookSender,
+				Conf:            pa.conf,
+				ExternalCmdEnv:  pa.ExternalCmdEnv(),
+				Track:           i + 1,
+				Codec:           st.Codec,
+				Stat:            c.stat,
+				Value:           value,
+				Threshold:       threshold,
+				Exceeded:        exceeded,
+			})
+		}
+	}
+}
+
+func (pa *path) silenceHooksEnabled() bool {
+	return pa.conf.RunOnSilence != "" || pa.conf.RunOnSound != ""
+}
+
+type pathSilenceState struct {
+	belowSince time.Time
+	silent     bool
+}
+
+// update updates the state with the current level of the track.
+// It returns true when the track becomes silent or not silent anymore.
+func (s *pathSilenceState) update(now time.Time, level float64, threshold float64, duration time.Duration) bool {
+	if level < threshold {
+		if s.belowSince.IsZero() {
+			s.belowSince = now
+		}
+
+		if s.silent || now.Sub(s.belowSince) < duration {
+			return false
+		}
+
+		s.silent = true
+		return true
+	}
+
+	s.belowSince = time.Time{}
+
+	if !s.silent {
+		return false
+	}
+
+	s.silent = false
+	return true
+}
+
+func (pa *path) doSilenceCheck() {
+	if !pa.silenceHooksEnabled() {
+		return
+	}
+
+	if pa.stream == nil || pa.offlineSource != nil {
+		pa.silenceStates = nil
+		return
+	}
+
+	if pa.silenceStates == nil {
+		pa.silenceStates = make(map[int]*pathSilenceState)
+	}
+
+	now := time.Now()
+
+	for i, st := range pa.trackStats() {
+		if st.RMSLevel == nil {
+			continue
+		}
+
+		state, ok := pa.silenceStates[i]
+		if !ok {
+			state = &pathSilenceState{}
+			pa.silenceStates[i] = state
+		}
+
+		if !state.update(now, *st.RMSLevel, pa.conf.SilenceThreshold, time.Duration(pa.conf.SilenceDuration)) {
+			continue
+		}
+
+		if state.silent {
+			pa.Log(logger.Warn, "track %d (%s) is silent since %v (level %.1f dBFS)",
+				i+1, st.Codec, time.Duration(pa.conf.SilenceDuration), *st.RMSLevel)
+		} else {
+			pa.Log(logger.Info, "track %d (%s) is not silent anymore (level %.1f dBFS)",
+				i+1, st.Codec, *st.RMSLevel)
+		}
+
+		hooks.OnSilence(hooks.OnSilenceParams{
+			Logger:          pa,
+			ExternalCmdPool: pa.externalCmdPool,
+			WebhookSender:   pa.webhookSender,
+			Conf:            pa.conf,
+			ExternalCmdEnv:  pa.ExternalCmdEnv(),
+			Track:           i + 1,
+			Codec:           st.Codec,
+			Level:           *st.RMSLevel,
+			Silent:          state.silent,
+		})
+	}
+}
+
 func (pa *path) doRemovePublisher(req defs.PathRemovePublisherReq) {
 	if pa.source == req.Author {
-		pa.executeRemovePublisher()
+		pa.removePublisher()
 	}
 	close(req.Res)
 }
@@ -487,8 +893,20 @@ func (pa *path) doAddPublisher(req defs.PathAddPublisherReq) {
 		}
 
 		pa.Log(logger.Info, "closing existing publisher")
+		previousDesc := pa.source.APISourceDescribe()
 		pa.source.(defs.Publisher).Close()
 		pa.executeRemovePublisher()
+
+		hooks.OnPublisherChange(hooks.OnPublisherChangeParams{
+			Logger:          pa,
+			ExternalCmdPool: pa.externalCmdPool,
+			WebhookSender:   pa.webhookSender,
+			Conf:            pa.conf,
+			ExternalCmdEnv:  pa.ExternalCmdEnv(),
+			PreviousDesc:    previousDesc,
+			Desc:            req.Author.APISourceDescribe(),
+			Query:           req.AccessRequest.Query,
+		})
 	}
 
 	pa.source = req.Author
@@ -503,6 +921,27 @@ func (pa *path) doStartPublisher(req defs.PathStartPublisherReq) {
 		return
 	}
 
+	// a publisher is reconnecting during the grace period
+	if pa.stream != nil && pa.offlineSource == nil {
+		pa.publisherGraceTimer.Stop()
+		pa.publisherGraceTimer = newEmptyTimer()
+
+		err := pa.reattachStream(req.Desc, req.GenerateRTPPackets)
+		if err == nil {
+			req.Author.Log(logger.Info, "is publishing to path '%s' again, %s",
+				pa.name,
+				defs.MediasInfo(req.Desc.Medias))
+
+			req.Res <- defs.PathStartPublisherRes{Stream: pa.stream}
+			return
+		}
+
+		pa.Log(logger.Info, "readers must be reconnected since tracks are different: %v", err)
+
+		pa.stopPublisherResources()
+		pa.closeStream()
+	}
+
 	err := pa.setReady(req.Desc, req.GenerateRTPPackets)
 	if err != nil {
 		req.Res <- defs.PathStartPublisherRes{Err: err}
@@ -587,6 +1026,14 @@ func (pa *path) doAPIPathsGet(req pathAPIPathsGetReq) {
 				v := pa.source.APISourceDescribe()
 				return &v
 			}(),
+			ActiveSource: func() *string {
+				sh, ok := pa.source.(*staticSourceHandler)
+				if !ok {
+					return nil
+				}
+				v := sh.activeSource()
+				return &v
+			}(),
 			Ready: pa.stream != nil,
 			ReadyTime: func() *time.Time {
 				if pa.stream == nil {
@@ -601,6 +1048,12 @@ func (pa *path) doAPIPathsGet(req pathAPIPathsGetReq) {
 				}
 				return defs.MediasToCodecs(pa.stream.Desc().Medias)
 			}(),
+			TrackStats: func() []defs.APIPathTrackStats {
+				if pa.stream == nil {
+					return []defs.APIPathTrackStats{}
+				}
+				return pa.trackStats()
+			}(),
 			BytesReceived: func() uint64 {
 				if pa.stream == nil {
 					return 0
@@ -613,6 +1066,12 @@ func (pa *path) doAPIPathsGet(req pathAPIPathsGetReq) {
 				}
 				return pa.stream.BytesSent()
 			}(),
+			TimeshiftBufferBytes: func() uint64 {
+				if pa.stream == nil {
+					return 0
+				}
+				return pa.stream.TimeshiftBufferMemoryUsage()
+			}(),
 			Readers: func() []defs.APIPathSourceOrReader {
 				ret := []defs.APIPathSourceOrReader{}
 				for r := range pa.readers {
@@ -620,6 +1079,13 @@ func (pa *path) doAPIPathsGet(req pathAPIPathsGetReq) {
 				}
 				return ret
 			}(),
+			Push: func() []defs.APIPathPushTarget {
+				ret := []defs.APIPathPushTarget{}
+				for _, t := range pa.pushTargets {
+					ret = append(ret, t.APIItem())
+				}
+				return ret
+			}(),
 		},
 	}
 }
@@ -630,16 +1096,16 @@ func (pa *path) SafeConf() *conf.Path {
 	return pa.conf
 }
 
-func (pa *path) ExternalCmdEnv() externalcmd.Environment {
-	_, port, _ := net.SplitHostPort(pa.rtspAddress)
+func pathExternalCmdEnv(rtspAddress string, name string, matches []string) externalcmd.Environment {
+	_, port, _ := net.SplitHostPort(rtspAddress)
 	env := externalcmd.Environment{
-		"MTX_PATH":  pa.name,
-		"RTSP_PATH": pa.name, // deprecated
+		"MTX_PATH":  name,
+		"RTSP_PATH": name, // deprecated
 		"RTSP_PORT": port,
 	}
 
-	if len(pa.matches) > 1 {
-		for i, ma := range pa.matches[1:] {
+	if len(matches) > 1 {
+		for i, ma := range matches[1:] {
 			env["G"+strconv.FormatInt(int64(i+1), 10)] = ma
 		}
 	}
@@ -647,6 +1113,10 @@ func (pa *path) ExternalCmdEnv() externalcmd.Environment {
 	return env
 }
 
+func (pa *path) ExternalCmdEnv() externalcmd.Environment {
+	return pathExternalCmdEnv(pa.rtspAddress, pa.name, pa.matches)
+}
+
 func (pa *path) shouldClose() bool {
 	return pa.conf.Regexp != nil &&
 		pa.source == nil &&
@@ -686,6 +1156,7 @@ func (pa *path) onDemandPublisherStart(query string) {
 	pa.onUnDemandHook = hooks.OnDemand(hooks.OnDemandParams{
 		Logger:          pa,
 		ExternalCmdPool: pa.externalCmdPool,
+		WebhookSender:   pa.webhookSender,
 		Conf:            pa.conf,
 		ExternalCmdEnv:  pa.ExternalCmdEnv(),
 		Query:           query,
@@ -717,15 +1188,22 @@ func (pa *path) onDemandPublisherStop(reason string) {
 }
 
 func (pa *path) setReady(desc *description.Session, allocateEncoder bool) error {
-	var err error
-	pa.stream, err = stream.New(
-		pa.udpMaxPayloadSize,
-		desc,
-		allocateEncoder,
-		logger.NewLimitedLogger(pa.source),
-	)
-	if err != nil {
-		return err
+	if pa.offlineSource != nil {
+		pa.offlineSource.Close()
+		pa.offlineSource = nil
+
+		err := pa.reattachStream(desc, allocateEncoder)
+		if err != nil {
+			pa.Log(logger.Info, "readers must be reconnected since tracks are different from the offline source: %v", err)
+			pa.closeStream()
+		}
+	}
+
+	if pa.stream == nil {
+		err := pa.createStream(desc, allocateEncoder, pa.source)
+		if err != nil {
+			return err
+		}
 	}
 
 	if pa.conf.Record {
@@ -737,17 +1215,117 @@ func (pa *path) setReady(desc *description.Session, allocateEncoder bool) error
 	pa.onNotReadyHook = hooks.OnReady(hooks.OnReadyParams{
 		Logger:          pa,
 		ExternalCmdPool: pa.externalCmdPool,
+		WebhookSender:   pa.webhookSender,
 		Conf:            pa.conf,
 		ExternalCmdEnv:  pa.ExternalCmdEnv(),
 		Desc:            pa.source.APISourceDescribe(),
 		Query:           pa.publisherQuery,
 	})
 
+	return nil
+}
+
+// filterTracks removes the tracks that are excluded by trackFilter.
+func (pa *path) filterTracks(desc *description.Session) (*description.Session, error) {
+	if pa.conf.TrackFilter == "" {
+		return desc, nil
+	}
+
+	ts, _ := conf.ParseTrackSelection(pa.conf.TrackFilter)
+
+	desc, err := ts.Apply(desc)
+	if err != nil {
+		return nil, fmt.Errorf("unable to apply trackFilter: %w", err)
+	}
+
+	return desc, nil
+}
+
+func (pa *path) createStream(desc *description.Session, allocateEncoder bool, decodeErrLogger logger.Writer) error {
+	desc, err := pa.filterTracks(desc)
+	if err != nil {
+		return err
+	}
+
+	pa.stream, err = stream.New(
+		pa.udpMaxPayloadSize,
+		desc,
+		allocateEncoder,
+		pa.conf.GOPCache,
+		time.Duration(pa.conf.TimeshiftBuffer),
+		logger.NewLimitedLogger(decodeErrLogger),
+	)
+	if err != nil {
+		return err
+	}
+
+	pa.readyTime = time.Now()
+	pa.streamGenerateRTPPackets = allocateEncoder
+
 	pa.parent.pathReady(pa)
 
+	pa.startPushing()
+
 	return nil
 }
 
+// reattachStream allows a new source to write into the existing stream.
+func (pa *path) reattachStream(desc *description.Session, generateRTPPackets bool) error {
+	if generateRTPPackets && !pa.streamGenerateRTPPackets {
+		return fmt.Errorf("the stream is not able to generate RTP packets")
+	}
+
+	desc, err := pa.filterTracks(desc)
+	if err != nil {
+		return err
+	}
+
+	return pa.stream.Reattach(desc)
+}
+
+// startOfflineSource starts looping the offline file into the stream.
+func (pa *path) startOfflineSource() {
+	if pa.offlineSource != nil {
+		return
+	}
+
+	src := &offlinesource.Source{
+		FilePath: pa.conf.OfflineSource,
+		Parent:   pa,
+	}
+	err := src.Initialize()
+	if err != nil {
+		pa.Log(logger.Error, "unable to load offline source: %v", err)
+		if pa.stream != nil {
+			pa.closeStream()
+		}
+		return
+	}
+
+	if pa.stream != nil {
+		err = pa.reattachStream(src.Desc(), true)
+		if err != nil {
+			pa.Log(logger.Info, "readers must be reconnected since tracks are different from the offline source: %v", err)
+			pa.closeStream()
+		}
+	}
+
+	if pa.stream == nil {
+		err = pa.createStream(src.Desc(), true, src)
+		if err != nil {
+			pa.Log(logger.Error, "unable to load offline source: %v", err)
+			return
+		}
+	}
+
+	src.Start(pa.stream)
+	pa.offlineSource = src
+
+	src.Log(logger.Info, "started, %s", defs.MediasInfo(src.Desc().Medias))
+
+	pa.consumeOnHoldRequests()
+}
+
 func (pa *path) consumeOnHoldRequests() {
 	for _, req := range pa.describeRequestsOnHold {
 		req.Res <- defs.PathDescribeRes{
@@ -763,19 +1341,47 @@ func (pa *path) consumeOnHoldRequests() {
 }
 
 func (pa *path) setNotReady() {
-	pa.parent.pathNotReady(pa)
+	pa.publisherGraceTimer.Stop()
+	pa.publisherGraceTimer = newEmptyTimer()
 
-	for r := range pa.readers {
-		pa.executeRemoveReader(r)
-		r.Close()
+	pa.stopPublisherResources()
+
+	// replace the publisher with the offline source, unless the path is closing
+	if pa.conf.OfflineSource != "" && pa.ctx.Err() == nil {
+		pa.startOfflineSource()
+		return
 	}
 
-	pa.onNotReadyHook()
+	pa.closeStream()
+}
+
+// stopPublisherResources stops hooks and recording, that are tied to the presence of a source.
+func (pa *path) stopPublisherResources() {
+	if pa.onNotReadyHook != nil {
+		pa.onNotReadyHook()
+		pa.onNotReadyHook = nil
+	}
 
 	if pa.recordAgent != nil {
 		pa.recordAgent.Close()
 		pa.recordAgent = nil
 	}
+}
+
+func (pa *path) closeStream() {
+	if pa.offlineSource != nil {
+		pa.offlineSource.Close()
+		pa.offlineSource = nil
+	}
+
+	pa.parent.pathNotReady(pa)
+
+	pa.stopPushing()
+
+	for r := range pa.readers {
+		pa.executeRemoveReader(r)
+		r.Close()
+	}
 
 	if pa.stream != nil {
 		pa.stream.Close()
@@ -793,42 +1399,78 @@ func (pa *path) startRecording() {
 		PathName:        pa.name,
 		Stream:          pa.stream,
 		OnSegmentCreate: func(segmentPath string) {
-			if pa.conf.RunOnRecordSegmentCreate != "" {
-				env := pa.ExternalCmdEnv()
-				env["MTX_SEGMENT_PATH"] = segmentPath
-
-				pa.Log(logger.Info, "runOnRecordSegmentCreate command launched")
-				externalcmd.NewCmd(
-					pa.externalCmdPool,
-					pa.conf.RunOnRecordSegmentCreate,
-					false,
-					env,
-					nil)
-			}
+			hooks.OnRecordSegmentCreate(hooks.OnRecordSegmentParams{
+				Logger:          pa,
+				ExternalCmdPool: pa.externalCmdPool,
+				WebhookSender:   pa.webhookSender,
+				Conf:            pa.conf,
+				ExternalCmdEnv:  pa.ExternalCmdEnv(),
+				SegmentPath:     segmentPath,
+			})
 		},
 		OnSegmentComplete: func(segmentPath string) {
-			if pa.conf.RunOnRecordSegmentComplete != "" {
-				env := pa.ExternalCmdEnv()
-				env["MTX_SEGMENT_PATH"] = segmentPath
-
-				pa.Log(logger.Info, "runOnRecordSegmentComplete command launched")
-				externalcmd.NewCmd(
-					pa.externalCmdPool,
-					pa.conf.RunOnRecordSegmentComplete,
-					false,
-					env,
-					nil)
-			}
+			hooks.OnRecordSegmentComplete(hooks.OnRecordSegmentParams{
+				Logger:          pa,
+				ExternalCmdPool: pa.externalCmdPool,
+				WebhookSender:   pa.webhookSender,
+				Conf:            pa.conf,
+				ExternalCmdEnv:  pa.ExternalCmdEnv(),
+				SegmentPath:     segmentPath,
+			})
 		},
 		Parent: pa,
 	}
 	pa.recordAgent.Initialize()
 }
 
+func (pa *path) startPushing() {
+	for _, target := range pa.conf.Push {
+		t := &push.Target{
+			Conf:              target,
+			ReadTimeout:       pa.readTimeout,
+			WriteTimeout:      pa.writeTimeout,
+			WriteQueueSize:    pa.writeQueueSize,
+			UDPMaxPayloadSize: pa.udpMaxPayloadSize,
+			Stream:            pa.stream,
+			Parent:            pa,
+		}
+		t.Initialize()
+		pa.pushTargets = append(pa.pushTargets, t)
+	}
+}
+
+func (pa *path) stopPushing() {
+	for _, t := range pa.pushTargets {
+		t.Close()
+	}
+	pa.pushTargets = nil
+}
+
 func (pa *path) executeRemoveReader(r defs.Reader) {
 	delete(pa.readers, r)
 }
 
+// startPublisherGrace detaches the publisher while keeping the stream,
+// waiting for the publisher to reconnect.
+func (pa *path) startPublisherGrace() {
+	pa.Log(logger.Info, "publisher disconnected, keeping readers attached for %v",
+		time.Duration(pa.conf.PublisherReconnectGrace))
+
+	pa.source = nil
+
+	pa.publisherGraceTimer.Stop()
+	pa.publisherGraceTimer = time.NewTimer(time.Duration(pa.conf.PublisherReconnectGrace))
+}
+
+// removePublisher removes the publisher, waiting for it to reconnect when publisherReconnectGrace is set.
+func (pa *path) removePublisher() {
+	if pa.stream != nil && pa.offlineSource == nil && pa.conf.PublisherReconnectGrace != 0 {
+		pa.startPublisherGrace()
+	} else {
+		pa.executeRemovePublisher()
+	}
+}
+
 func (pa *path) executeRemovePublisher() {
 	if pa.stream != nil {
 		pa.setNotReady()
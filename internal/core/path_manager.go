@@ -9,13 +9,20 @@ import (
 	"github.com/bluenviron/mediamtx/internal/conf"
 	"github.com/bluenviron/mediamtx/internal/defs"
 	"github.com/bluenviron/mediamtx/internal/externalcmd"
+	"github.com/bluenviron/mediamtx/internal/hooks"
 	"github.com/bluenviron/mediamtx/internal/logger"
+	"github.com/bluenviron/mediamtx/internal/webhook"
 )
 
 func pathConfCanBeUpdated(oldPathConf *conf.Path, newPathConf *conf.Path) bool {
-	clone := oldPathConf.Clone()
+	clone, err := oldPathConf.Clone()
+	if err != nil {
+		return false
+	}
 
+	clone.Aliases = newPathConf.Aliases
 	clone.Record = newPathConf.Record
+	clone.Push = newPathConf.Push
 
 	clone.RPICameraBrightness = newPathConf.RPICameraBrightness
 	clone.RPICameraContrast = newPathConf.RPICameraContrast
@@ -54,7 +61,9 @@ type pathManager struct {
 	writeQueueSize            int
 	udpMaxPayloadSize         int
 	pathConfs                 map[string]*conf.Path
+	runOnAuthFailure          string
 	externalCmdPool           *externalcmd.Pool
+	webhookSender             *webhook.Sender
 	parent                    pathManagerParent
 
 	ctx         context.Context
@@ -227,6 +236,26 @@ func (pm *pathManager) doPathNotReady(pa *path) {
 	}
 }
 
+func (pm *pathManager) authenticate(pathConf *conf.Path, accessRequest defs.PathAccessRequest) error {
+	err := doAuthentication(pm.externalAuthenticationURL, pm.authMethods, pathConf, accessRequest)
+	if err != nil && !isAuthChallenge(accessRequest) {
+		hooks.OnAuthFailure(hooks.OnAuthFailureParams{
+			Logger:           pm,
+			ExternalCmdPool:  pm.externalCmdPool,
+			WebhookSender:    pm.webhookSender,
+			RunOnAuthFailure: pm.runOnAuthFailure,
+			RTSPAddress:      pm.rtspAddress,
+			Path:             accessRequest.Name,
+			Query:            accessRequest.Query,
+			IP:               accessRequest.IP,
+			User:             accessRequestUser(accessRequest),
+			Protocol:         string(accessRequest.Proto),
+			Publish:          accessRequest.Publish,
+		})
+	}
+	return err
+}
+
 func (pm *pathManager) doFindPathConf(req defs.PathFindPathConfReq) {
 	_, pathConf, _, err := conf.FindPathConf(pm.pathConfs, req.AccessRequest.Name)
 	if err != nil {
@@ -234,8 +263,7 @@ func (pm *pathManager) doFindPathConf(req defs.PathFindPathConfReq) {
 		return
 	}
 
-	err = doAuthentication(pm.externalAuthenticationURL, pm.authMethods,
-		pathConf, req.AccessRequest)
+	err = pm.authenticate(pathConf, req.AccessRequest)
 	if err != nil {
 		req.Res <- defs.PathFindPathConfRes{Err: err}
 		return
@@ -251,19 +279,20 @@ func (pm *pathManager) doDescribe(req defs.PathDescribeReq) {
 		return
 	}
 
-	err = doAuthentication(pm.externalAuthenticationURL, pm.authMethods,
-		pathConf, req.AccessRequest)
+	err = pm.authenticate(pathConf, req.AccessRequest)
 	if err != nil {
 		req.Res <- defs.PathDescribeRes{Err: err}
 		return
 	}
 
+	name := pathConf.CanonicalName(req.AccessRequest.Name)
+
 	// create path if it doesn't exist
-	if _, ok := pm.paths[req.AccessRequest.Name]; !ok {
-		pm.createPath(pathConfName, pathConf, req.AccessRequest.Name, pathMatches)
+	if _, ok := pm.paths[name]; !ok {
+		pm.createPath(pathConfName, pathConf, name, pathMatches)
 	}
 
-	req.Res <- defs.PathDescribeRes{Path: pm.paths[req.AccessRequest.Name]}
+	req.Res <- defs.PathDescribeRes{Path: pm.paths[name]}
 }
 
 func (pm *pathManager) doAddReader(req defs.PathAddReaderReq) {
@@ -274,20 +303,21 @@ func (pm *pathManager) doAddReader(req defs.PathAddReaderReq) {
 	}
 
 	if !req.AccessRequest.SkipAuth {
-		err = doAuthentication(pm.externalAuthenticationURL, pm.authMethods,
-			pathConf, req.AccessRequest)
+		err = pm.authenticate(pathConf, req.AccessRequest)
 		if err != nil {
 			req.Res <- defs.PathAddReaderRes{Err: err}
 			return
 		}
 	}
 
+	name := pathConf.CanonicalName(req.AccessRequest.Name)
+
 	// create path if it doesn't exist
-	if _, ok := pm.paths[req.AccessRequest.Name]; !ok {
-		pm.createPath(pathConfName, pathConf, req.AccessRequest.Name, pathMatches)
+	if _, ok := pm.paths[name]; !ok {
+		pm.createPath(pathConfName, pathConf, name, pathMatches)
 	}
 
-	req.Res <- defs.PathAddReaderRes{Path: pm.paths[req.AccessRequest.Name]}
+	req.Res <- defs.PathAddReaderRes{Path: pm.paths[name]}
 }
 
 func (pm *pathManager) doAddPublisher(req defs.PathAddPublisherReq) {
@@ -298,20 +328,21 @@ func (pm *pathManager) doAddPublisher(req defs.PathAddPublisherReq) {
 	}
 
 	if !req.AccessRequest.SkipAuth {
-		err = doAuthentication(pm.externalAuthenticationURL, pm.authMethods,
-			pathConf, req.AccessRequest)
+		err = pm.authenticate(pathConf, req.AccessRequest)
 		if err != nil {
 			req.Res <- defs.PathAddPublisherRes{Err: err}
 			return
 		}
 	}
 
+	name := pathConf.CanonicalName(req.AccessRequest.Name)
+
 	// create path if it doesn't exist
-	if _, ok := pm.paths[req.AccessRequest.Name]; !ok {
-		pm.createPath(pathConfName, pathConf, req.AccessRequest.Name, pathMatches)
+	if _, ok := pm.paths[name]; !ok {
+		pm.createPath(pathConfName, pathConf, name, pathMatches)
 	}
 
-	req.Res <- defs.PathAddPublisherRes{Path: pm.paths[req.AccessRequest.Name]}
+	req.Res <- defs.PathAddPublisherRes{Path: pm.paths[name]}
 }
 
 func (pm *pathManager) doAPIPathsList(req pathAPIPathsListReq) {
@@ -326,6 +357,12 @@ func (pm *pathManager) doAPIPathsList(req pathAPIPathsListReq) {
 
 func (pm *pathManager) doAPIPathsGet(req pathAPIPathsGetReq) {
 	path, ok := pm.paths[req.name]
+	if !ok {
+		// resolve aliases
+		if _, pathConf, _, err := conf.FindPathConf(pm.pathConfs, req.name); err == nil {
+			path, ok = pm.paths[pathConf.CanonicalName(req.name)]
+		}
+	}
 	if !ok {
 		req.res <- pathAPIPathsGetRes{err: conf.ErrPathNotFound}
 		return
@@ -354,6 +391,8 @@ func (pm *pathManager) createPath(
 		matches:           matches,
 		wg:                &pm.wg,
 		externalCmdPool:   pm.externalCmdPool,
+		webhookSender:     pm.webhookSender,
+		pathManager:       pm,
 		parent:            pm,
 	}
 	pa.initialize()
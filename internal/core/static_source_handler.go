@@ -3,12 +3,15 @@ package core
 import (
 	"context"
 	"fmt"
+	"net/url"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/bluenviron/mediamtx/internal/conf"
 	"github.com/bluenviron/mediamtx/internal/defs"
 	"github.com/bluenviron/mediamtx/internal/logger"
+	composesource "github.com/bluenviron/mediamtx/internal/staticsources/compose"
 	hlssource "github.com/bluenviron/mediamtx/internal/staticsources/hls"
 	rpicamerasource "github.com/bluenviron/mediamtx/internal/staticsources/rpicamera"
 	rtmpsource "github.com/bluenviron/mediamtx/internal/staticsources/rtmp"
@@ -16,37 +19,77 @@ import (
 	srtsource "github.com/bluenviron/mediamtx/internal/staticsources/srt"
 	udpsource "github.com/bluenviron/mediamtx/internal/staticsources/udp"
 	webrtcsource "github.com/bluenviron/mediamtx/internal/staticsources/webrtc"
+	"github.com/bluenviron/mediamtx/internal/stream"
 )
 
 const (
-	staticSourceHandlerRetryPause = 5 * time.Second
+	staticSourceHandlerRetryPause    = 5 * time.Second
+	staticSourceHandlerFailbackPause = 30 * time.Second
 )
 
+// redactSource removes credentials from a source URL.
+func redactSource(source string) string {
+	u, err := url.Parse(source)
+	if err != nil || u.User == nil {
+		return source
+	}
+
+	u.User = nil
+	return u.String()
+}
+
 type staticSourceHandlerParent interface {
 	logger.Writer
 	staticSourceHandlerSetReady(context.Context, defs.PathSourceStaticSetReadyReq)
 	staticSourceHandlerSetNotReady(context.Context, defs.PathSourceStaticSetNotReadyReq)
 }
 
+// staticSourceProbe is the parent of a source that is started
+// only to check whether the primary source has recovered.
+type staticSourceProbe struct {
+	parent logger.Writer
+	ready  chan struct{}
+}
+
+// Log implements logger.Writer.
+func (p *staticSourceProbe) Log(level logger.Level, format string, args ...interface{}) {
+	p.parent.Log(level, "[probe] "+format, args...)
+}
+
+// SetReady implements defs.StaticSourceParent.
+func (p *staticSourceProbe) SetReady(_ defs.PathSourceStaticSetReadyReq) defs.PathSourceStaticSetReadyRes {
+	close(p.ready)
+	return defs.PathSourceStaticSetReadyRes{Err: fmt.Errorf("probe succeeded")}
+}
+
+// SetNotReady implements defs.StaticSourceParent.
+func (p *staticSourceProbe) SetNotReady(_ defs.PathSourceStaticSetNotReadyReq) {
+}
+
 // staticSourceHandler is a static source handler.
 type staticSourceHandler struct {
-	conf           *conf.Path
-	logLevel       conf.LogLevel
-	readTimeout    conf.StringDuration
-	writeTimeout   conf.StringDuration
-	writeQueueSize int
-	resolvedSource string
-	parent         staticSourceHandlerParent
+	conf            *conf.Path
+	logLevel        conf.LogLevel
+	readTimeout     conf.StringDuration
+	writeTimeout    conf.StringDuration
+	writeQueueSize  int
+	resolvedSources []string
+	pathManager     defs.PathManager
+	parent          staticSourceHandlerParent
 
 	ctx       context.Context
 	ctxCancel func()
-	instance  defs.StaticSource
 	running   bool
 
+	mutex       sync.Mutex
+	instance    defs.StaticSource
+	sourceIndex int
+
 	// in
 	chReloadConf          chan *conf.Path
 	chInstanceSetReady    chan defs.PathSourceStaticSetReadyReq
 	chInstanceSetNotReady chan defs.PathSourceStaticSetNotReadyReq
+	chRestart             chan struct{}
 
 	// out
 	done chan struct{}
@@ -56,63 +99,93 @@ func (s *staticSourceHandler) initialize() {
 	s.chReloadConf = make(chan *conf.Path)
 	s.chInstanceSetReady = make(chan defs.PathSourceStaticSetReadyReq)
 	s.chInstanceSetNotReady = make(chan defs.PathSourceStaticSetNotReadyReq)
+	s.chRestart = make(chan struct{}, 1)
+
+	s.instance = s.newInstance(s.resolvedSources[0], s)
+}
 
+func (s *staticSourceHandler) newInstance(resolvedSource string, parent defs.StaticSourceParent) defs.StaticSource {
 	switch {
-	case strings.HasPrefix(s.resolvedSource, "rtsp://") ||
-		strings.HasPrefix(s.resolvedSource, "rtsps://"):
-		s.instance = &rtspsource.Source{
-			ResolvedSource: s.resolvedSource,
+	case strings.HasPrefix(resolvedSource, "rtsp://") ||
+		strings.HasPrefix(resolvedSource, "rtsps://"):
+		return &rtspsource.Source{
+			ResolvedSource: resolvedSource,
 			ReadTimeout:    s.readTimeout,
 			WriteTimeout:   s.writeTimeout,
 			WriteQueueSize: s.writeQueueSize,
-			Parent:         s,
+			Parent:         parent,
 		}
 
-	case strings.HasPrefix(s.resolvedSource, "rtmp://") ||
-		strings.HasPrefix(s.resolvedSource, "rtmps://"):
-		s.instance = &rtmpsource.Source{
-			ResolvedSource: s.resolvedSource,
+	case strings.HasPrefix(resolvedSource, "rtmp://") ||
+		strings.HasPrefix(resolvedSource, "rtmps://"):
+		return &rtmpsource.Source{
+			ResolvedSource: resolvedSource,
 			ReadTimeout:    s.readTimeout,
 			WriteTimeout:   s.writeTimeout,
-			Parent:         s,
+			Parent:         parent,
 		}
 
-	case strings.HasPrefix(s.resolvedSource, "http://") ||
-		strings.HasPrefix(s.resolvedSource, "https://"):
-		s.instance = &hlssource.Source{
-			ResolvedSource: s.resolvedSource,
+	case strings.HasPrefix(resolvedSource, "http://") ||
+		strings.HasPrefix(resolvedSource, "https://"):
+		return &hlssource.Source{
+			ResolvedSource: resolvedSource,
 			ReadTimeout:    s.readTimeout,
-			Parent:         s,
+			Parent:         parent,
 		}
 
-	case strings.HasPrefix(s.resolvedSource, "udp://"):
-		s.instance = &udpsource.Source{
-			ResolvedSource: s.resolvedSource,
+	case strings.HasPrefix(resolvedSource, "udp://"):
+		return &udpsource.Source{
+			ResolvedSource: resolvedSource,
 			ReadTimeout:    s.readTimeout,
-			Parent:         s,
+			Parent:         parent,
 		}
 
-	case strings.HasPrefix(s.resolvedSource, "srt://"):
-		s.instance = &srtsource.Source{
-			ResolvedSource: s.resolvedSource,
+	case strings.HasPrefix(resolvedSource, "srt://"):
+		return &srtsource.Source{
+			ResolvedSource: resolvedSource,
 			ReadTimeout:    s.readTimeout,
-			Parent:         s,
+			Parent:         parent,
 		}
 
-	case strings.HasPrefix(s.resolvedSource, "whep://") ||
-		strings.HasPrefix(s.resolvedSource, "wheps://"):
-		s.instance = &webrtcsource.Source{
-			ResolvedSource: s.resolvedSource,
+	case strings.HasPrefix(resolvedSource, "whep://") ||
+		strings.HasPrefix(resolvedSource, "wheps://"):
+		return &webrtcsource.Source{
+			ResolvedSource: resolvedSource,
 			ReadTimeout:    s.readTimeout,
-			Parent:         s,
+			Parent:         parent,
+		}
+
+	case strings.HasPrefix(resolvedSource, "compose:"):
+		return &composesource.Source{
+			ResolvedSource: resolvedSource,
+			WriteQueueSize: s.writeQueueSize,
+			PathManager:    s.pathManager,
+			Parent:         parent,
 		}
 
-	case s.resolvedSource == "rpiCamera":
-		s.instance = &rpicamerasource.Source{
+	case resolvedSource == "rpiCamera":
+		return &rpicamerasource.Source{
 			LogLevel: s.logLevel,
-			Parent:   s,
+			Parent:   parent,
 		}
 	}
+
+	return nil
+}
+
+func (s *staticSourceHandler) currentInstance() defs.StaticSource {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.instance
+}
+
+func (s *staticSourceHandler) switchSource(index int) {
+	s.mutex.Lock()
+	s.sourceIndex = index
+	s.instance = s.newInstance(s.resolvedSources[index], s)
+	s.mutex.Unlock()
+
+	s.Log(logger.Info, "switching to source %d (%s)", index+1, redactSource(s.resolvedSources[index]))
 }
 
 func (s *staticSourceHandler) close(reason string) {
@@ -125,7 +198,7 @@ func (s *staticSourceHandler) start(onDemand bool) {
 	}
 
 	s.running = true
-	s.instance.Log(logger.Info, "started%s",
+	s.currentInstance().Log(logger.Info, "started%s",
 		func() string {
 			if onDemand {
 				return " on demand"
@@ -145,7 +218,7 @@ func (s *staticSourceHandler) stop(reason string) {
 	}
 
 	s.running = false
-	s.instance.Log(logger.Info, "stopped: %s", reason)
+	s.currentInstance().Log(logger.Info, "stopped: %s", reason)
 
 	s.ctxCancel()
 
@@ -158,6 +231,26 @@ func (s *staticSourceHandler) Log(level logger.Level, format string, args ...int
 	s.parent.Log(level, format, args...)
 }
 
+// forwardSetReady sends a SetReady request to the path and waits for the response.
+func (s *staticSourceHandler) forwardSetReady(req defs.PathSourceStaticSetReadyReq) defs.PathSourceStaticSetReadyRes {
+	res := make(chan defs.PathSourceStaticSetReadyRes, 1)
+	s.parent.staticSourceHandlerSetReady(s.ctx, defs.PathSourceStaticSetReadyReq{
+		Desc:               req.Desc,
+		GenerateRTPPackets: req.GenerateRTPPackets,
+		Res:                res,
+	})
+	return <-res
+}
+
+// forwardSetNotReady sends a SetNotReady request to the path and waits for the response.
+func (s *staticSourceHandler) forwardSetNotReady() {
+	res := make(chan struct{})
+	s.parent.staticSourceHandlerSetNotReady(s.ctx, defs.PathSourceStaticSetNotReadyReq{
+		Res: res,
+	})
+	<-res
+}
+
 func (s *staticSourceHandler) run() {
 	defer close(s.done)
 
@@ -168,32 +261,143 @@ func (s *staticSourceHandler) run() {
 
 	recreate := func() {
 		runCtx, runCtxCancel = context.WithCancel(context.Background())
+		instance := s.currentInstance()
+		ctx := runCtx
+		pathConf := s.conf
 		go func() {
-			runErr <- s.instance.Run(defs.StaticSourceRunParams{
-				Context:    runCtx,
-				Conf:       s.conf,
+			runErr <- instance.Run(defs.StaticSourceRunParams{
+				Context:    ctx,
+				Conf:       pathConf,
 				ReloadConf: runReloadConf,
 			})
 		}()
 	}
 
+	s.mutex.Lock()
+	sourceIndex := s.sourceIndex
+	s.mutex.Unlock()
+
 	recreate()
 
 	recreating := false
 	recreateTimer := newEmptyTimer()
 
+	// failover state
+	failover := len(s.resolvedSources) > 1
+	failures := 0
+	var curStream *stream.Stream
+	curGenerateRTPPackets := false
+	ready := false       // whether the current instance is attached to the stream
+	readyInRun := false  // whether the current instance has been ready at least once
+	held := false        // whether the stream is kept for the next instance
+	forceSwitch := false // whether the next error must cause a switch
+	pendingIndex := -1   // index of the source to switch to, after the current instance exits
+
+	failbackTimer := newEmptyTimer()
+	var probeCtxCancel func()
+	probeDone := make(chan bool)
+	probing := false
+
+	scheduleFailback := func() {
+		failbackTimer.Stop()
+		if failover && s.conf.SourceFailback && sourceIndex != 0 && !probing {
+			failbackTimer = time.NewTimer(staticSourceHandlerFailbackPause)
+		} else {
+			failbackTimer = newEmptyTimer()
+		}
+	}
+
+	releaseStream := func() {
+		if held {
+			held = false
+			curStream = nil
+			s.forwardSetNotReady()
+		}
+	}
+
 	for {
 		select {
 		case err := <-runErr:
 			runCtxCancel()
-			s.instance.Log(logger.Error, err.Error())
+			ready = false
+
+			if pendingIndex >= 0 {
+				sourceIndex = pendingIndex
+				pendingIndex = -1
+				failures = 0
+				s.switchSource(sourceIndex)
+				scheduleFailback()
+				recreate()
+				readyInRun = false
+				continue
+			}
+
+			s.currentInstance().Log(logger.Error, err.Error())
+
+			// the new instance failed before replacing the previous one
+			if !readyInRun {
+				releaseStream()
+			}
+
+			failures++
+
+			if failover && (forceSwitch || failures >= s.conf.SourceFailoverAfter) {
+				forceSwitch = false
+				failures = 0
+				sourceIndex = (sourceIndex + 1) % len(s.resolvedSources)
+				s.switchSource(sourceIndex)
+				scheduleFailback()
+				recreate()
+				readyInRun = false
+				continue
+			}
+
 			recreating = true
 			recreateTimer = time.NewTimer(staticSourceHandlerRetryPause)
 
 		case req := <-s.chInstanceSetReady:
-			s.parent.staticSourceHandlerSetReady(s.ctx, req)
+			readyInRun = true
+
+			if held {
+				held = false
+
+				if req.GenerateRTPPackets == curGenerateRTPPackets {
+					err := curStream.Reattach(req.Desc)
+					if err == nil {
+						ready = true
+						failures = 0
+						s.Log(logger.Info, "source replaced without interrupting readers")
+						req.Res <- defs.PathSourceStaticSetReadyRes{Stream: curStream}
+						continue
+					}
+
+					s.Log(logger.Info, "readers must be reconnected since tracks are different: %v", err)
+				}
+
+				curStream = nil
+				s.forwardSetNotReady()
+			}
+
+			res := s.forwardSetReady(req)
+			if res.Err == nil {
+				curStream = res.Stream
+				curGenerateRTPPackets = req.GenerateRTPPackets
+				ready = true
+				failures = 0
+			}
+			req.Res <- res
 
 		case req := <-s.chInstanceSetNotReady:
+			ready = false
+
+			// keep the stream, in order to allow the next source to replace the current one
+			if failover {
+				held = true
+				close(req.Res)
+				continue
+			}
+
+			curStream = nil
 			s.parent.staticSourceHandlerSetNotReady(s.ctx, req)
 
 		case newConf := <-s.chReloadConf:
@@ -209,20 +413,93 @@ func (s *staticSourceHandler) run() {
 				}()
 			}
 
+		case <-s.chRestart:
+			if ready {
+				s.currentInstance().Log(logger.Info, "restarting since the stream is stalled")
+				forceSwitch = failover
+				runCtxCancel()
+			}
+
 		case <-recreateTimer.C:
 			recreate()
 			recreating = false
+			readyInRun = false
+
+		case <-failbackTimer.C:
+			failbackTimer = newEmptyTimer()
+
+			probeCtx, cancel := context.WithCancel(context.Background())
+			probeCtxCancel = cancel
+			probe := &staticSourceProbe{
+				parent: s,
+				ready:  make(chan struct{}),
+			}
+			instance := s.newInstance(s.resolvedSources[0], probe)
+			pathConf := s.conf
+			probing = true
+
+			go func() {
+				instance.Run(defs.StaticSourceRunParams{ //nolint:errcheck
+					Context:    probeCtx,
+					Conf:       pathConf,
+					ReloadConf: make(chan *conf.Path),
+				})
+
+				select {
+				case <-probe.ready:
+					probeDone <- true
+				default:
+					probeDone <- false
+				}
+			}()
+
+		case recovered := <-probeDone:
+			probeCtxCancel()
+			probing = false
+
+			if recovered && sourceIndex != 0 {
+				s.Log(logger.Info, "primary source has recovered")
+
+				if recreating {
+					recreateTimer.Stop()
+					recreating = false
+					sourceIndex = 0
+					failures = 0
+					s.switchSource(sourceIndex)
+					recreate()
+					readyInRun = false
+				} else {
+					pendingIndex = 0
+					runCtxCancel()
+				}
+			}
+
+			scheduleFailback()
 
 		case <-s.ctx.Done():
 			if !recreating {
 				runCtxCancel()
 				<-runErr
 			}
+			if probing {
+				probeCtxCancel()
+				<-probeDone
+			}
+			failbackTimer.Stop()
 			return
 		}
 	}
 }
 
+// restart closes the current instance, in order to start it again or to switch to a backup source.
+// It doesn't block, since it is called by the path, that may be busy serving the handler.
+func (s *staticSourceHandler) restart() {
+	select {
+	case s.chRestart <- struct{}{}:
+	default:
+	}
+}
+
 func (s *staticSourceHandler) reloadConf(newConf *conf.Path) {
 	select {
 	case s.chReloadConf <- newConf:
@@ -230,9 +507,16 @@ func (s *staticSourceHandler) reloadConf(newConf *conf.Path) {
 	}
 }
 
-// APISourceDescribe instanceements source.
+// APISourceDescribe implements source.
 func (s *staticSourceHandler) APISourceDescribe() defs.APIPathSourceOrReader {
-	return s.instance.APISourceDescribe()
+	return s.currentInstance().APISourceDescribe()
+}
+
+// activeSource returns the source that is currently in use.
+func (s *staticSourceHandler) activeSource() string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return redactSource(s.resolvedSources[s.sourceIndex])
 }
 
 // setReady is called by a staticSource.
@@ -243,7 +527,7 @@ func (s *staticSourceHandler) SetReady(req defs.PathSourceStaticSetReadyReq) def
 		res := <-req.Res
 
 		if res.Err == nil {
-			s.instance.Log(logger.Info, "ready: %s", defs.MediasInfo(req.Desc.Medias))
+			s.currentInstance().Log(logger.Info, "ready: %s", defs.MediasInfo(req.Desc.Medias))
 		}
 
 		return res
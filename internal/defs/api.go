@@ -28,15 +28,53 @@ type APIPathSourceOrReader struct {
 
 // APIPath is a path.
 type APIPath struct {
-	Name          string                  `json:"name"`
-	ConfName      string                  `json:"confName"`
-	Source        *APIPathSourceOrReader  `json:"source"`
-	Ready         bool                    `json:"ready"`
-	ReadyTime     *time.Time              `json:"readyTime"`
-	Tracks        []string                `json:"tracks"`
-	BytesReceived uint64                  `json:"bytesReceived"`
-	BytesSent     uint64                  `json:"bytesSent"`
-	Readers       []APIPathSourceOrReader `json:"readers"`
+	Name                 string                  `json:"name"`
+	ConfName             string                  `json:"confName"`
+	Source               *APIPathSourceOrReader  `json:"source"`
+	ActiveSource         *string                 `json:"activeSource"`
+	Ready                bool                    `json:"ready"`
+	ReadyTime            *time.Time              `json:"readyTime"`
+	Tracks               []string                `json:"tracks"`
+	TrackStats           []APIPathTrackStats     `json:"trackStats"`
+	BytesReceived        uint64                  `json:"bytesReceived"`
+	BytesSent            uint64                  `json:"bytesSent"`
+	TimeshiftBufferBytes uint64                  `json:"timeshiftBufferBytes"`
+	Readers              []APIPathSourceOrReader `json:"readers"`
+	Push                 []APIPathPushTarget     `json:"push"`
+}
+
+// APIPathTrackStats are statistics of a track.
+type APIPathTrackStats struct {
+	Codec        string   `json:"codec"`
+	Bitrate1s    uint64   `json:"bitrate1s"`
+	Bitrate10s   uint64   `json:"bitrate10s"`
+	FPS          float64  `json:"fps"`
+	GOPLength    uint64   `json:"gopLength"`
+	GOPDuration  float64  `json:"gopDuration"`
+	MaxFrameSize uint64   `json:"maxFrameSize"`
+	DecodeErrors uint64   `json:"decodeErrors"`
+	RMSLevel     *float64 `json:"rmsLevel"`
+	PeakLevel    *float64 `json:"peakLevel"`
+}
+
+// APIPathPushTargetState is the state of a push target.
+type APIPathPushTargetState string
+
+// states.
+const (
+	APIPathPushTargetStateConnecting APIPathPushTargetState = "connecting"
+	APIPathPushTargetStatePushing    APIPathPushTargetState = "pushing"
+	APIPathPushTargetStateWaiting    APIPathPushTargetState = "waiting"
+	APIPathPushTargetStateFailed     APIPathPushTargetState = "failed"
+)
+
+// APIPathPushTarget is a push target.
+type APIPathPushTarget struct {
+	URL       string                 `json:"url"`
+	State     APIPathPushTargetState `json:"state"`
+	Retries   int                    `json:"retries"`
+	LastError *string                `json:"lastError"`
+	BytesSent uint64                 `json:"bytesSent"`
 }
 
 // APIPathList is a list of paths.
@@ -46,12 +84,32 @@ type APIPathList struct {
 	Items     []*APIPath `json:"items"`
 }
 
+// APIHook is a running hook command.
+type APIHook struct {
+	Name           string     `json:"name"`
+	Path           string     `json:"path"`
+	Command        string     `json:"command"`
+	Created        time.Time  `json:"created"`
+	PID            *int       `json:"pid"`
+	Started        *time.Time `json:"started"`
+	Restarts       int        `json:"restarts"`
+	LastExitStatus *int       `json:"lastExitStatus"`
+}
+
+// APIHookList is a list of running hook commands.
+type APIHookList struct {
+	ItemCount int        `json:"itemCount"`
+	PageCount int        `json:"pageCount"`
+	Items     []*APIHook `json:"items"`
+}
+
 // APIHLSMuxer is an HLS muxer.
 type APIHLSMuxer struct {
-	Path        string    `json:"path"`
-	Created     time.Time `json:"created"`
-	LastRequest time.Time `json:"lastRequest"`
-	BytesSent   uint64    `json:"bytesSent"`
+	Path         string    `json:"path"`
+	Created      time.Time `json:"created"`
+	LastRequest  time.Time `json:"lastRequest"`
+	BytesSent    uint64    `json:"bytesSent"`
+	UnitsDropped uint64    `json:"unitsDropped"`
 }
 
 // APIHLSMuxerList is a list of HLS muxers.
@@ -81,6 +139,7 @@ type APIRTMPConn struct {
 	Query         string           `json:"query"`
 	BytesReceived uint64           `json:"bytesReceived"`
 	BytesSent     uint64           `json:"bytesSent"`
+	UnitsDropped  uint64           `json:"unitsDropped"`
 }
 
 // APIRTMPConnList is a list of RTMP connections.
@@ -156,6 +215,7 @@ type APISRTConn struct {
 	Query         string          `json:"query"`
 	BytesReceived uint64          `json:"bytesReceived"`
 	BytesSent     uint64          `json:"bytesSent"`
+	UnitsDropped  uint64          `json:"unitsDropped"`
 }
 
 // APISRTConnList is a list of SRT connections.
@@ -187,6 +247,7 @@ type APIWebRTCSession struct {
 	Query                     string                `json:"query"`
 	BytesReceived             uint64                `json:"bytesReceived"`
 	BytesSent                 uint64                `json:"bytesSent"`
+	UnitsDropped              uint64                `json:"unitsDropped"`
 }
 
 // APIWebRTCSessionList is a list of WebRTC sessions.
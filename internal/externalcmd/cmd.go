@@ -4,12 +4,21 @@ package externalcmd
 import (
 	"errors"
 	"fmt"
+	"math/rand"
+	"os"
+	"os/exec"
 	"strings"
+	"sync"
 	"time"
+
+	"github.com/bluenviron/mediamtx/internal/logger"
 )
 
 const (
-	restartPause = 5 * time.Second
+	restartMinPause = 5 * time.Second
+
+	// time to wait for output to be closed after the command exits.
+	outputWaitDelay = 2 * time.Second
 )
 
 var errTerminated = errors.New("terminated")
@@ -20,24 +29,49 @@ type OnExitFunc func(error)
 // Environment is a Cmd environment.
 type Environment map[string]string
 
+// Status is the status of a Cmd.
+type Status struct {
+	Name           string
+	Path           string
+	Command        string
+	Created        time.Time
+	PID            int
+	Started        time.Time
+	Restarts       int
+	LastExitStatus *int
+}
+
 // Cmd is an external command.
 type Cmd struct {
 	pool    *Pool
+	name    string
 	cmdstr  string
 	restart bool
 	env     Environment
+	output  logger.Writer
 	onExit  func(error)
 
+	mutex          sync.RWMutex
+	created        time.Time
+	pid            int
+	started        time.Time
+	restarts       int
+	lastExitStatus *int
+
 	// in
 	terminate chan struct{}
 }
 
 // NewCmd allocates a Cmd.
+// If output is not nil, the command output is written into it, line by line.
+// Otherwise, it is written into the standard output.
 func NewCmd(
 	pool *Pool,
+	name string,
 	cmdstr string,
 	restart bool,
 	env Environment,
+	output logger.Writer,
 	onExit OnExitFunc,
 ) *Cmd {
 	// replace variables in both Linux and Windows, in order to allow using the
@@ -52,14 +86,17 @@ func NewCmd(
 
 	e := &Cmd{
 		pool:      pool,
+		name:      name,
 		cmdstr:    cmdstr,
 		restart:   restart,
 		env:       env,
+		output:    output,
 		onExit:    onExit,
+		created:   time.Now(),
 		terminate: make(chan struct{}),
 	}
 
-	pool.wg.Add(1)
+	pool.add(e)
 
 	go e.run()
 
@@ -71,10 +108,30 @@ func (e *Cmd) Close() {
 	close(e.terminate)
 }
 
+func restartPause(failures int, maxPause time.Duration) time.Duration {
+	pause := restartMinPause
+	for i := 0; i < failures && pause < maxPause; i++ {
+		pause *= 2
+	}
+
+	// add jitter in order to avoid restarting many commands at once
+	pause = pause*3/4 + time.Duration(rand.Int63n(int64(pause/2)+1))
+
+	if pause > maxPause {
+		pause = maxPause
+	}
+
+	return pause
+}
+
 func (e *Cmd) run() {
-	defer e.pool.wg.Done()
+	defer e.pool.remove(e)
+
+	failures := 0
 
 	for {
+		start := time.Now()
+
 		err := e.runOSSpecific()
 		if errors.Is(err, errTerminated) {
 			return
@@ -87,16 +144,90 @@ func (e *Cmd) run() {
 			return
 		}
 
-		if err != nil {
-			e.onExit(err)
-		} else {
-			e.onExit(fmt.Errorf("command exited with code 0"))
+		if err == nil {
+			err = fmt.Errorf("command exited with code 0")
+		}
+
+		conf := e.pool.getConf()
+
+		// a command that has been running for a long time is considered healthy
+		if time.Since(start) > conf.RestartMaxPause {
+			failures = 0
+		}
+
+		if conf.RestartMaxRetries != 0 && failures >= conf.RestartMaxRetries {
+			e.onExit(fmt.Errorf("%w, maximum number of restarts reached", err))
+			return
 		}
 
+		e.onExit(err)
+
+		pause := restartPause(failures, conf.RestartMaxPause)
+		failures++
+
 		select {
-		case <-time.After(restartPause):
+		case <-time.After(pause):
 		case <-e.terminate:
 			return
 		}
+
+		e.mutex.Lock()
+		e.restarts++
+		e.mutex.Unlock()
+	}
+}
+
+// setOutput routes the command output and returns a function that flushes it.
+func (e *Cmd) setOutput(cmd *exec.Cmd) func() {
+	if e.output == nil {
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		return func() {}
+	}
+
+	onLine := func(line string) {
+		e.output.Log(e.pool.getConf().OutputLevel, "[%s] %s", e.name, line)
+	}
+
+	stdout := &lineWriter{onLine: onLine}
+	stderr := &lineWriter{onLine: onLine}
+
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	cmd.WaitDelay = outputWaitDelay
+
+	return func() {
+		stdout.flush()
+		stderr.flush()
+	}
+}
+
+func (e *Cmd) setStarted(pid int) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.pid = pid
+	e.started = time.Now()
+}
+
+func (e *Cmd) setExited(code int) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.pid = 0
+	e.lastExitStatus = &code
+}
+
+func (e *Cmd) status() *Status {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+
+	return &Status{
+		Name:           e.name,
+		Path:           e.env["MTX_PATH"],
+		Command:        e.cmdstr,
+		Created:        e.created,
+		PID:            e.pid,
+		Started:        e.started,
+		Restarts:       e.restarts,
+		LastExitStatus: e.lastExitStatus,
 	}
 }
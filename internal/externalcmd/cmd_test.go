@@ -0,0 +1,25 @@
+package externalcmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRestartPause(t *testing.T) {
+	for _, ca := range []struct {
+		failures int
+		min      time.Duration
+		max      time.Duration
+	}{
+		{0, 3750 * time.Millisecond, 6250 * time.Millisecond},
+		{1, 7500 * time.Millisecond, 12500 * time.Millisecond},
+		{3, 30 * time.Second, 50 * time.Second},
+		{20, 45 * time.Second, 60 * time.Second},
+	} {
+		pause := restartPause(ca.failures, 60*time.Second)
+		require.GreaterOrEqual(t, pause, ca.min)
+		require.LessOrEqual(t, pause, ca.max)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"os"
 	"os/exec"
 	"syscall"
+	"time"
 
 	"github.com/kballard/go-shellquote"
 )
@@ -21,29 +22,36 @@ func (e *Cmd) runOSSpecific() error {
 
 	cmd := exec.Command(cmdParts[0], cmdParts[1:]...)
 
+	// put the command in a dedicated process group,
+	// in order to be able to terminate its children too.
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+
 	cmd.Env = append([]string(nil), os.Environ()...)
 	for key, val := range e.env {
 		cmd.Env = append(cmd.Env, key+"="+val)
 	}
 
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	flushOutput := e.setOutput(cmd)
 
 	err = cmd.Start()
 	if err != nil {
 		return err
 	}
 
+	e.setStarted(cmd.Process.Pid)
+
 	cmdDone := make(chan int)
 	go func() {
 		cmdDone <- func() int {
+			defer flushOutput()
+
 			err := cmd.Wait()
 			if err == nil {
 				return 0
 			}
 			var ee *exec.ExitError
 			if errors.As(err, &ee) {
-				ee.ExitCode()
+				return ee.ExitCode()
 			}
 			return 0
 		}()
@@ -51,11 +59,23 @@ func (e *Cmd) runOSSpecific() error {
 
 	select {
 	case <-e.terminate:
-		syscall.Kill(cmd.Process.Pid, syscall.SIGINT) //nolint:errcheck
-		<-cmdDone
+		syscall.Kill(-cmd.Process.Pid, syscall.SIGTERM) //nolint:errcheck
+
+		select {
+		case <-cmdDone:
+		case <-time.After(e.pool.getConf().TerminationTimeout):
+			syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL) //nolint:errcheck
+			<-cmdDone
+		}
+
+		// kill children that are still alive
+		syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL) //nolint:errcheck
+
 		return errTerminated
 
 	case c := <-cmdDone:
+		e.setExited(c)
+
 		if c != 0 {
 			return fmt.Errorf("command exited with code %d", c)
 		}
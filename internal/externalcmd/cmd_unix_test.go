@@ -0,0 +1,67 @@
+//go:build !windows
+// +build !windows
+
+package externalcmd
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCmdTerminate(t *testing.T) {
+	dir, err := os.MkdirTemp("", "mediamtx-externalcmd")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	childPIDFile := filepath.Join(dir, "child")
+
+	pool := NewPool()
+	pool.SetConf(PoolConf{
+		TerminationTimeout: 500 * time.Millisecond,
+		RestartMaxPause:    time.Minute,
+	})
+
+	// the command ignores SIGTERM and starts a child
+	cmd := NewCmd(
+		pool,
+		"test",
+		"sh -c 'trap \"\" TERM; sleep 30 & echo $! > "+childPIDFile+"; wait'",
+		false,
+		nil,
+		nil,
+		nil)
+
+	var childPID int
+	for {
+		byts, err := os.ReadFile(childPIDFile)
+		if err == nil && strings.HasSuffix(string(byts), "\n") {
+			childPID, err = strconv.Atoi(strings.TrimSpace(string(byts)))
+			require.NoError(t, err)
+			break
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	start := time.Now()
+	cmd.Close()
+	pool.Close()
+	require.Greater(t, time.Since(start), 400*time.Millisecond)
+	require.Less(t, time.Since(start), 5*time.Second)
+
+	// the child has been killed too. It may still exist as a zombie.
+	// SIGKILL is delivered asynchronously, therefore wait for it.
+	require.Eventually(t, func() bool {
+		if syscall.Kill(childPID, 0) != nil {
+			return true
+		}
+		byts, err := os.ReadFile("/proc/" + strconv.Itoa(childPID) + "/stat")
+		return err != nil || strings.Contains(string(byts), ") Z ")
+	}, 2*time.Second, 10*time.Millisecond)
+}
@@ -44,17 +44,20 @@ func (e *Cmd) runOSSpecific() error {
 		cmd.Env = append(cmd.Env, key+"="+val)
 	}
 
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	flushOutput := e.setOutput(cmd)
 
 	err := cmd.Start()
 	if err != nil {
 		return err
 	}
 
+	e.setStarted(cmd.Process.Pid)
+
 	cmdDone := make(chan int)
 	go func() {
 		cmdDone <- func() int {
+			defer flushOutput()
+
 			err := cmd.Wait()
 			if err == nil {
 				return 0
@@ -76,6 +79,8 @@ func (e *Cmd) runOSSpecific() error {
 		return errTerminated
 
 	case c := <-cmdDone:
+		e.setExited(c)
+
 		if c != 0 {
 			return fmt.Errorf("command exited with code %d", c)
 		}
@@ -0,0 +1,45 @@
+package externalcmd
+
+import (
+	"bytes"
+	"strings"
+)
+
+// maximum size of a line. Longer lines are split.
+const maxLineSize = 4096
+
+// lineWriter is a io.Writer that splits output into lines.
+type lineWriter struct {
+	onLine func(string)
+
+	buf []byte
+}
+
+// Write implements io.Writer.
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+
+		w.onLine(strings.TrimRight(string(w.buf[:i]), "\r"))
+		w.buf = w.buf[i+1:]
+	}
+
+	for len(w.buf) > maxLineSize {
+		w.onLine(string(w.buf[:maxLineSize]))
+		w.buf = w.buf[maxLineSize:]
+	}
+
+	return len(p), nil
+}
+
+func (w *lineWriter) flush() {
+	if len(w.buf) != 0 {
+		w.onLine(strings.TrimRight(string(w.buf), "\r"))
+		w.buf = nil
+	}
+}
@@ -0,0 +1,31 @@
+package externalcmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLineWriter(t *testing.T) {
+	var lines []string
+
+	w := &lineWriter{
+		onLine: func(line string) {
+			lines = append(lines, line)
+		},
+	}
+
+	w.Write([]byte("first li"))                       //nolint:errcheck
+	w.Write([]byte("ne\r\nsecond line\nthi"))         //nolint:errcheck
+	w.Write([]byte(strings.Repeat("a", maxLineSize))) //nolint:errcheck
+	w.Write([]byte("rd"))                             //nolint:errcheck
+	w.flush()
+
+	require.Equal(t, []string{
+		"first line",
+		"second line",
+		"thi" + strings.Repeat("a", maxLineSize-3),
+		"aaard",
+	}, lines)
+}
@@ -1,20 +1,97 @@
 package externalcmd
 
 import (
+	"sort"
 	"sync"
+	"time"
+
+	"github.com/bluenviron/mediamtx/internal/logger"
 )
 
+// PoolConf is the configuration of a Pool.
+type PoolConf struct {
+	// level used to log the output of commands.
+	OutputLevel logger.Level
+
+	// time to wait for commands to exit after SIGTERM, before sending SIGKILL.
+	TerminationTimeout time.Duration
+
+	// maximum pause between restarts of a command.
+	RestartMaxPause time.Duration
+
+	// maximum number of consecutive restarts of a command. 0 means unlimited.
+	RestartMaxRetries int
+}
+
 // Pool is a pool of external commands.
 type Pool struct {
 	wg sync.WaitGroup
+
+	mutex sync.RWMutex
+	cmds  map[*Cmd]struct{}
+	conf  PoolConf
 }
 
 // NewPool allocates a Pool.
 func NewPool() *Pool {
-	return &Pool{}
+	return &Pool{
+		cmds: make(map[*Cmd]struct{}),
+		conf: PoolConf{
+			OutputLevel:        logger.Info,
+			TerminationTimeout: 10 * time.Second,
+			RestartMaxPause:    5 * time.Minute,
+		},
+	}
 }
 
 // Close waits for all external commands to exit.
 func (p *Pool) Close() {
 	p.wg.Wait()
 }
+
+// SetConf sets the configuration of the pool.
+// It is applied to running commands too.
+func (p *Pool) SetConf(conf PoolConf) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.conf = conf
+}
+
+func (p *Pool) getConf() PoolConf {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	return p.conf
+}
+
+func (p *Pool) add(e *Cmd) {
+	p.wg.Add(1)
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.cmds[e] = struct{}{}
+}
+
+func (p *Pool) remove(e *Cmd) {
+	p.mutex.Lock()
+	delete(p.cmds, e)
+	p.mutex.Unlock()
+
+	p.wg.Done()
+}
+
+// Status returns the status of all commands in the pool, sorted by creation time.
+func (p *Pool) Status() []*Status {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	out := make([]*Status, 0, len(p.cmds))
+	for e := range p.cmds {
+		out = append(out, e.status())
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Created.Before(out[j].Created)
+	})
+
+	return out
+}
@@ -1,17 +1,22 @@
 package formatprocessor
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/bluenviron/gortsplib/v4/pkg/format"
 	"github.com/pion/rtp"
 
+	"github.com/bluenviron/mediamtx/internal/metadata"
 	"github.com/bluenviron/mediamtx/internal/unit"
 )
 
 type formatProcessorGeneric struct {
 	udpMaxPayloadSize int
+	format            format.Format
+	encoder           *metadata.RTPEncoder
+	decoder           *metadata.RTPDecoder
 }
 
 func newGeneric(
@@ -19,24 +24,60 @@ func newGeneric(
 	forma format.Format,
 	generateRTPPackets bool,
 ) (*formatProcessorGeneric, error) {
+	t := &formatProcessorGeneric{
+		udpMaxPayloadSize: udpMaxPayloadSize,
+		format:            forma,
+	}
+
 	if generateRTPPackets {
-		return nil, fmt.Errorf("we don't know how to generate RTP packets of format %+v", forma)
+		// metadata units are the only generic units we know how to packetize
+		if !metadata.IsMetadata(forma) {
+			return nil, fmt.Errorf("we don't know how to generate RTP packets of format %+v", forma)
+		}
+
+		err := t.createEncoder()
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	return &formatProcessorGeneric{
-		udpMaxPayloadSize: udpMaxPayloadSize,
-	}, nil
+	return t, nil
 }
 
-func (t *formatProcessorGeneric) ProcessUnit(_ unit.Unit) error {
-	return fmt.Errorf("using a generic unit without RTP is not supported")
+func (t *formatProcessorGeneric) createEncoder() error {
+	t.encoder = &metadata.RTPEncoder{
+		PayloadType:    t.format.PayloadType(),
+		PayloadMaxSize: t.udpMaxPayloadSize - 12,
+	}
+	return t.encoder.Init()
+}
+
+func (t *formatProcessorGeneric) ProcessUnit(uu unit.Unit) error {
+	if t.encoder == nil {
+		return fmt.Errorf("using a generic unit without RTP is not supported")
+	}
+
+	u := uu.(*unit.Generic)
+
+	pkts, err := t.encoder.Encode(u.Payload)
+	if err != nil {
+		return err
+	}
+	u.RTPPackets = pkts
+
+	ts := uint32(multiplyAndDivide(u.PTS, time.Duration(t.format.ClockRate()), time.Second))
+	for _, pkt := range u.RTPPackets {
+		pkt.Timestamp += ts
+	}
+
+	return nil
 }
 
 func (t *formatProcessorGeneric) ProcessRTPPacket(
 	pkt *rtp.Packet,
 	ntp time.Time,
 	pts time.Duration,
-	_ bool,
+	hasNonRTSPReaders bool,
 ) (Unit, error) {
 	u := &unit.Generic{
 		Base: unit.Base{
@@ -55,5 +96,27 @@ func (t *formatProcessorGeneric) ProcessRTPPacket(
 			pkt.MarshalSize(), t.udpMaxPayloadSize)
 	}
 
+	// decode from RTP
+	if metadata.IsMetadata(t.format) && (hasNonRTSPReaders || t.decoder != nil) {
+		if t.decoder == nil {
+			t.decoder = &metadata.RTPDecoder{}
+			err := t.decoder.Init()
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		payload, err := t.decoder.Decode(pkt)
+		if err != nil {
+			if errors.Is(err, metadata.ErrMorePacketsNeeded) {
+				return u, nil
+			}
+			return nil, err
+		}
+
+		u.Payload = payload
+	}
+
+	// route packet as is
 	return u, nil
 }
@@ -1,12 +1,16 @@
 package formatprocessor
 
 import (
+	"bytes"
 	"testing"
 	"time"
 
 	"github.com/bluenviron/gortsplib/v4/pkg/format"
 	"github.com/pion/rtp"
 	"github.com/stretchr/testify/require"
+
+	"github.com/bluenviron/mediamtx/internal/metadata"
+	"github.com/bluenviron/mediamtx/internal/unit"
 )
 
 func TestGenericRemovePadding(t *testing.T) {
@@ -49,3 +53,40 @@ func TestGenericRemovePadding(t *testing.T) {
 		Payload: []byte{1, 2, 3, 4},
 	}, pkt)
 }
+
+func TestGenericMetadata(t *testing.T) {
+	forma := metadata.NewKLVFormat()
+
+	p, err := New(1472, forma, true)
+	require.NoError(t, err)
+
+	payload := bytes.Repeat([]byte{1, 2, 3, 4}, 1000)
+
+	u := &unit.Generic{
+		Base: unit.Base{
+			PTS: 30 * time.Millisecond,
+		},
+		Payload: payload,
+	}
+
+	err = p.ProcessUnit(u)
+	require.NoError(t, err)
+	require.Equal(t, 3, len(u.RTPPackets))
+
+	p2, err := New(1472, forma, false)
+	require.NoError(t, err)
+
+	for i, pkt := range u.RTPPackets {
+		require.Equal(t, uint32(2700), pkt.Timestamp)
+
+		var out Unit
+		out, err = p2.ProcessRTPPacket(pkt, time.Time{}, 0, true)
+		require.NoError(t, err)
+
+		if i == len(u.RTPPackets)-1 {
+			require.Equal(t, payload, out.(*unit.Generic).Payload)
+		} else {
+			require.Equal(t, []byte(nil), out.(*unit.Generic).Payload)
+		}
+	}
+}
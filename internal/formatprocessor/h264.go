@@ -10,6 +10,7 @@ import (
 	"github.com/bluenviron/mediacommon/pkg/codecs/h264"
 	"github.com/pion/rtp"
 
+	"github.com/bluenviron/mediamtx/internal/captions"
 	"github.com/bluenviron/mediamtx/internal/unit"
 )
 
@@ -218,6 +219,7 @@ func (t *formatProcessorH264) ProcessUnit(uu unit.Unit) error {
 
 	t.updateTrackParametersFromAU(u.AU)
 	u.AU = t.remuxAccessUnit(u.AU)
+	u.Captions = captions.FromH264(u.AU)
 
 	if u.AU != nil {
 		pkts, err := t.encoder.Encode(u.AU)
@@ -292,6 +294,7 @@ func (t *formatProcessorH264) ProcessRTPPacket( //nolint:dupl
 		}
 
 		u.AU = t.remuxAccessUnit(au)
+		u.Captions = captions.FromH264(u.AU)
 	}
 
 	// route packet as is
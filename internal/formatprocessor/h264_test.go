@@ -10,6 +10,7 @@ import (
 	"github.com/pion/rtp"
 	"github.com/stretchr/testify/require"
 
+	"github.com/bluenviron/mediamtx/internal/captions"
 	"github.com/bluenviron/mediamtx/internal/unit"
 )
 
@@ -201,6 +202,34 @@ func TestH264EmptyPacket(t *testing.T) {
 	require.Equal(t, []*rtp.Packet(nil), unit.RTPPackets)
 }
 
+func TestH264Captions(t *testing.T) {
+	forma := &format.H264{
+		PayloadTyp:        96,
+		PacketizationMode: 1,
+	}
+
+	p, err := New(1472, forma, true)
+	require.NoError(t, err)
+
+	unit := &unit.H264{
+		AU: [][]byte{
+			{
+				0x06, 0x04, 0x11, 0xb5, 0x00, 0x31, 'G', 'A', '9', '4', 0x03,
+				0x42, 0xff, 0xfc, 0x94, 0x20, 0xfc, 0x94, 0x20, 0xff, 0x80,
+			},
+			{0x05, 0x01},
+		},
+	}
+
+	err = p.ProcessUnit(unit)
+	require.NoError(t, err)
+
+	require.Equal(t, []captions.CCData{
+		{Type: captions.CCTypeNTSCField1, Data1: 0x94, Data2: 0x20},
+		{Type: captions.CCTypeNTSCField1, Data1: 0x94, Data2: 0x20},
+	}, unit.Captions)
+}
+
 func FuzzRTPH264ExtractParams(f *testing.F) {
 	f.Fuzz(func(t *testing.T, b []byte) {
 		rtpH264ExtractParams(b)
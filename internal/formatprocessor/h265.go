@@ -10,6 +10,7 @@ import (
 	"github.com/bluenviron/mediacommon/pkg/codecs/h265"
 	"github.com/pion/rtp"
 
+	"github.com/bluenviron/mediamtx/internal/captions"
 	"github.com/bluenviron/mediamtx/internal/unit"
 )
 
@@ -237,6 +238,7 @@ func (t *formatProcessorH265) ProcessUnit(uu unit.Unit) error { //nolint:dupl
 
 	t.updateTrackParametersFromAU(u.AU)
 	u.AU = t.remuxAccessUnit(u.AU)
+	u.Captions = captions.FromH265(u.AU)
 
 	if u.AU != nil {
 		pkts, err := t.encoder.Encode(u.AU)
@@ -311,6 +313,7 @@ func (t *formatProcessorH265) ProcessRTPPacket( //nolint:dupl
 		}
 
 		u.AU = t.remuxAccessUnit(au)
+		u.Captions = captions.FromH265(u.AU)
 	}
 
 	// route packet as is
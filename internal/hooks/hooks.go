@@ -1,2 +1,66 @@
 // Package hooks contains hook implementations.
 package hooks
+
+import (
+	"github.com/bluenviron/mediamtx/internal/externalcmd"
+	"github.com/bluenviron/mediamtx/internal/logger"
+	"github.com/bluenviron/mediamtx/internal/webhook"
+)
+
+// startHook starts a hook.
+// If the hook is an HTTP URL, a webhook is sent and no command is returned.
+func startHook(
+	l logger.Writer,
+	externalCmdPool *externalcmd.Pool,
+	webhookSender *webhook.Sender,
+	name string,
+	event string,
+	cmdstr string,
+	restart bool,
+	env externalcmd.Environment,
+) *externalcmd.Cmd {
+	if webhook.IsURL(cmdstr) {
+		l.Log(logger.Info, "%s webhook sent", name)
+		webhookSender.Send(cmdstr, event, env)
+		return nil
+	}
+
+	l.Log(logger.Info, "%s command started", name)
+	return externalcmd.NewCmd(
+		externalCmdPool,
+		name,
+		cmdstr,
+		restart,
+		env,
+		l,
+		func(err error) {
+			l.Log(logger.Info, "%s command exited: %v", name, err)
+		})
+}
+
+// launchHook launches a hook that is not restarted and not stopped.
+func launchHook(
+	l logger.Writer,
+	externalCmdPool *externalcmd.Pool,
+	webhookSender *webhook.Sender,
+	name string,
+	event string,
+	cmdstr string,
+	env externalcmd.Environment,
+) {
+	if webhook.IsURL(cmdstr) {
+		l.Log(logger.Info, "%s webhook sent", name)
+		webhookSender.Send(cmdstr, event, env)
+		return
+	}
+
+	l.Log(logger.Info, "%s command launched", name)
+	externalcmd.NewCmd(
+		externalCmdPool,
+		name,
+		cmdstr,
+		false,
+		env,
+		l,
+		nil)
+}
@@ -0,0 +1,56 @@
+package hooks
+
+import (
+	"net"
+
+	"github.com/bluenviron/mediamtx/internal/externalcmd"
+	"github.com/bluenviron/mediamtx/internal/logger"
+	"github.com/bluenviron/mediamtx/internal/webhook"
+)
+
+// OnAuthFailureParams are the parameters of OnAuthFailure.
+type OnAuthFailureParams struct {
+	Logger           logger.Writer
+	ExternalCmdPool  *externalcmd.Pool
+	WebhookSender    *webhook.Sender
+	RunOnAuthFailure string
+	RTSPAddress      string
+	Path             string
+	Query            string
+	IP               net.IP
+	User             string
+	Protocol         string
+	Publish          bool
+}
+
+// OnAuthFailure is the OnAuthFailure hook.
+func OnAuthFailure(params OnAuthFailureParams) {
+	if params.RunOnAuthFailure == "" {
+		return
+	}
+
+	_, port, _ := net.SplitHostPort(params.RTSPAddress)
+	env := externalcmd.Environment{
+		"RTSP_PORT":    port,
+		"MTX_PATH":     params.Path,
+		"MTX_QUERY":    params.Query,
+		"MTX_IP":       params.IP.String(),
+		"MTX_USER":     params.User,
+		"MTX_PROTOCOL": params.Protocol,
+		"MTX_ACTION": func() string {
+			if params.Publish {
+				return "publish"
+			}
+			return "read"
+		}(),
+	}
+
+	launchHook(
+		params.Logger,
+		params.ExternalCmdPool,
+		params.WebhookSender,
+		"runOnAuthFailure",
+		"authFailure",
+		params.RunOnAuthFailure,
+		env)
+}
@@ -6,12 +6,14 @@ import (
 	"github.com/bluenviron/mediamtx/internal/defs"
 	"github.com/bluenviron/mediamtx/internal/externalcmd"
 	"github.com/bluenviron/mediamtx/internal/logger"
+	"github.com/bluenviron/mediamtx/internal/webhook"
 )
 
 // OnConnectParams are the parameters of OnConnect.
 type OnConnectParams struct {
 	Logger              logger.Writer
 	ExternalCmdPool     *externalcmd.Pool
+	WebhookSender       *webhook.Sender
 	RunOnConnect        string
 	RunOnConnectRestart bool
 	RunOnDisconnect     string
@@ -34,16 +36,15 @@ func OnConnect(params OnConnectParams) func() {
 	}
 
 	if params.RunOnConnect != "" {
-		params.Logger.Log(logger.Info, "runOnConnect command started")
-
-		onConnectCmd = externalcmd.NewCmd(
+		onConnectCmd = startHook(
+			params.Logger,
 			params.ExternalCmdPool,
+			params.WebhookSender,
+			"runOnConnect",
+			"connect",
 			params.RunOnConnect,
 			params.RunOnConnectRestart,
-			env,
-			func(err error) {
-				params.Logger.Log(logger.Info, "runOnConnect command exited: %v", err)
-			})
+			env)
 	}
 
 	return func() {
@@ -53,13 +54,14 @@ func OnConnect(params OnConnectParams) func() {
 		}
 
 		if params.RunOnDisconnect != "" {
-			params.Logger.Log(logger.Info, "runOnDisconnect command launched")
-			externalcmd.NewCmd(
+			launchHook(
+				params.Logger,
 				params.ExternalCmdPool,
+				params.WebhookSender,
+				"runOnDisconnect",
+				"disconnect",
 				params.RunOnDisconnect,
-				false,
-				env,
-				nil)
+				env)
 		}
 	}
 }
@@ -4,12 +4,14 @@ import (
 	"github.com/bluenviron/mediamtx/internal/conf"
 	"github.com/bluenviron/mediamtx/internal/externalcmd"
 	"github.com/bluenviron/mediamtx/internal/logger"
+	"github.com/bluenviron/mediamtx/internal/webhook"
 )
 
 // OnDemandParams are the parameters of OnDemand.
 type OnDemandParams struct {
 	Logger          logger.Writer
 	ExternalCmdPool *externalcmd.Pool
+	WebhookSender   *webhook.Sender
 	Conf            *conf.Path
 	ExternalCmdEnv  externalcmd.Environment
 	Query           string
@@ -26,16 +28,15 @@ func OnDemand(params OnDemandParams) func(string) {
 	}
 
 	if params.Conf.RunOnDemand != "" {
-		params.Logger.Log(logger.Info, "runOnDemand command started")
-
-		onDemandCmd = externalcmd.NewCmd(
+		onDemandCmd = startHook(
+			params.Logger,
 			params.ExternalCmdPool,
+			params.WebhookSender,
+			"runOnDemand",
+			"demand",
 			params.Conf.RunOnDemand,
 			params.Conf.RunOnDemandRestart,
-			env,
-			func(err error) {
-				params.Logger.Log(logger.Info, "runOnDemand command exited: %v", err)
-			})
+			env)
 	}
 
 	return func(reason string) {
@@ -45,13 +46,14 @@ func OnDemand(params OnDemandParams) func(string) {
 		}
 
 		if params.Conf.RunOnUnDemand != "" {
-			params.Logger.Log(logger.Info, "runOnUnDemand command launched")
-			externalcmd.NewCmd(
+			launchHook(
+				params.Logger,
 				params.ExternalCmdPool,
+				params.WebhookSender,
+				"runOnUnDemand",
+				"unDemand",
 				params.Conf.RunOnUnDemand,
-				false,
-				env,
-				nil)
+				env)
 		}
 	}
 }
@@ -4,12 +4,14 @@ import (
 	"github.com/bluenviron/mediamtx/internal/conf"
 	"github.com/bluenviron/mediamtx/internal/externalcmd"
 	"github.com/bluenviron/mediamtx/internal/logger"
+	"github.com/bluenviron/mediamtx/internal/webhook"
 )
 
 // OnInitParams are the parameters of OnInit.
 type OnInitParams struct {
 	Logger          logger.Writer
 	ExternalCmdPool *externalcmd.Pool
+	WebhookSender   *webhook.Sender
 	Conf            *conf.Path
 	ExternalCmdEnv  externalcmd.Environment
 }
@@ -19,15 +21,15 @@ func OnInit(params OnInitParams) func() {
 	var onInitCmd *externalcmd.Cmd
 
 	if params.Conf.RunOnInit != "" {
-		params.Logger.Log(logger.Info, "runOnInit command started")
-		onInitCmd = externalcmd.NewCmd(
+		onInitCmd = startHook(
+			params.Logger,
 			params.ExternalCmdPool,
+			params.WebhookSender,
+			"runOnInit",
+			"init",
 			params.Conf.RunOnInit,
 			params.Conf.RunOnInitRestart,
-			params.ExternalCmdEnv,
-			func(err error) {
-				params.Logger.Log(logger.Info, "runOnInit command exited: %v", err)
-			})
+			params.ExternalCmdEnv)
 	}
 
 	return func() {
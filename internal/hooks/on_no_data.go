@@ -0,0 +1,41 @@
+package hooks
+
+import (
+	"github.com/bluenviron/mediamtx/internal/conf"
+	"github.com/bluenviron/mediamtx/internal/defs"
+	"github.com/bluenviron/mediamtx/internal/externalcmd"
+	"github.com/bluenviron/mediamtx/internal/logger"
+	"github.com/bluenviron/mediamtx/internal/webhook"
+)
+
+// OnNoDataParams are the parameters of OnNoData.
+type OnNoDataParams struct {
+	Logger          logger.Writer
+	ExternalCmdPool *externalcmd.Pool
+	WebhookSender   *webhook.Sender
+	Conf            *conf.Path
+	ExternalCmdEnv  externalcmd.Environment
+	Desc            defs.APIPathSourceOrReader
+	Tracks          string
+}
+
+// OnNoData is the OnNoData hook.
+func OnNoData(params OnNoDataParams) {
+	if params.Conf.RunOnNoData == "" {
+		return
+	}
+
+	env := params.ExternalCmdEnv
+	env["MTX_SOURCE_TYPE"] = params.Desc.Type
+	env["MTX_SOURCE_ID"] = params.Desc.ID
+	env["MTX_TRACKS"] = params.Tracks
+
+	launchHook(
+		params.Logger,
+		params.ExternalCmdPool,
+		params.WebhookSender,
+		"runOnNoData",
+		"noData",
+		params.Conf.RunOnNoData,
+		env)
+}
@@ -0,0 +1,49 @@
+package hooks
+
+import (
+	"github.com/bluenviron/mediamtx/internal/conf"
+	"github.com/bluenviron/mediamtx/internal/externalcmd"
+	"github.com/bluenviron/mediamtx/internal/logger"
+	"github.com/bluenviron/mediamtx/internal/webhook"
+)
+
+// OnPathCreateParams are the parameters of OnPathCreate.
+type OnPathCreateParams struct {
+	Logger          logger.Writer
+	ExternalCmdPool *externalcmd.Pool
+	WebhookSender   *webhook.Sender
+	Conf            *conf.Path
+	ExternalCmdEnv  externalcmd.Environment
+}
+
+// OnPathCreate is the OnPathCreate hook.
+// Hooks are launched only for paths created from a regular expression.
+func OnPathCreate(params OnPathCreateParams) func() {
+	if params.Conf.Regexp == nil {
+		return func() {}
+	}
+
+	if params.Conf.RunOnPathCreate != "" {
+		launchHook(
+			params.Logger,
+			params.ExternalCmdPool,
+			params.WebhookSender,
+			"runOnPathCreate",
+			"pathCreate",
+			params.Conf.RunOnPathCreate,
+			params.ExternalCmdEnv)
+	}
+
+	return func() {
+		if params.Conf.RunOnPathDestroy != "" {
+			launchHook(
+				params.Logger,
+				params.ExternalCmdPool,
+				params.WebhookSender,
+				"runOnPathDestroy",
+				"pathDestroy",
+				params.Conf.RunOnPathDestroy,
+				params.ExternalCmdEnv)
+		}
+	}
+}
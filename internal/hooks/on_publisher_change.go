@@ -0,0 +1,44 @@
+package hooks
+
+import (
+	"github.com/bluenviron/mediamtx/internal/conf"
+	"github.com/bluenviron/mediamtx/internal/defs"
+	"github.com/bluenviron/mediamtx/internal/externalcmd"
+	"github.com/bluenviron/mediamtx/internal/logger"
+	"github.com/bluenviron/mediamtx/internal/webhook"
+)
+
+// OnPublisherChangeParams are the parameters of OnPublisherChange.
+type OnPublisherChangeParams struct {
+	Logger          logger.Writer
+	ExternalCmdPool *externalcmd.Pool
+	WebhookSender   *webhook.Sender
+	Conf            *conf.Path
+	ExternalCmdEnv  externalcmd.Environment
+	PreviousDesc    defs.APIPathSourceOrReader
+	Desc            defs.APIPathSourceOrReader
+	Query           string
+}
+
+// OnPublisherChange is the OnPublisherChange hook.
+func OnPublisherChange(params OnPublisherChangeParams) {
+	if params.Conf.RunOnPublisherChange == "" {
+		return
+	}
+
+	env := params.ExternalCmdEnv
+	env["MTX_QUERY"] = params.Query
+	env["MTX_SOURCE_TYPE"] = params.Desc.Type
+	env["MTX_SOURCE_ID"] = params.Desc.ID
+	env["MTX_PREVIOUS_SOURCE_TYPE"] = params.PreviousDesc.Type
+	env["MTX_PREVIOUS_SOURCE_ID"] = params.PreviousDesc.ID
+
+	launchHook(
+		params.Logger,
+		params.ExternalCmdPool,
+		params.WebhookSender,
+		"runOnPublisherChange",
+		"publisherChange",
+		params.Conf.RunOnPublisherChange,
+		env)
+}
@@ -5,12 +5,14 @@ import (
 	"github.com/bluenviron/mediamtx/internal/defs"
 	"github.com/bluenviron/mediamtx/internal/externalcmd"
 	"github.com/bluenviron/mediamtx/internal/logger"
+	"github.com/bluenviron/mediamtx/internal/webhook"
 )
 
 // OnReadParams are the parameters of OnRead.
 type OnReadParams struct {
 	Logger          logger.Writer
 	ExternalCmdPool *externalcmd.Pool
+	WebhookSender   *webhook.Sender
 	Conf            *conf.Path
 	ExternalCmdEnv  externalcmd.Environment
 	Reader          defs.APIPathSourceOrReader
@@ -31,15 +33,15 @@ func OnRead(params OnReadParams) func() {
 	}
 
 	if params.Conf.RunOnRead != "" {
-		params.Logger.Log(logger.Info, "runOnRead command started")
-		onReadCmd = externalcmd.NewCmd(
+		onReadCmd = startHook(
+			params.Logger,
 			params.ExternalCmdPool,
+			params.WebhookSender,
+			"runOnRead",
+			"read",
 			params.Conf.RunOnRead,
 			params.Conf.RunOnReadRestart,
-			env,
-			func(err error) {
-				params.Logger.Log(logger.Info, "runOnRead command exited: %v", err)
-			})
+			env)
 	}
 
 	return func() {
@@ -49,13 +51,14 @@ func OnRead(params OnReadParams) func() {
 		}
 
 		if params.Conf.RunOnUnread != "" {
-			params.Logger.Log(logger.Info, "runOnUnread command launched")
-			externalcmd.NewCmd(
+			launchHook(
+				params.Logger,
 				params.ExternalCmdPool,
+				params.WebhookSender,
+				"runOnUnread",
+				"unread",
 				params.Conf.RunOnUnread,
-				false,
-				env,
-				nil)
+				env)
 		}
 	}
 }
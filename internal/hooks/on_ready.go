@@ -5,12 +5,14 @@ import (
 	"github.com/bluenviron/mediamtx/internal/defs"
 	"github.com/bluenviron/mediamtx/internal/externalcmd"
 	"github.com/bluenviron/mediamtx/internal/logger"
+	"github.com/bluenviron/mediamtx/internal/webhook"
 )
 
 // OnReadyParams are the parameters of OnReady.
 type OnReadyParams struct {
 	Logger          logger.Writer
 	ExternalCmdPool *externalcmd.Pool
+	WebhookSender   *webhook.Sender
 	Conf            *conf.Path
 	ExternalCmdEnv  externalcmd.Environment
 	Desc            defs.APIPathSourceOrReader
@@ -30,15 +32,15 @@ func OnReady(params OnReadyParams) func() {
 	}
 
 	if params.Conf.RunOnReady != "" {
-		params.Logger.Log(logger.Info, "runOnReady command started")
-		onReadyCmd = externalcmd.NewCmd(
+		onReadyCmd = startHook(
+			params.Logger,
 			params.ExternalCmdPool,
+			params.WebhookSender,
+			"runOnReady",
+			"ready",
 			params.Conf.RunOnReady,
 			params.Conf.RunOnReadyRestart,
-			env,
-			func(err error) {
-				params.Logger.Log(logger.Info, "runOnReady command exited: %v", err)
-			})
+			env)
 	}
 
 	return func() {
@@ -48,13 +50,14 @@ func OnReady(params OnReadyParams) func() {
 		}
 
 		if params.Conf.RunOnNotReady != "" {
-			params.Logger.Log(logger.Info, "runOnNotReady command launched")
-			externalcmd.NewCmd(
+			launchHook(
+				params.Logger,
 				params.ExternalCmdPool,
+				params.WebhookSender,
+				"runOnNotReady",
+				"notReady",
 				params.Conf.RunOnNotReady,
-				false,
-				env,
-				nil)
+				env)
 		}
 	}
 }
@@ -0,0 +1,70 @@
+package hooks
+
+import (
+	"github.com/bluenviron/mediamtx/internal/conf"
+	"github.com/bluenviron/mediamtx/internal/externalcmd"
+	"github.com/bluenviron/mediamtx/internal/logger"
+	"github.com/bluenviron/mediamtx/internal/webhook"
+)
+
+// OnRecordSegmentParams are the parameters of OnRecordSegmentCreate,
+// OnRecordSegmentComplete and OnRecordSegmentDelete.
+type OnRecordSegmentParams struct {
+	Logger          logger.Writer
+	ExternalCmdPool *externalcmd.Pool
+	WebhookSender   *webhook.Sender
+	Conf            *conf.Path
+	ExternalCmdEnv  externalcmd.Environment
+	SegmentPath     string
+}
+
+// OnRecordSegmentCreate is the OnRecordSegmentCreate hook.
+func OnRecordSegmentCreate(params OnRecordSegmentParams) {
+	if params.Conf.RunOnRecordSegmentCreate != "" {
+		env := params.ExternalCmdEnv
+		env["MTX_SEGMENT_PATH"] = params.SegmentPath
+
+		launchHook(
+			params.Logger,
+			params.ExternalCmdPool,
+			params.WebhookSender,
+			"runOnRecordSegmentCreate",
+			"recordSegmentCreate",
+			params.Conf.RunOnRecordSegmentCreate,
+			env)
+	}
+}
+
+// OnRecordSegmentComplete is the OnRecordSegmentComplete hook.
+func OnRecordSegmentComplete(params OnRecordSegmentParams) {
+	if params.Conf.RunOnRecordSegmentComplete != "" {
+		env := params.ExternalCmdEnv
+		env["MTX_SEGMENT_PATH"] = params.SegmentPath
+
+		launchHook(
+			params.Logger,
+			params.ExternalCmdPool,
+			params.WebhookSender,
+			"runOnRecordSegmentComplete",
+			"recordSegmentComplete",
+			params.Conf.RunOnRecordSegmentComplete,
+			env)
+	}
+}
+
+// OnRecordSegmentDelete is the OnRecordSegmentDelete hook.
+func OnRecordSegmentDelete(params OnRecordSegmentParams) {
+	if params.Conf.RunOnRecordSegmentDelete != "" {
+		env := params.ExternalCmdEnv
+		env["MTX_SEGMENT_PATH"] = params.SegmentPath
+
+		launchHook(
+			params.Logger,
+			params.ExternalCmdPool,
+			params.WebhookSender,
+			"runOnRecordSegmentDelete",
+			"recordSegmentDelete",
+			params.Conf.RunOnRecordSegmentDelete,
+			env)
+	}
+}
@@ -0,0 +1,55 @@
+package hooks
+
+import (
+	"strconv"
+
+	"github.com/bluenviron/mediamtx/internal/conf"
+	"github.com/bluenviron/mediamtx/internal/externalcmd"
+	"github.com/bluenviron/mediamtx/internal/logger"
+	"github.com/bluenviron/mediamtx/internal/webhook"
+)
+
+// OnSilenceParams are the parameters of OnSilence.
+type OnSilenceParams struct {
+	Logger          logger.Writer
+	ExternalCmdPool *externalcmd.Pool
+	WebhookSender   *webhook.Sender
+	Conf            *conf.Path
+	ExternalCmdEnv  externalcmd.Environment
+	Track           int
+	Codec           string
+	Level           float64
+	Silent          bool
+}
+
+// OnSilence is the OnSilence hook.
+// It launches runOnSilence when a track becomes silent and runOnSound when it isn't silent anymore.
+func OnSilence(params OnSilenceParams) {
+	name := "runOnSound"
+	event := "sound"
+	cmd := params.Conf.RunOnSound
+
+	if params.Silent {
+		name = "runOnSilence"
+		event = "silence"
+		cmd = params.Conf.RunOnSilence
+	}
+
+	if cmd == "" {
+		return
+	}
+
+	env := params.ExternalCmdEnv
+	env["MTX_TRACK"] = strconv.FormatInt(int64(params.Track), 10)
+	env["MTX_TRACK_CODEC"] = params.Codec
+	env["MTX_AUDIO_LEVEL"] = strconv.FormatFloat(params.Level, 'f', 1, 64)
+
+	launchHook(
+		params.Logger,
+		params.ExternalCmdPool,
+		params.WebhookSender,
+		name,
+		event,
+		cmd,
+		env)
+}
@@ -0,0 +1,53 @@
+package hooks
+
+import (
+	"strconv"
+
+	"github.com/bluenviron/mediamtx/internal/conf"
+	"github.com/bluenviron/mediamtx/internal/externalcmd"
+	"github.com/bluenviron/mediamtx/internal/logger"
+	"github.com/bluenviron/mediamtx/internal/webhook"
+)
+
+// OnStatsThresholdParams are the parameters of OnStatsThreshold.
+type OnStatsThresholdParams struct {
+	Logger          logger.Writer
+	ExternalCmdPool *externalcmd.Pool
+	WebhookSender   *webhook.Sender
+	Conf            *conf.Path
+	ExternalCmdEnv  externalcmd.Environment
+	Track           int
+	Codec           string
+	Stat            string
+	Value           string
+	Threshold       string
+	Exceeded        bool
+}
+
+// OnStatsThreshold is the OnStatsThreshold hook.
+func OnStatsThreshold(params OnStatsThresholdParams) {
+	if params.Conf.RunOnStatsThreshold == "" {
+		return
+	}
+
+	env := params.ExternalCmdEnv
+	env["MTX_TRACK"] = strconv.FormatInt(int64(params.Track), 10)
+	env["MTX_TRACK_CODEC"] = params.Codec
+	env["MTX_STAT"] = params.Stat
+	env["MTX_STAT_VALUE"] = params.Value
+	env["MTX_STAT_THRESHOLD"] = params.Threshold
+	if params.Exceeded {
+		env["MTX_STAT_STATE"] = "exceeded"
+	} else {
+		env["MTX_STAT_STATE"] = "restored"
+	}
+
+	launchHook(
+		params.Logger,
+		params.ExternalCmdPool,
+		params.WebhookSender,
+		"runOnStatsThreshold",
+		"statsThreshold",
+		params.Conf.RunOnStatsThreshold,
+		env)
+}
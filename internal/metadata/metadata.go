@@ -0,0 +1,58 @@
+// Package metadata contains utilities to handle metadata tracks (KLV, SCTE-35).
+package metadata
+
+import (
+	"github.com/bluenviron/gortsplib/v4/pkg/format"
+)
+
+const (
+	// KLVRTPMap is the RTP map of KLV tracks.
+	// Specification: RFC 6597
+	KLVRTPMap = "smpte336m/90000"
+
+	// SCTE35RTPMap is the RTP map of SCTE-35 tracks.
+	// There's no standard RTP payload format for SCTE-35, splice information sections are
+	// transmitted as they are, in the same way as KLV units.
+	SCTE35RTPMap = "x-scte35/90000"
+)
+
+func newFormat(rtpMap string) *format.Generic {
+	forma := &format.Generic{
+		PayloadTyp: 96,
+		RTPMa:      rtpMap,
+	}
+	forma.Init() //nolint:errcheck
+	return forma
+}
+
+// NewKLVFormat allocates a KLV format.
+func NewKLVFormat() *format.Generic {
+	return newFormat(KLVRTPMap)
+}
+
+// NewSCTE35Format allocates a SCTE-35 format.
+func NewSCTE35Format() *format.Generic {
+	return newFormat(SCTE35RTPMap)
+}
+
+func rtpMapIs(forma format.Format, rtpMap string) bool {
+	if _, ok := forma.(*format.Generic); !ok {
+		return false
+	}
+	return forma.RTPMap() == rtpMap
+}
+
+// IsKLV checks whether a format is a KLV format.
+func IsKLV(forma format.Format) bool {
+	return rtpMapIs(forma, KLVRTPMap)
+}
+
+// IsSCTE35 checks whether a format is a SCTE-35 format.
+func IsSCTE35(forma format.Format) bool {
+	return rtpMapIs(forma, SCTE35RTPMap)
+}
+
+// IsMetadata checks whether a format is a metadata format.
+func IsMetadata(forma format.Format) bool {
+	return IsKLV(forma) || IsSCTE35(forma)
+}
@@ -0,0 +1,69 @@
+package metadata
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/pion/rtp"
+)
+
+// maximum size of a metadata unit.
+const unitMaxSize = 1 * 1024 * 1024
+
+// ErrMorePacketsNeeded is returned when more packets are needed.
+var ErrMorePacketsNeeded = errors.New("need more packets")
+
+// RTPDecoder is a RTP decoder for metadata units.
+// Specification: RFC 6597
+type RTPDecoder struct {
+	fragments     [][]byte
+	fragmentsSize int
+	timestamp     uint32
+}
+
+// Init initializes the decoder.
+func (d *RTPDecoder) Init() error {
+	return nil
+}
+
+func (d *RTPDecoder) resetFragments() {
+	d.fragments = d.fragments[:0]
+	d.fragmentsSize = 0
+}
+
+// Decode decodes a unit from a RTP packet.
+func (d *RTPDecoder) Decode(pkt *rtp.Packet) ([]byte, error) {
+	// packets of a unit share the same timestamp.
+	// when it changes, the previous unit is incomplete.
+	if d.fragmentsSize != 0 && pkt.Timestamp != d.timestamp {
+		d.resetFragments()
+	}
+
+	if pkt.Marker && d.fragmentsSize == 0 {
+		return pkt.Payload, nil
+	}
+
+	d.fragmentsSize += len(pkt.Payload)
+	if d.fragmentsSize > unitMaxSize {
+		errSize := d.fragmentsSize
+		d.resetFragments()
+		return nil, fmt.Errorf("unit size (%d) is too big, maximum is %d", errSize, unitMaxSize)
+	}
+
+	d.fragments = append(d.fragments, pkt.Payload)
+	d.timestamp = pkt.Timestamp
+
+	if !pkt.Marker {
+		return nil, ErrMorePacketsNeeded
+	}
+
+	ret := make([]byte, d.fragmentsSize)
+	n := 0
+	for _, p := range d.fragments {
+		n += copy(ret[n:], p)
+	}
+
+	d.resetFragments()
+
+	return ret, nil
+}
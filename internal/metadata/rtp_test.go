@@ -0,0 +1,43 @@
+package metadata
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRTPEncodeDecode(t *testing.T) {
+	payload := bytes.Repeat([]byte{1, 2, 3, 4}, 1000)
+
+	enc := &RTPEncoder{
+		PayloadType:    96,
+		PayloadMaxSize: 1000,
+	}
+	err := enc.Init()
+	require.NoError(t, err)
+
+	pkts, err := enc.Encode(payload)
+	require.NoError(t, err)
+	require.Equal(t, 4, len(pkts))
+
+	for i, pkt := range pkts {
+		require.Equal(t, i == len(pkts)-1, pkt.Marker)
+	}
+
+	dec := &RTPDecoder{}
+	err = dec.Init()
+	require.NoError(t, err)
+
+	for i, pkt := range pkts {
+		var out []byte
+		out, err = dec.Decode(pkt)
+
+		if i != len(pkts)-1 {
+			require.Equal(t, ErrMorePacketsNeeded, err)
+		} else {
+			require.NoError(t, err)
+			require.Equal(t, payload, out)
+		}
+	}
+}
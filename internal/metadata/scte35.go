@@ -0,0 +1,217 @@
+package metadata
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+const (
+	scte35TableID = 0xFC
+
+	// PTS values are 33-bit wide.
+	ptsMask = 1<<33 - 1
+)
+
+// SCTE35CommandType is the type of a splice command.
+type SCTE35CommandType uint8
+
+// splice command types.
+const (
+	SCTE35CommandTypeSpliceNull           SCTE35CommandType = 0x00
+	SCTE35CommandTypeSpliceSchedule       SCTE35CommandType = 0x04
+	SCTE35CommandTypeSpliceInsert         SCTE35CommandType = 0x05
+	SCTE35CommandTypeTimeSignal           SCTE35CommandType = 0x06
+	SCTE35CommandTypeBandwidthReservation SCTE35CommandType = 0x07
+	SCTE35CommandTypePrivateCommand       SCTE35CommandType = 0xFF
+)
+
+// SCTE35 is a SCTE-35 splice information section.
+// Specification: ANSI/SCTE 35, 9.6 Splice Info Section
+type SCTE35 struct {
+	PTSAdjustment int64
+	CommandType   SCTE35CommandType
+
+	// splice_insert() fields.
+	EventID      uint32
+	Cancel       bool
+	OutOfNetwork bool
+
+	// time of the splice, with the PTS adjustment applied.
+	// It is nil when the splice is immediate.
+	SpliceTime *int64
+
+	// duration of the break, in 90kHz units.
+	BreakDuration *int64
+}
+
+// Unmarshal decodes a splice information section.
+func (s *SCTE35) Unmarshal(buf []byte) error {
+	if len(buf) < 14 {
+		return fmt.Errorf("buffer is too short")
+	}
+
+	if buf[0] != scte35TableID {
+		return fmt.Errorf("invalid table ID: %d", buf[0])
+	}
+
+	sectionLength := int(buf[1]&0x0F)<<8 | int(buf[2])
+	if len(buf) < 3+sectionLength {
+		return fmt.Errorf("buffer is too short")
+	}
+
+	encrypted := (buf[4] & 0x80) != 0
+	if encrypted {
+		return fmt.Errorf("encrypted sections are not supported")
+	}
+
+	s.PTSAdjustment = int64(buf[4]&0x01)<<32 | int64(binary.BigEndian.Uint32(buf[5:9]))
+	commandLength := int(buf[11]&0x0F)<<8 | int(buf[12])
+	s.CommandType = SCTE35CommandType(buf[13])
+
+	cmd := buf[14 : 3+sectionLength]
+
+	// 0xFFF is used by legacy encoders when the length is unknown
+	if commandLength != 0xFFF {
+		if len(cmd) < commandLength {
+			return fmt.Errorf("buffer is too short")
+		}
+		cmd = cmd[:commandLength]
+	}
+
+	switch s.CommandType {
+	case SCTE35CommandTypeSpliceInsert:
+		return s.unmarshalSpliceInsert(cmd)
+
+	case SCTE35CommandTypeTimeSignal:
+		t, _, err := s.unmarshalSpliceTime(cmd)
+		if err != nil {
+			return err
+		}
+		s.SpliceTime = t
+	}
+
+	return nil
+}
+
+func (s *SCTE35) unmarshalSpliceInsert(buf []byte) error {
+	if len(buf) < 5 {
+		return fmt.Errorf("buffer is too short")
+	}
+
+	s.EventID = binary.BigEndian.Uint32(buf[0:4])
+	s.Cancel = (buf[4] & 0x80) != 0
+	buf = buf[5:]
+
+	if s.Cancel {
+		return nil
+	}
+
+	if len(buf) < 1 {
+		return fmt.Errorf("buffer is too short")
+	}
+
+	s.OutOfNetwork = (buf[0] & 0x80) != 0
+	programSplice := (buf[0] & 0x40) != 0
+	hasDuration := (buf[0] & 0x20) != 0
+	immediate := (buf[0] & 0x10) != 0
+	buf = buf[1:]
+
+	if programSplice {
+		if !immediate {
+			t, n, err := s.unmarshalSpliceTime(buf)
+			if err != nil {
+				return err
+			}
+			s.SpliceTime = t
+			buf = buf[n:]
+		}
+	} else {
+		if len(buf) < 1 {
+			return fmt.Errorf("buffer is too short")
+		}
+		componentCount := int(buf[0])
+		buf = buf[1:]
+
+		for i := 0; i < componentCount; i++ {
+			if len(buf) < 1 {
+				return fmt.Errorf("buffer is too short")
+			}
+			buf = buf[1:]
+
+			if !immediate {
+				_, n, err := s.unmarshalSpliceTime(buf)
+				if err != nil {
+					return err
+				}
+				buf = buf[n:]
+			}
+		}
+	}
+
+	if hasDuration {
+		if len(buf) < 5 {
+			return fmt.Errorf("buffer is too short")
+		}
+		v := int64(buf[0]&0x01)<<32 | int64(binary.BigEndian.Uint32(buf[1:5]))
+		s.BreakDuration = &v
+	}
+
+	return nil
+}
+
+func (s *SCTE35) unmarshalSpliceTime(buf []byte) (*int64, int, error) {
+	if len(buf) < 1 {
+		return nil, 0, fmt.Errorf("buffer is too short")
+	}
+
+	timeSpecified := (buf[0] & 0x80) != 0
+	if !timeSpecified {
+		return nil, 1, nil
+	}
+
+	if len(buf) < 5 {
+		return nil, 0, fmt.Errorf("buffer is too short")
+	}
+
+	v := int64(buf[0]&0x01)<<32 | int64(binary.BigEndian.Uint32(buf[1:5]))
+	v = (v + s.PTSAdjustment) & ptsMask
+
+	return &v, 5, nil
+}
+
+// CRC32MPEG2 computes the CRC of MPEG-TS sections (CRC-32/MPEG-2).
+func CRC32MPEG2(buf []byte) uint32 {
+	crc := uint32(0xFFFFFFFF)
+	for _, b := range buf {
+		crc ^= uint32(b) << 24
+		for i := 0; i < 8; i++ {
+			if (crc & 0x80000000) != 0 {
+				crc = (crc << 1) ^ 0x04C11DB7
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
+
+// SCTE35AddPTSAdjustment returns a copy of a splice information section
+// with delta (in 90kHz units) added to pts_adjustment and the CRC updated.
+// It allows to shift splice times together with timestamps of the stream.
+// Buffers that do not contain a complete section are returned unchanged.
+func SCTE35AddPTSAdjustment(buf []byte, delta int64) []byte {
+	if len(buf) < 18 || buf[0] != scte35TableID ||
+		(3+(int(buf[1]&0x0F)<<8|int(buf[2]))) != len(buf) {
+		return buf
+	}
+
+	v := int64(buf[4]&0x01)<<32 | int64(binary.BigEndian.Uint32(buf[5:]))
+	v = (v + delta) & ptsMask
+
+	buf = append([]byte(nil), buf...)
+	buf[4] = (buf[4] & 0xFE) | byte(v>>32)
+	binary.BigEndian.PutUint32(buf[5:], uint32(v))
+	binary.BigEndian.PutUint32(buf[len(buf)-4:], CRC32MPEG2(buf[:len(buf)-4]))
+
+	return buf
+}
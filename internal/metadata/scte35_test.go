@@ -0,0 +1,92 @@
+package metadata
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func int64Ptr(v int64) *int64 {
+	return &v
+}
+
+func TestSCTE35Unmarshal(t *testing.T) {
+	for _, ca := range []struct {
+		name string
+		enc  string
+		dec  SCTE35
+	}{
+		{
+			"splice insert",
+			"/DAvAAAAAAAA///wFAVIAACPf+/+c2nALv4AUsz1AAAAAAAKAAhDVUVJAAABNWLbowo=",
+			SCTE35{
+				CommandType:   SCTE35CommandTypeSpliceInsert,
+				EventID:       0x4800008f,
+				OutOfNetwork:  true,
+				SpliceTime:    int64Ptr(0x07369c02e),
+				BreakDuration: int64Ptr(0x00052ccf5),
+			},
+		},
+		{
+			"time signal",
+			"/DA0AAAAAAAA///wBQb+cr0AUAAeAhxDVUVJSAAAjn/PAAGlmbAICAAAAAAsoKGKNAIAmsnRfg==",
+			SCTE35{
+				CommandType: SCTE35CommandTypeTimeSignal,
+				SpliceTime:  int64Ptr(0x072bd0050),
+			},
+		},
+	} {
+		t.Run(ca.name, func(t *testing.T) {
+			buf, err := base64.StdEncoding.DecodeString(ca.enc)
+			require.NoError(t, err)
+
+			var dec SCTE35
+			err = dec.Unmarshal(buf)
+			require.NoError(t, err)
+			require.Equal(t, ca.dec, dec)
+		})
+	}
+}
+
+func TestSCTE35AddPTSAdjustment(t *testing.T) {
+	buf, err := base64.StdEncoding.DecodeString(
+		"/DAvAAAAAAAA///wFAVIAACPf+/+c2nALv4AUsz1AAAAAAAKAAhDVUVJAAABNWLbowo=")
+	require.NoError(t, err)
+
+	for _, ca := range []struct {
+		name  string
+		delta int64
+		time  int64
+	}{
+		{
+			"forward",
+			90000,
+			0x07369c02e + 90000,
+		},
+		{
+			"backward",
+			-90000,
+			0x07369c02e - 90000,
+		},
+		{
+			"wrap around",
+			1<<33 - 0x07369c02e,
+			0,
+		},
+	} {
+		t.Run(ca.name, func(t *testing.T) {
+			out := SCTE35AddPTSAdjustment(buf, ca.delta)
+			require.Equal(t, uint32(0), CRC32MPEG2(out))
+
+			var dec SCTE35
+			err = dec.Unmarshal(out)
+			require.NoError(t, err)
+			require.Equal(t, ca.time, *dec.SpliceTime)
+		})
+	}
+
+	t.Run("incomplete section", func(t *testing.T) {
+		require.Equal(t, buf[:20], SCTE35AddPTSAdjustment(buf[:20], 90000))
+	})
+}
@@ -26,6 +26,10 @@ func metric(key string, tags string, value int64) string {
 	return key + tags + " " + strconv.FormatInt(value, 10) + "\n"
 }
 
+func metricFloat(key string, tags string, value float64) string {
+	return key + tags + " " + strconv.FormatFloat(value, 'f', -1, 64) + "\n"
+}
+
 type metricsParent interface {
 	logger.Writer
 }
@@ -104,6 +108,23 @@ func (m *Metrics) onMetrics(ctx *gin.Context) {
 			out += metric("paths", tags, 1)
 			out += metric("paths_bytes_received", tags, int64(i.BytesReceived))
 			out += metric("paths_bytes_sent", tags, int64(i.BytesSent))
+			out += metric("paths_timeshift_buffer_bytes", tags, int64(i.TimeshiftBufferBytes))
+
+			for j, st := range i.TrackStats {
+				ttags := "{name=\"" + i.Name + "\",state=\"" + state + "\",track=\"" +
+					strconv.FormatInt(int64(j), 10) + "\",codec=\"" + st.Codec + "\"}"
+				out += metric("paths_track_bitrate_1s", ttags, int64(st.Bitrate1s))
+				out += metric("paths_track_bitrate_10s", ttags, int64(st.Bitrate10s))
+				out += metricFloat("paths_track_fps", ttags, st.FPS)
+				out += metric("paths_track_gop_length", ttags, int64(st.GOPLength))
+				out += metricFloat("paths_track_gop_duration", ttags, st.GOPDuration)
+				out += metric("paths_track_max_frame_size", ttags, int64(st.MaxFrameSize))
+				out += metric("paths_track_decode_errors", ttags, int64(st.DecodeErrors))
+				if st.RMSLevel != nil {
+					out += metricFloat("paths_track_audio_rms_level", ttags, *st.RMSLevel)
+					out += metricFloat("paths_track_audio_peak_level", ttags, *st.PeakLevel)
+				}
+			}
 		}
 	} else {
 		out += metric("paths", "", 0)
@@ -0,0 +1,251 @@
+package offlinesource
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/bluenviron/gortsplib/v4/pkg/description"
+	"github.com/bluenviron/gortsplib/v4/pkg/format"
+	"github.com/bluenviron/mediacommon/pkg/codecs/av1"
+	"github.com/bluenviron/mediacommon/pkg/codecs/h264"
+	"github.com/bluenviron/mediacommon/pkg/formats/fmp4"
+
+	"github.com/bluenviron/mediamtx/internal/unit"
+)
+
+func durationMp4ToGo(v int64, timeScale uint32) time.Duration {
+	timeScale64 := int64(timeScale)
+	secs := v / timeScale64
+	dec := v % timeScale64
+	return time.Duration(secs)*time.Second + time.Duration(dec)*time.Second/time.Duration(timeScale64)
+}
+
+type fmp4Track struct {
+	mediaIndex int
+	timeScale  uint32
+	newUnit    func(base unit.Base, payload []byte) (unit.Unit, error)
+}
+
+type fmp4Sample struct {
+	track   *fmp4Track
+	dts     time.Duration
+	pts     time.Duration
+	payload []byte
+}
+
+func fmp4NewTrack(codec fmp4.Codec) (*description.Media, *fmp4Track) {
+	track := &fmp4Track{}
+	var medi *description.Media
+
+	switch codec := codec.(type) {
+	case *fmp4.CodecAV1:
+		medi = &description.Media{
+			Type: description.MediaTypeVideo,
+			Formats: []format.Format{&format.AV1{
+				PayloadTyp: 96,
+			}},
+		}
+
+		track.newUnit = func(base unit.Base, payload []byte) (unit.Unit, error) {
+			tu, err := av1.BitstreamUnmarshal(payload, true)
+			if err != nil {
+				return nil, err
+			}
+			return &unit.AV1{Base: base, TU: tu}, nil
+		}
+
+	case *fmp4.CodecVP9:
+		medi = &description.Media{
+			Type: description.MediaTypeVideo,
+			Formats: []format.Format{&format.VP9{
+				PayloadTyp: 96,
+			}},
+		}
+
+		track.newUnit = func(base unit.Base, payload []byte) (unit.Unit, error) {
+			return &unit.VP9{Base: base, Frame: payload}, nil
+		}
+
+	case *fmp4.CodecH265:
+		medi = &description.Media{
+			Type: description.MediaTypeVideo,
+			Formats: []format.Format{&format.H265{
+				PayloadTyp: 96,
+				VPS:        codec.VPS,
+				SPS:        codec.SPS,
+				PPS:        codec.PPS,
+			}},
+		}
+
+		track.newUnit = func(base unit.Base, payload []byte) (unit.Unit, error) {
+			au, err := h264.AVCCUnmarshal(payload)
+			if err != nil {
+				return nil, err
+			}
+			return &unit.H265{Base: base, AU: au}, nil
+		}
+
+	case *fmp4.CodecH264:
+		medi = &description.Media{
+			Type: description.MediaTypeVideo,
+			Formats: []format.Format{&format.H264{
+				PayloadTyp:        96,
+				SPS:               codec.SPS,
+				PPS:               codec.PPS,
+				PacketizationMode: 1,
+			}},
+		}
+
+		track.newUnit = func(base unit.Base, payload []byte) (unit.Unit, error) {
+			au, err := h264.AVCCUnmarshal(payload)
+			if err != nil {
+				return nil, err
+			}
+			return &unit.H264{Base: base, AU: au}, nil
+		}
+
+	case *fmp4.CodecOpus:
+		medi = &description.Media{
+			Type: description.MediaTypeAudio,
+			Formats: []format.Format{&format.Opus{
+				PayloadTyp: 96,
+				IsStereo:   (codec.ChannelCount >= 2),
+			}},
+		}
+
+		track.newUnit = func(base unit.Base, payload []byte) (unit.Unit, error) {
+			return &unit.Opus{Base: base, Packets: [][]byte{payload}}, nil
+		}
+
+	case *fmp4.CodecMPEG4Audio:
+		medi = &description.Media{
+			Type: description.MediaTypeAudio,
+			Formats: []format.Format{&format.MPEG4Audio{
+				PayloadTyp:       96,
+				SizeLength:       13,
+				IndexLength:      3,
+				IndexDeltaLength: 3,
+				Config:           &codec.Config,
+			}},
+		}
+
+		track.newUnit = func(base unit.Base, payload []byte) (unit.Unit, error) {
+			return &unit.MPEG4Audio{Base: base, AUs: [][]byte{payload}}, nil
+		}
+
+	default:
+		return nil, nil
+	}
+
+	return medi, track
+}
+
+type fmp4File struct {
+	fMedias  []*description.Media
+	samples  []*fmp4Sample
+	fileDur  time.Duration
+	startDTS time.Duration
+}
+
+func (f *fmp4File) initialize(byts []byte) error {
+	var init fmp4.Init
+	err := init.Unmarshal(bytes.NewReader(byts))
+	if err != nil {
+		return err
+	}
+
+	tracks := make(map[int]*fmp4Track)
+
+	for _, initTrack := range init.Tracks {
+		medi, track := fmp4NewTrack(initTrack.Codec)
+		if medi == nil {
+			continue
+		}
+
+		track.mediaIndex = len(f.fMedias)
+		track.timeScale = initTrack.TimeScale
+		tracks[initTrack.ID] = track
+		f.fMedias = append(f.fMedias, medi)
+	}
+
+	if len(f.fMedias) == 0 {
+		return fmt.Errorf("no supported tracks found (supported are AV1, VP9, H265, H264, Opus, MPEG-4 Audio)")
+	}
+
+	var parts fmp4.Parts
+	err = parts.Unmarshal(byts)
+	if err != nil {
+		return err
+	}
+
+	first := true
+
+	for _, part := range parts {
+		for _, partTrack := range part.Tracks {
+			track, ok := tracks[partTrack.ID]
+			if !ok {
+				continue
+			}
+
+			dts := int64(partTrack.BaseTime)
+
+			for _, sample := range partTrack.Samples {
+				s := &fmp4Sample{
+					track:   track,
+					dts:     durationMp4ToGo(dts, track.timeScale),
+					pts:     durationMp4ToGo(dts+int64(sample.PTSOffset), track.timeScale),
+					payload: sample.Payload,
+				}
+				f.samples = append(f.samples, s)
+
+				if first || s.dts < f.startDTS {
+					f.startDTS = s.dts
+					first = false
+				}
+
+				dts += int64(sample.Duration)
+
+				if end := durationMp4ToGo(dts, track.timeScale); end > f.fileDur {
+					f.fileDur = end
+				}
+			}
+		}
+	}
+
+	if len(f.samples) == 0 {
+		return fmt.Errorf("no samples found")
+	}
+
+	sort.SliceStable(f.samples, func(i, j int) bool {
+		return f.samples[i].dts < f.samples[j].dts
+	})
+
+	f.fileDur -= f.startDTS
+
+	return nil
+}
+
+func (f *fmp4File) medias() []*description.Media {
+	return f.fMedias
+}
+
+func (f *fmp4File) read(cb func(int, unit.Unit) error) (time.Duration, error) {
+	for _, sample := range f.samples {
+		u, err := sample.track.newUnit(unit.Base{
+			NTP: time.Now(),
+			PTS: sample.pts - f.startDTS,
+		}, sample.payload)
+		if err != nil {
+			return 0, err
+		}
+
+		err = cb(sample.track.mediaIndex, u)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return f.fileDur, nil
+}
@@ -0,0 +1,121 @@
+package offlinesource
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/bluenviron/gortsplib/v4/pkg/description"
+
+	"github.com/bluenviron/mediamtx/internal/protocols/mpegts"
+	"github.com/bluenviron/mediamtx/internal/unit"
+)
+
+// eofReader is a reader that remembers whether the end of the underlying reader has been reached.
+type eofReader struct {
+	r   io.Reader
+	eof bool
+}
+
+func (r *eofReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if err == io.EOF {
+		r.eof = true
+	}
+	return n, err
+}
+
+type mpegtsFile struct {
+	byts    []byte
+	fMedias []*description.Media
+}
+
+func (f *mpegtsFile) initialize(byts []byte) error {
+	f.byts = byts
+
+	r, err := mpegts.NewReader(bytes.NewReader(byts))
+	if err != nil {
+		return err
+	}
+
+	f.fMedias, err = mpegts.ToUnits(r, func(_ *description.Media, _ unit.Unit) error {
+		return nil
+	})
+	return err
+}
+
+func (f *mpegtsFile) medias() []*description.Media {
+	return f.fMedias
+}
+
+func (f *mpegtsFile) read(cb func(int, unit.Unit) error) (time.Duration, error) {
+	er := &eofReader{r: bytes.NewReader(f.byts)}
+
+	r, err := mpegts.NewReader(er)
+	if err != nil {
+		return 0, err
+	}
+
+	mediaIndexes := make(map[*description.Media]int)
+	var dc durationCounter
+
+	medias, err := mpegts.ToUnits(r, func(medi *description.Media, u unit.Unit) error {
+		i := mediaIndexes[medi]
+		dc.add(i, u.GetPTS())
+		return cb(i, u)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if len(medias) != len(f.fMedias) {
+		return 0, fmt.Errorf("file has changed")
+	}
+
+	for i, medi := range medias {
+		mediaIndexes[medi] = i
+	}
+
+	for {
+		err := r.Read()
+		if err != nil {
+			if er.eof {
+				break
+			}
+			return 0, err
+		}
+	}
+
+	return dc.duration(), nil
+}
+
+// durationCounter estimates the duration of a file that has no explicit duration,
+// by adding the average unit duration of the first media to the maximum PTS.
+type durationCounter struct {
+	maxPTS     time.Duration
+	firstPTS   time.Duration
+	lastPTS    time.Duration
+	firstCount int
+}
+
+func (c *durationCounter) add(mediaIndex int, pts time.Duration) {
+	if pts > c.maxPTS {
+		c.maxPTS = pts
+	}
+
+	if mediaIndex == 0 {
+		if c.firstCount == 0 {
+			c.firstPTS = pts
+		}
+		c.lastPTS = pts
+		c.firstCount++
+	}
+}
+
+func (c *durationCounter) duration() time.Duration {
+	if c.firstCount < 2 {
+		return c.maxPTS
+	}
+	return c.maxPTS + (c.lastPTS-c.firstPTS)/time.Duration(c.firstCount-1)
+}
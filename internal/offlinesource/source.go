@@ -0,0 +1,150 @@
+// Package offlinesource contains the offline source.
+package offlinesource
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/bluenviron/gortsplib/v4/pkg/description"
+
+	"github.com/bluenviron/mediamtx/internal/logger"
+	"github.com/bluenviron/mediamtx/internal/stream"
+	"github.com/bluenviron/mediamtx/internal/unit"
+)
+
+// minimum duration of a loop, in order to avoid busy loops with files that are empty.
+const minLoopDuration = 100 * time.Millisecond
+
+type file interface {
+	initialize(byts []byte) error
+	medias() []*description.Media
+
+	// read reads the whole file, passes units to cb and returns the file duration.
+	read(cb func(int, unit.Unit) error) (time.Duration, error)
+}
+
+func isMPEGTS(byts []byte) bool {
+	return len(byts) >= 188 && byts[0] == 0x47
+}
+
+func isFMP4(byts []byte) bool {
+	if len(byts) < 8 {
+		return false
+	}
+
+	switch string(byts[4:8]) {
+	case "ftyp", "moov", "styp", "moof":
+		return true
+	}
+	return false
+}
+
+// Source is a source that loops a local MPEG-TS or fMP4 file.
+// It is used to feed a path when the publisher is absent.
+type Source struct {
+	FilePath string
+	Parent   logger.Writer
+
+	file      file
+	ctx       context.Context
+	ctxCancel func()
+	done      chan struct{}
+}
+
+// Initialize loads the file.
+func (s *Source) Initialize() error {
+	byts, err := os.ReadFile(s.FilePath)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case isFMP4(byts):
+		s.file = &fmp4File{}
+
+	case isMPEGTS(byts):
+		s.file = &mpegtsFile{}
+
+	default:
+		return fmt.Errorf("unsupported file format (supported are fMP4 and MPEG-TS)")
+	}
+
+	return s.file.initialize(byts)
+}
+
+// Desc returns the description of the file.
+func (s *Source) Desc() *description.Session {
+	return &description.Session{Medias: s.file.medias()}
+}
+
+// Start starts writing the file into the stream.
+func (s *Source) Start(strm *stream.Stream) {
+	s.ctx, s.ctxCancel = context.WithCancel(context.Background())
+	s.done = make(chan struct{})
+
+	go s.run(strm)
+}
+
+// Close stops writing the file into the stream.
+func (s *Source) Close() {
+	s.ctxCancel()
+	<-s.done
+}
+
+// Log implements logger.Writer.
+func (s *Source) Log(level logger.Level, format string, args ...interface{}) {
+	s.Parent.Log(level, "[offline source] "+format, args...)
+}
+
+func (s *Source) run(strm *stream.Stream) {
+	defer close(s.done)
+
+	medias := s.file.medias()
+	start := time.Now()
+	offset := time.Duration(0)
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		duration, err := s.file.read(func(mediaIndex int, u unit.Unit) error {
+			pts := offset + u.GetPTS()
+
+			if wait := time.Until(start.Add(pts)); wait > 0 {
+				if !timer.Stop() {
+					select {
+					case <-timer.C:
+					default:
+					}
+				}
+				timer.Reset(wait)
+
+				select {
+				case <-timer.C:
+				case <-s.ctx.Done():
+					return fmt.Errorf("terminated")
+				}
+			}
+
+			u.SetPTS(pts)
+			strm.WriteUnit(medias[mediaIndex], medias[mediaIndex].Formats[0], u)
+			return nil
+		})
+
+		if s.ctx.Err() != nil {
+			return
+		}
+
+		if err != nil {
+			s.Log(logger.Error, err.Error())
+			return
+		}
+
+		if duration < minLoopDuration {
+			duration = minLoopDuration
+		}
+		offset += duration
+	}
+}
@@ -0,0 +1,169 @@
+package offlinesource
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bluenviron/gortsplib/v4/pkg/format"
+	"github.com/bluenviron/mediacommon/pkg/formats/fmp4"
+	"github.com/bluenviron/mediacommon/pkg/formats/fmp4/seekablebuffer"
+	"github.com/bluenviron/mediacommon/pkg/formats/mpegts"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bluenviron/mediamtx/internal/logger"
+	"github.com/bluenviron/mediamtx/internal/unit"
+)
+
+type nilLogger struct{}
+
+func (nilLogger) Log(_ logger.Level, _ string, _ ...interface{}) {
+}
+
+var testSPS = []byte{
+	0x67, 0x42, 0xc0, 0x28, 0xd9, 0x00, 0x78, 0x02,
+	0x27, 0xe5, 0x84, 0x00, 0x00, 0x03, 0x00, 0x04,
+	0x00, 0x00, 0x03, 0x00, 0xf0, 0x3c, 0x60, 0xc9,
+	0x20,
+}
+
+var testPPS = []byte{0x08, 0x06, 0x07, 0x08}
+
+func writeMPEGTS(t *testing.T, fpath string) {
+	var buf bytes.Buffer
+
+	track := &mpegts.Track{
+		Codec: &mpegts.CodecH264{},
+	}
+
+	w := mpegts.NewWriter(&buf, []*mpegts.Track{track})
+
+	for i := 0; i < 3; i++ {
+		err := w.WriteH26x(track, 90000+int64(i)*45000, 90000+int64(i)*45000, true, [][]byte{
+			testSPS,
+			testPPS,
+			{5, byte(i)},
+		})
+		require.NoError(t, err)
+	}
+
+	err := os.WriteFile(fpath, buf.Bytes(), 0o644)
+	require.NoError(t, err)
+}
+
+func writeFMP4(t *testing.T, fpath string) {
+	init := fmp4.Init{
+		Tracks: []*fmp4.InitTrack{{
+			ID:        1,
+			TimeScale: 90000,
+			Codec: &fmp4.CodecH264{
+				SPS: testSPS,
+				PPS: testPPS,
+			},
+		}},
+	}
+
+	var buf1 seekablebuffer.Buffer
+	err := init.Marshal(&buf1)
+	require.NoError(t, err)
+
+	var buf2 seekablebuffer.Buffer
+	parts := fmp4.Parts{{
+		SequenceNumber: 1,
+		Tracks: []*fmp4.PartTrack{{
+			ID:       1,
+			BaseTime: 90000,
+			Samples: []*fmp4.PartSample{
+				{
+					Duration: 45000,
+					Payload:  []byte{0, 0, 0, 2, 5, 0},
+				},
+				{
+					Duration: 45000,
+					Payload:  []byte{0, 0, 0, 2, 5, 1},
+				},
+				{
+					Duration: 45000,
+					Payload:  []byte{0, 0, 0, 2, 5, 2},
+				},
+			},
+		}},
+	}}
+	err = parts.Marshal(&buf2)
+	require.NoError(t, err)
+
+	err = os.WriteFile(fpath, append(buf1.Bytes(), buf2.Bytes()...), 0o644)
+	require.NoError(t, err)
+}
+
+func TestSourceRead(t *testing.T) {
+	for _, ca := range []string{
+		"mpegts",
+		"fmp4",
+	} {
+		t.Run(ca, func(t *testing.T) {
+			dir, err := os.MkdirTemp("", "mediamtx-offlinesource")
+			require.NoError(t, err)
+			defer os.RemoveAll(dir)
+
+			fpath := filepath.Join(dir, "offline")
+
+			if ca == "mpegts" {
+				writeMPEGTS(t, fpath)
+			} else {
+				writeFMP4(t, fpath)
+			}
+
+			s := &Source{
+				FilePath: fpath,
+				Parent:   nilLogger{},
+			}
+			err = s.Initialize()
+			require.NoError(t, err)
+
+			desc := s.Desc()
+			require.Equal(t, 1, len(desc.Medias))
+			require.IsType(t, &format.H264{}, desc.Medias[0].Formats[0])
+
+			var ptss []time.Duration
+			var aus [][][]byte
+
+			for i := 0; i < 2; i++ {
+				ptss = nil
+				aus = nil
+
+				duration, err := s.file.read(func(mediaIndex int, u unit.Unit) error {
+					require.Equal(t, 0, mediaIndex)
+					ptss = append(ptss, u.GetPTS())
+					aus = append(aus, u.(*unit.H264).AU)
+					return nil
+				})
+				require.NoError(t, err)
+				require.Equal(t, 1500*time.Millisecond, duration)
+			}
+
+			require.Equal(t, []time.Duration{0, 500 * time.Millisecond, 1000 * time.Millisecond}, ptss)
+			require.Equal(t, []byte{5, 2}, aus[2][len(aus[2])-1])
+		})
+	}
+}
+
+func TestSourceUnsupportedFormat(t *testing.T) {
+	dir, err := os.MkdirTemp("", "mediamtx-offlinesource")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	fpath := filepath.Join(dir, "offline")
+
+	err = os.WriteFile(fpath, []byte("not a media file"), 0o644)
+	require.NoError(t, err)
+
+	s := &Source{
+		FilePath: fpath,
+		Parent:   nilLogger{},
+	}
+	err = s.Initialize()
+	require.EqualError(t, err, "unsupported file format (supported are fMP4 and MPEG-TS)")
+}
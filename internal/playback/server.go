@@ -223,6 +223,9 @@ func (p *Server) onGet(ctx *gin.Context) {
 		return
 	}
 
+	// recordings of aliases are stored with the name of the path
+	pathName = pathConf.CanonicalName(pathName)
+
 	segments, err := findSegments(pathConf, pathName, start, duration)
 	if err != nil {
 		if errors.Is(err, errNoSegmentsFound) {
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/bluenviron/gortsplib/v4/pkg/description"
 	"github.com/bluenviron/gortsplib/v4/pkg/format"
 	"github.com/bluenviron/mediacommon/pkg/codecs/ac3"
 	"github.com/bluenviron/mediacommon/pkg/codecs/h264"
@@ -13,6 +14,7 @@ import (
 	srt "github.com/datarhei/gosrt"
 
 	"github.com/bluenviron/mediamtx/internal/asyncwriter"
+	"github.com/bluenviron/mediamtx/internal/metadata"
 	"github.com/bluenviron/mediamtx/internal/stream"
 	"github.com/bluenviron/mediamtx/internal/unit"
 )
@@ -22,8 +24,10 @@ func durationGoToMPEGTS(v time.Duration) int64 {
 }
 
 // FromStream links a server stream to a MPEG-TS writer.
+// Only medias contained in desc are read.
 func FromStream(
 	stream *stream.Stream,
+	desc *description.Session,
 	writer *asyncwriter.Writer,
 	bw *bufio.Writer,
 	sconn srt.Conn,
@@ -31,6 +35,7 @@ func FromStream(
 ) error {
 	var w *mcmpegts.Writer
 	var tracks []*mcmpegts.Track
+	mw := &MetadataWriter{W: bw}
 
 	addTrack := func(codec mcmpegts.Codec) *mcmpegts.Track {
 		track := &mcmpegts.Track{
@@ -40,7 +45,15 @@ func FromStream(
 		return track
 	}
 
-	for _, medi := range stream.Desc().Medias {
+	addMetadataTrack := func(typ MetadataType) *MetadataTrack {
+		track := &MetadataTrack{
+			Type: typ,
+		}
+		mw.Tracks = append(mw.Tracks, track)
+		return track
+	}
+
+	for _, medi := range desc.Medias {
 		for _, forma := range medi.Formats {
 			switch forma := forma.(type) {
 			case *format.H265: //nolint:dupl
@@ -246,6 +259,43 @@ func FromStream(
 					}
 					return bw.Flush()
 				})
+
+			case *format.Generic:
+				switch {
+				case metadata.IsKLV(forma):
+					track := addMetadataTrack(MetadataTypeKLV)
+
+					stream.AddReader(writer, medi, forma, func(u unit.Unit) error {
+						tunit := u.(*unit.Generic)
+						if tunit.Payload == nil {
+							return nil
+						}
+
+						sconn.SetWriteDeadline(time.Now().Add(writeTimeout))
+						err := mw.WriteKLV(track, durationGoToMPEGTS(tunit.PTS), tunit.Payload)
+						if err != nil {
+							return err
+						}
+						return bw.Flush()
+					})
+
+				case metadata.IsSCTE35(forma):
+					track := addMetadataTrack(MetadataTypeSCTE35)
+
+					stream.AddReader(writer, medi, forma, func(u unit.Unit) error {
+						tunit := u.(*unit.Generic)
+						if tunit.Payload == nil {
+							return nil
+						}
+
+						sconn.SetWriteDeadline(time.Now().Add(writeTimeout))
+						err := mw.WriteSCTE35(track, tunit.Payload)
+						if err != nil {
+							return err
+						}
+						return bw.Flush()
+					})
+				}
 			}
 		}
 	}
@@ -254,7 +304,8 @@ func FromStream(
 		return ErrNoTracks
 	}
 
-	w = mcmpegts.NewWriter(bw, tracks)
+	w = mcmpegts.NewWriter(mw, tracks)
+	mw.Initialize(tracks)
 
 	return nil
 }
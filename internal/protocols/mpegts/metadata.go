@@ -0,0 +1,184 @@
+package mpegts
+
+import (
+	"encoding/binary"
+)
+
+const (
+	tsPacketSize = 188
+
+	streamTypePrivateData = 0x06
+	streamTypeMetadataPES = 0x15
+	streamTypeSCTE35      = 0x86
+
+	descriptorTagRegistration = 0x05
+	descriptorTagMetadata     = 0x26
+
+	streamIDPrivateStream1 = 0xBD
+	streamIDMetadata       = 0xFC
+
+	ptsMask = (1 << 33) - 1
+)
+
+// MetadataType is the type of a metadata track.
+type MetadataType int
+
+// metadata types.
+const (
+	MetadataTypeKLV MetadataType = iota
+	MetadataTypeSCTE35
+)
+
+// MetadataTrack is a MPEG-TS track that contains metadata (KLV, SCTE-35).
+// These tracks are not supported by the mediacommon reader and writer,
+// therefore they are handled separately.
+type MetadataTrack struct {
+	PID  uint16
+	Type MetadataType
+
+	// writer state
+	continuityCounter uint8
+}
+
+type tsPacketHeader struct {
+	pid     uint16
+	pusi    bool
+	payload []byte
+}
+
+func parseTSPacket(pkt []byte) (*tsPacketHeader, bool) {
+	if pkt[0] != 0x47 {
+		return nil, false
+	}
+
+	h := &tsPacketHeader{
+		pid:  uint16(pkt[1]&0x1F)<<8 | uint16(pkt[2]),
+		pusi: (pkt[1] & 0x40) != 0,
+	}
+
+	afc := (pkt[3] >> 4) & 0x03
+	pos := 4
+
+	if (afc & 0x02) != 0 {
+		pos += 1 + int(pkt[4])
+	}
+
+	if (afc&0x01) == 0 || pos >= tsPacketSize {
+		return h, true
+	}
+
+	h.payload = pkt[pos:]
+	return h, true
+}
+
+// psiSection returns the section that starts in a payload with PUSI set.
+func psiSection(payload []byte) ([]byte, bool) {
+	if len(payload) < 1 {
+		return nil, false
+	}
+
+	pos := 1 + int(payload[0])
+	if len(payload) < (pos + 3) {
+		return nil, false
+	}
+
+	sec := payload[pos:]
+	le := 3 + int(binary.BigEndian.Uint16(sec[1:])&0x0FFF)
+	if len(sec) < le || le < 12 {
+		return nil, false
+	}
+
+	return sec[:le], true
+}
+
+// parsePAT returns the PID of the first PMT listed in a PAT section.
+func parsePAT(sec []byte) (uint16, bool) {
+	if sec[0] != 0x00 {
+		return 0, false
+	}
+
+	for pos := 8; (pos + 4) <= len(sec)-4; pos += 4 {
+		programNumber := binary.BigEndian.Uint16(sec[pos:])
+		if programNumber != 0 {
+			return binary.BigEndian.Uint16(sec[pos+2:]) & 0x1FFF, true
+		}
+	}
+
+	return 0, false
+}
+
+func descriptorsHaveRegistration(descs []byte, id string) bool {
+	for len(descs) >= 2 {
+		tag := descs[0]
+		le := int(descs[1])
+		if len(descs) < (2 + le) {
+			return false
+		}
+		body := descs[2 : 2+le]
+
+		switch tag {
+		case descriptorTagRegistration:
+			if len(body) >= 4 && string(body[:4]) == id {
+				return true
+			}
+
+		case descriptorTagMetadata:
+			// metadata_application_format (2), then metadata_format (1) and metadata_format_identifier (4)
+			if len(body) >= 7 && body[2] == 0xFF && string(body[3:7]) == id {
+				return true
+			}
+		}
+
+		descs = descs[2+le:]
+	}
+
+	return false
+}
+
+// parsePMTMetadataTracks returns the metadata tracks listed in a PMT section.
+func parsePMTMetadataTracks(sec []byte) ([]*MetadataTrack, bool) {
+	if sec[0] != 0x02 {
+		return nil, false
+	}
+
+	programInfoLength := int(binary.BigEndian.Uint16(sec[10:]) & 0x0FFF)
+	pos := 12 + programInfoLength
+	end := len(sec) - 4
+
+	var tracks []*MetadataTrack
+
+	for (pos + 5) <= end {
+		streamType := sec[pos]
+		pid := binary.BigEndian.Uint16(sec[pos+1:]) & 0x1FFF
+		esInfoLength := int(binary.BigEndian.Uint16(sec[pos+3:]) & 0x0FFF)
+		pos += 5
+		if (pos + esInfoLength) > end {
+			return nil, false
+		}
+		descs := sec[pos : pos+esInfoLength]
+		pos += esInfoLength
+
+		switch {
+		case streamType == streamTypeSCTE35:
+			tracks = append(tracks, &MetadataTrack{PID: pid, Type: MetadataTypeSCTE35})
+
+		case (streamType == streamTypePrivateData || streamType == streamTypeMetadataPES) &&
+			descriptorsHaveRegistration(descs, "KLVA"):
+			tracks = append(tracks, &MetadataTrack{PID: pid, Type: MetadataTypeKLV})
+		}
+	}
+
+	return tracks, true
+}
+
+func parsePESPTS(buf []byte) int64 {
+	return int64(buf[0]>>1&0x07)<<30 |
+		int64(binary.BigEndian.Uint16(buf[1:])>>1)<<15 |
+		int64(binary.BigEndian.Uint16(buf[3:])>>1)
+}
+
+func marshalPESPTS(buf []byte, pts int64) {
+	buf[0] = 0x21 | byte(pts>>29)&0x0E
+	binary.BigEndian.PutUint16(buf[1:], uint16(pts>>14)|0x01)
+	binary.BigEndian.PutUint16(buf[3:], uint16(pts<<1)|0x01)
+}
@@ -0,0 +1,217 @@
+package mpegts
+
+import (
+	"encoding/binary"
+	"io"
+
+	mcmpegts "github.com/bluenviron/mediacommon/pkg/formats/mpegts"
+
+	"github.com/bluenviron/mediamtx/internal/metadata"
+)
+
+// MetadataWriter allows to write metadata tracks (KLV, SCTE-35),
+// that are not supported by the mediacommon writer.
+// It must be placed between the mediacommon writer and the output,
+// in order to add metadata tracks to the PMT.
+type MetadataWriter struct {
+	W      io.Writer
+	Tracks []*MetadataTrack
+
+	pending    [tsPacketSize]byte
+	pendingLen int
+	pmtPID     uint16
+}
+
+// Initialize initializes MetadataWriter.
+// PIDs of metadata tracks are assigned after the ones of the mediacommon writer,
+// therefore it must be called after the mediacommon writer has been created.
+func (w *MetadataWriter) Initialize(tracks []*mcmpegts.Track) {
+	nextPID := uint16(256)
+	for _, track := range tracks {
+		if track.PID >= nextPID {
+			nextPID = track.PID + 1
+		}
+	}
+
+	for _, track := range w.Tracks {
+		if track.PID == 0 {
+			track.PID = nextPID
+			nextPID++
+		}
+	}
+}
+
+// Write implements io.Writer.
+func (w *MetadataWriter) Write(p []byte) (int, error) {
+	n := len(p)
+
+	for len(p) > 0 {
+		if w.pendingLen == 0 && len(p) >= tsPacketSize {
+			err := w.writePacket(p[:tsPacketSize])
+			if err != nil {
+				return 0, err
+			}
+			p = p[tsPacketSize:]
+			continue
+		}
+
+		copied := copy(w.pending[w.pendingLen:], p)
+		w.pendingLen += copied
+		p = p[copied:]
+
+		if w.pendingLen == tsPacketSize {
+			w.pendingLen = 0
+			err := w.writePacket(w.pending[:])
+			if err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	return n, nil
+}
+
+func (w *MetadataWriter) writePacket(pkt []byte) error {
+	h, ok := parseTSPacket(pkt)
+	if ok && h.pusi && h.payload != nil && len(w.Tracks) != 0 {
+		switch {
+		case h.pid == 0:
+			if sec, ok := psiSection(h.payload); ok {
+				if pid, ok := parsePAT(sec); ok {
+					w.pmtPID = pid
+				}
+			}
+
+		case w.pmtPID != 0 && h.pid == w.pmtPID:
+			if sec, ok := psiSection(h.payload); ok && sec[0] == 0x02 {
+				if newPkt, ok := w.rewritePMT(pkt, sec); ok {
+					pkt = newPkt
+				}
+			}
+		}
+	}
+
+	_, err := w.W.Write(pkt)
+	return err
+}
+
+func (w *MetadataWriter) rewritePMT(pkt []byte, sec []byte) ([]byte, bool) {
+	newSec := append([]byte(nil), sec[:len(sec)-4]...)
+
+	for _, track := range w.Tracks {
+		var streamType byte
+		var registration string
+
+		switch track.Type {
+		case MetadataTypeKLV:
+			streamType = streamTypePrivateData
+			registration = "KLVA"
+
+		case MetadataTypeSCTE35:
+			streamType = streamTypeSCTE35
+			registration = "CUEI"
+		}
+
+		newSec = append(newSec,
+			streamType,
+			0xE0|byte(track.PID>>8), byte(track.PID),
+			0xF0, 6,
+			descriptorTagRegistration, 4)
+		newSec = append(newSec, registration...)
+	}
+
+	newSec = append(newSec, 0, 0, 0, 0)
+	binary.BigEndian.PutUint16(newSec[1:], (binary.BigEndian.Uint16(newSec[1:])&0xF000)|uint16(len(newSec)-3))
+	binary.BigEndian.PutUint32(newSec[len(newSec)-4:], metadata.CRC32MPEG2(newSec[:len(newSec)-4]))
+
+	if (4 + 1 + len(newSec)) > tsPacketSize {
+		return nil, false
+	}
+
+	newPkt := make([]byte, tsPacketSize)
+	copy(newPkt, pkt[:4])
+	newPkt[3] = (newPkt[3] & 0xCF) | 0x10 // payload only
+	newPkt[4] = 0                         // pointer_field
+	n := copy(newPkt[5:], newSec)
+	for i := 5 + n; i < tsPacketSize; i++ {
+		newPkt[i] = 0xFF
+	}
+
+	return newPkt, true
+}
+
+// WriteKLV writes KLV data.
+func (w *MetadataWriter) WriteKLV(track *MetadataTrack, pts int64, data []byte) error {
+	pes := make([]byte, 14+len(data))
+	pes[2] = 0x01
+	pes[3] = streamIDPrivateStream1
+
+	if le := 8 + len(data); le <= 0xFFFF {
+		binary.BigEndian.PutUint16(pes[4:], uint16(le))
+	}
+
+	pes[6] = 0x84 // data_alignment_indicator
+	pes[7] = 0x80 // PTS only
+	pes[8] = 5
+	marshalPESPTS(pes[9:], pts&ptsMask)
+	copy(pes[14:], data)
+
+	return w.writeTrackData(track, pes, false)
+}
+
+// WriteSCTE35 writes a SCTE-35 splice_info_section.
+func (w *MetadataWriter) WriteSCTE35(track *MetadataTrack, section []byte) error {
+	return w.writeTrackData(track, append([]byte{0}, section...), true)
+}
+
+func (w *MetadataWriter) writeTrackData(track *MetadataTrack, data []byte, isSection bool) error {
+	first := true
+
+	for first || len(data) > 0 {
+		pkt := make([]byte, tsPacketSize)
+		pkt[0] = 0x47
+		pkt[1] = byte(track.PID>>8) & 0x1F
+		if first {
+			pkt[1] |= 0x40
+		}
+		pkt[2] = byte(track.PID)
+		pkt[3] = 0x10 | track.continuityCounter
+		track.continuityCounter = (track.continuityCounter + 1) & 0x0F
+		first = false
+
+		const space = tsPacketSize - 4
+
+		switch {
+		case len(data) >= space:
+			copy(pkt[4:], data[:space])
+			data = data[space:]
+
+		case isSection:
+			n := copy(pkt[4:], data)
+			for i := 4 + n; i < tsPacketSize; i++ {
+				pkt[i] = 0xFF
+			}
+			data = nil
+
+		default:
+			stuffing := space - len(data)
+			pkt[3] |= 0x20
+			pkt[4] = byte(stuffing - 1)
+			if stuffing >= 2 {
+				pkt[5] = 0x00
+				for i := 6; i < 4+stuffing; i++ {
+					pkt[i] = 0xFF
+				}
+			}
+			copy(pkt[4+stuffing:], data)
+			data = nil
+		}
+
+		_, err := w.W.Write(pkt)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
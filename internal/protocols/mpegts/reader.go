@@ -0,0 +1,242 @@
+package mpegts
+
+import (
+	"encoding/binary"
+	"io"
+
+	mcmpegts "github.com/bluenviron/mediacommon/pkg/formats/mpegts"
+
+	"github.com/bluenviron/mediamtx/internal/metadata"
+)
+
+const (
+	maxMetadataSize = 1 * 1024 * 1024
+)
+
+// ReaderOnDataMetadataFunc is the prototype of the callback passed to OnDataMetadata.
+// pts is nil when the metadata unit doesn't have a timestamp.
+type ReaderOnDataMetadataFunc func(pts *int64, data []byte) error
+
+type metadataTrackState struct {
+	track *MetadataTrack
+	buf   []byte
+	cb    ReaderOnDataMetadataFunc
+}
+
+// metadataDemuxer extracts metadata tracks from a MPEG-TS stream.
+// It receives a copy of all the data read by the mediacommon reader.
+type metadataDemuxer struct {
+	buf    []byte
+	pmtPID uint16
+	pmtOK  bool
+	tracks []*MetadataTrack
+	states map[uint16]*metadataTrackState
+}
+
+func (d *metadataDemuxer) Write(p []byte) (int, error) {
+	d.buf = append(d.buf, p...)
+
+	for len(d.buf) >= tsPacketSize {
+		if d.buf[0] != 0x47 {
+			d.buf = d.buf[1:]
+			continue
+		}
+
+		err := d.processPacket(d.buf[:tsPacketSize])
+		d.buf = d.buf[tsPacketSize:]
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	// avoid keeping the whole stream in memory
+	d.buf = append([]byte(nil), d.buf...)
+
+	return len(p), nil
+}
+
+func (d *metadataDemuxer) processPacket(pkt []byte) error {
+	h, ok := parseTSPacket(pkt)
+	if !ok || h.payload == nil {
+		return nil
+	}
+
+	switch {
+	case h.pid == 0:
+		if h.pusi && !d.pmtOK {
+			if sec, ok := psiSection(h.payload); ok {
+				if pid, ok := parsePAT(sec); ok {
+					d.pmtPID = pid
+				}
+			}
+		}
+
+	case d.pmtPID != 0 && h.pid == d.pmtPID:
+		if h.pusi && !d.pmtOK {
+			if sec, ok := psiSection(h.payload); ok {
+				if tracks, ok := parsePMTMetadataTracks(sec); ok {
+					d.pmtOK = true
+					d.tracks = tracks
+					d.states = make(map[uint16]*metadataTrackState)
+					for _, track := range tracks {
+						d.states[track.PID] = &metadataTrackState{track: track}
+					}
+				}
+			}
+		}
+
+	default:
+		st, ok := d.states[h.pid]
+		if !ok {
+			return nil
+		}
+		return d.processMetadataPayload(st, h.pusi, h.payload)
+	}
+
+	return nil
+}
+
+func (d *metadataDemuxer) processMetadataPayload(st *metadataTrackState, pusi bool, payload []byte) error {
+	if st.track.Type == MetadataTypeSCTE35 {
+		if pusi {
+			if len(payload) < 1 || len(payload) < (1+int(payload[0])) {
+				st.buf = nil
+				return nil
+			}
+			st.buf = append([]byte(nil), payload[1+int(payload[0]):]...)
+		} else {
+			if st.buf == nil {
+				return nil
+			}
+			st.buf = append(st.buf, payload...)
+		}
+
+		if len(st.buf) < 3 {
+			return nil
+		}
+
+		le := 3 + int(binary.BigEndian.Uint16(st.buf[1:])&0x0FFF)
+		if len(st.buf) < le {
+			return nil
+		}
+
+		sec := st.buf[:le]
+		st.buf = nil
+
+		if sec[0] != 0xFC || metadata.CRC32MPEG2(sec) != 0 {
+			return nil
+		}
+
+		if st.cb == nil {
+			return nil
+		}
+		return st.cb(nil, sec)
+	}
+
+	if pusi {
+		// PES with unbounded length are delimited by the next PES
+		if st.buf != nil {
+			err := d.processPES(st)
+			if err != nil {
+				return err
+			}
+		}
+		st.buf = append([]byte(nil), payload...)
+	} else {
+		if st.buf == nil {
+			return nil
+		}
+		if (len(st.buf) + len(payload)) > maxMetadataSize {
+			st.buf = nil
+			return nil
+		}
+		st.buf = append(st.buf, payload...)
+	}
+
+	if len(st.buf) >= 6 {
+		pesLen := int(binary.BigEndian.Uint16(st.buf[4:]))
+		if pesLen != 0 && len(st.buf) >= (6+pesLen) {
+			st.buf = st.buf[:6+pesLen]
+			return d.processPES(st)
+		}
+	}
+
+	return nil
+}
+
+func (d *metadataDemuxer) processPES(st *metadataTrackState) error {
+	buf := st.buf
+	st.buf = nil
+
+	if len(buf) < 9 || buf[0] != 0 || buf[1] != 0 || buf[2] != 1 {
+		return nil
+	}
+
+	streamID := buf[3]
+	headerEnd := 9 + int(buf[8])
+	if len(buf) < headerEnd {
+		return nil
+	}
+
+	var pts *int64
+	if (buf[7]&0x80) != 0 && headerEnd >= 14 {
+		v := parsePESPTS(buf[9:])
+		pts = &v
+	}
+
+	data := buf[headerEnd:]
+
+	// synchronous metadata is wrapped into metadata access unit cells
+	if streamID == streamIDMetadata {
+		var payload []byte
+		for len(data) >= 5 {
+			le := int(binary.BigEndian.Uint16(data[3:]))
+			if len(data) < (5 + le) {
+				return nil
+			}
+			payload = append(payload, data[5:5+le]...)
+			data = data[5+le:]
+		}
+		data = payload
+	}
+
+	if len(data) == 0 || st.cb == nil {
+		return nil
+	}
+
+	return st.cb(pts, data)
+}
+
+// Reader is a MPEG-TS reader that supports metadata tracks (KLV, SCTE-35)
+// in addition to the tracks supported by the mediacommon reader.
+type Reader struct {
+	*mcmpegts.Reader
+
+	metadata *metadataDemuxer
+}
+
+// NewReader allocates a Reader.
+func NewReader(br io.Reader) (*Reader, error) {
+	md := &metadataDemuxer{}
+
+	r, err := mcmpegts.NewReader(io.TeeReader(br, md))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Reader{
+		Reader:   r,
+		metadata: md,
+	}, nil
+}
+
+// MetadataTracks returns metadata tracks.
+func (r *Reader) MetadataTracks() []*MetadataTrack {
+	return r.metadata.tracks
+}
+
+// OnDataMetadata sets a callback that is called when data from a metadata track is received.
+// SCTE-35 data is a splice_info_section, KLV data is a sequence of KLV packets.
+func (r *Reader) OnDataMetadata(track *MetadataTrack, cb ReaderOnDataMetadataFunc) {
+	r.metadata.states[track.PID].cb = cb
+}
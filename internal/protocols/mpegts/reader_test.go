@@ -0,0 +1,115 @@
+package mpegts
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+
+	mcmpegts "github.com/bluenviron/mediacommon/pkg/formats/mpegts"
+	"github.com/stretchr/testify/require"
+)
+
+type metadataEntry struct {
+	pts  *int64
+	data []byte
+}
+
+func int64Ptr(v int64) *int64 {
+	return &v
+}
+
+func TestReaderMetadata(t *testing.T) {
+	section, err := base64.StdEncoding.DecodeString(
+		"/DAvAAAAAAAA///wFAVIAACPf+/+c2nALv4AUsz1AAAAAAAKAAhDVUVJAAABNWLbowo=")
+	require.NoError(t, err)
+
+	klv := []byte{
+		0x06, 0x0e, 0x2b, 0x34, 0x02, 0x0b, 0x01, 0x01,
+		0x0e, 0x01, 0x03, 0x01, 0x01, 0x00, 0x00, 0x00,
+		0x04, 0x01, 0x02, 0x03, 0x04,
+	}
+
+	// a KLV unit that is split into multiple TS packets
+	bigKLV := bytes.Repeat([]byte{1, 2, 3, 4}, 200)
+
+	var buf bytes.Buffer
+
+	videoTrack := &mcmpegts.Track{Codec: &mcmpegts.CodecH264{}}
+	klvTrack := &MetadataTrack{Type: MetadataTypeKLV}
+	scte35Track := &MetadataTrack{Type: MetadataTypeSCTE35}
+
+	mw := &MetadataWriter{
+		W:      &buf,
+		Tracks: []*MetadataTrack{klvTrack, scte35Track},
+	}
+	w := mcmpegts.NewWriter(mw, []*mcmpegts.Track{videoTrack})
+	mw.Initialize([]*mcmpegts.Track{videoTrack})
+
+	// fill the read buffer of the reader before writing metadata,
+	// since callbacks are set after the reader has been created.
+	for i := 0; i < 100; i++ {
+		err = w.WriteH26x(videoTrack, int64(i)*3000, int64(i)*3000, true, [][]byte{{5, 1}})
+		require.NoError(t, err)
+	}
+
+	err = mw.WriteKLV(klvTrack, 300000, klv)
+	require.NoError(t, err)
+
+	err = mw.WriteSCTE35(scte35Track, section)
+	require.NoError(t, err)
+
+	err = mw.WriteKLV(klvTrack, 303000, bigKLV)
+	require.NoError(t, err)
+
+	// an invalid section is discarded
+	invalid := append([]byte(nil), section...)
+	invalid[10] ^= 0xFF
+	err = mw.WriteSCTE35(scte35Track, invalid)
+	require.NoError(t, err)
+
+	err = w.WriteH26x(videoTrack, 306000, 306000, true, [][]byte{{5, 1}})
+	require.NoError(t, err)
+
+	r, err := NewReader(&buf)
+	require.NoError(t, err)
+
+	tracks := r.MetadataTracks()
+	require.Equal(t, []*MetadataTrack{
+		{PID: klvTrack.PID, Type: MetadataTypeKLV},
+		{PID: scte35Track.PID, Type: MetadataTypeSCTE35},
+	}, tracks)
+
+	var klvEntries []metadataEntry
+	var scte35Entries []metadataEntry
+
+	r.OnDataMetadata(tracks[0], func(pts *int64, data []byte) error {
+		klvEntries = append(klvEntries, metadataEntry{pts, data})
+		return nil
+	})
+
+	r.OnDataMetadata(tracks[1], func(pts *int64, data []byte) error {
+		scte35Entries = append(scte35Entries, metadataEntry{pts, data})
+		return nil
+	})
+
+	r.OnDataH26x(r.Tracks()[0], func(_ int64, _ int64, _ [][]byte) error {
+		return nil
+	})
+
+	// read until the end of the stream
+	for {
+		err = r.Read()
+		if err != nil {
+			break
+		}
+	}
+
+	require.Equal(t, []metadataEntry{
+		{int64Ptr(300000), klv},
+		{int64Ptr(303000), bigKLV},
+	}, klvEntries)
+
+	require.Equal(t, []metadataEntry{
+		{nil, section},
+	}, scte35Entries)
+}
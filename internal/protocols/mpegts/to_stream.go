@@ -9,6 +9,7 @@ import (
 	"github.com/bluenviron/gortsplib/v4/pkg/format"
 	"github.com/bluenviron/mediacommon/pkg/formats/mpegts"
 
+	"github.com/bluenviron/mediamtx/internal/metadata"
 	"github.com/bluenviron/mediamtx/internal/stream"
 	"github.com/bluenviron/mediamtx/internal/unit"
 )
@@ -18,15 +19,28 @@ var ErrNoTracks = errors.New("no supported tracks found (supported are H265, H26
 	" MPEG-4 Video, MPEG-1/2 Video, Opus, MPEG-4 Audio, MPEG-1 Audio, AC-3")
 
 // ToStream converts a MPEG-TS stream to a server stream.
-func ToStream(r *mpegts.Reader, stream **stream.Stream) ([]*description.Media, error) {
+func ToStream(r *Reader, stream **stream.Stream) ([]*description.Media, error) {
+	return ToUnits(r, func(medi *description.Media, u unit.Unit) error {
+		(*stream).WriteUnit(medi, medi.Formats[0], u)
+		return nil
+	})
+}
+
+// ToUnits converts a MPEG-TS stream to units, that are passed to cb.
+// PTS of units starts from zero.
+func ToUnits(r *Reader, cb func(*description.Media, unit.Unit) error) ([]*description.Media, error) {
 	var medias []*description.Media //nolint:prealloc
 
 	var td *mpegts.TimeDecoder
+	var lastRawPTS int64
+	var lastPTS time.Duration
 	decodeTime := func(t int64) time.Duration {
 		if td == nil {
 			td = mpegts.NewTimeDecoder(t)
 		}
-		return td.Decode(t)
+		lastRawPTS = t
+		lastPTS = td.Decode(t)
+		return lastPTS
 	}
 
 	for _, track := range r.Tracks() { //nolint:dupl
@@ -42,14 +56,13 @@ func ToStream(r *mpegts.Reader, stream **stream.Stream) ([]*description.Media, e
 			}
 
 			r.OnDataH26x(track, func(pts int64, _ int64, au [][]byte) error {
-				(*stream).WriteUnit(medi, medi.Formats[0], &unit.H265{
+				return cb(medi, &unit.H265{
 					Base: unit.Base{
 						NTP: time.Now(),
 						PTS: decodeTime(pts),
 					},
 					AU: au,
 				})
-				return nil
 			})
 
 		case *mpegts.CodecH264:
@@ -62,14 +75,13 @@ func ToStream(r *mpegts.Reader, stream **stream.Stream) ([]*description.Media, e
 			}
 
 			r.OnDataH26x(track, func(pts int64, _ int64, au [][]byte) error {
-				(*stream).WriteUnit(medi, medi.Formats[0], &unit.H264{
+				return cb(medi, &unit.H264{
 					Base: unit.Base{
 						NTP: time.Now(),
 						PTS: decodeTime(pts),
 					},
 					AU: au,
 				})
-				return nil
 			})
 
 		case *mpegts.CodecMPEG4Video:
@@ -81,14 +93,13 @@ func ToStream(r *mpegts.Reader, stream **stream.Stream) ([]*description.Media, e
 			}
 
 			r.OnDataMPEGxVideo(track, func(pts int64, frame []byte) error {
-				(*stream).WriteUnit(medi, medi.Formats[0], &unit.MPEG4Video{
+				return cb(medi, &unit.MPEG4Video{
 					Base: unit.Base{
 						NTP: time.Now(),
 						PTS: decodeTime(pts),
 					},
 					Frame: frame,
 				})
-				return nil
 			})
 
 		case *mpegts.CodecMPEG1Video:
@@ -98,14 +109,13 @@ func ToStream(r *mpegts.Reader, stream **stream.Stream) ([]*description.Media, e
 			}
 
 			r.OnDataMPEGxVideo(track, func(pts int64, frame []byte) error {
-				(*stream).WriteUnit(medi, medi.Formats[0], &unit.MPEG1Video{
+				return cb(medi, &unit.MPEG1Video{
 					Base: unit.Base{
 						NTP: time.Now(),
 						PTS: decodeTime(pts),
 					},
 					Frame: frame,
 				})
-				return nil
 			})
 
 		case *mpegts.CodecOpus:
@@ -118,14 +128,13 @@ func ToStream(r *mpegts.Reader, stream **stream.Stream) ([]*description.Media, e
 			}
 
 			r.OnDataOpus(track, func(pts int64, packets [][]byte) error {
-				(*stream).WriteUnit(medi, medi.Formats[0], &unit.Opus{
+				return cb(medi, &unit.Opus{
 					Base: unit.Base{
 						NTP: time.Now(),
 						PTS: decodeTime(pts),
 					},
 					Packets: packets,
 				})
-				return nil
 			})
 
 		case *mpegts.CodecMPEG4Audio:
@@ -141,14 +150,13 @@ func ToStream(r *mpegts.Reader, stream **stream.Stream) ([]*description.Media, e
 			}
 
 			r.OnDataMPEG4Audio(track, func(pts int64, aus [][]byte) error {
-				(*stream).WriteUnit(medi, medi.Formats[0], &unit.MPEG4Audio{
+				return cb(medi, &unit.MPEG4Audio{
 					Base: unit.Base{
 						NTP: time.Now(),
 						PTS: decodeTime(pts),
 					},
 					AUs: aus,
 				})
-				return nil
 			})
 
 		case *mpegts.CodecMPEG1Audio:
@@ -158,14 +166,13 @@ func ToStream(r *mpegts.Reader, stream **stream.Stream) ([]*description.Media, e
 			}
 
 			r.OnDataMPEG1Audio(track, func(pts int64, frames [][]byte) error {
-				(*stream).WriteUnit(medi, medi.Formats[0], &unit.MPEG1Audio{
+				return cb(medi, &unit.MPEG1Audio{
 					Base: unit.Base{
 						NTP: time.Now(),
 						PTS: decodeTime(pts),
 					},
 					Frames: frames,
 				})
-				return nil
 			})
 
 		case *mpegts.CodecAC3:
@@ -179,14 +186,13 @@ func ToStream(r *mpegts.Reader, stream **stream.Stream) ([]*description.Media, e
 			}
 
 			r.OnDataAC3(track, func(pts int64, frame []byte) error {
-				(*stream).WriteUnit(medi, medi.Formats[0], &unit.AC3{
+				return cb(medi, &unit.AC3{
 					Base: unit.Base{
 						NTP: time.Now(),
 						PTS: decodeTime(pts),
 					},
 					Frames: [][]byte{frame},
 				})
-				return nil
 			})
 
 		default:
@@ -200,5 +206,66 @@ func ToStream(r *mpegts.Reader, stream **stream.Stream) ([]*description.Media, e
 		return nil, ErrNoTracks
 	}
 
+	for _, track := range r.MetadataTracks() {
+		var medi *description.Media
+
+		switch track.Type {
+		case MetadataTypeKLV:
+			medi = &description.Media{
+				Type:    description.MediaTypeApplication,
+				Formats: []format.Format{metadata.NewKLVFormat()},
+			}
+
+			r.OnDataMetadata(track, func(pts *int64, data []byte) error {
+				var p time.Duration
+				switch {
+				case pts != nil:
+					p = decodeTime(*pts)
+
+				// asynchronous KLV is attached to the current timestamp
+				case td != nil:
+					p = lastPTS
+
+				default:
+					return nil
+				}
+
+				return cb(medi, &unit.Generic{
+					Base: unit.Base{
+						NTP: time.Now(),
+						PTS: p,
+					},
+					Payload: data,
+				})
+			})
+
+		case MetadataTypeSCTE35:
+			medi = &description.Media{
+				Type:    description.MediaTypeApplication,
+				Formats: []format.Format{metadata.NewSCTE35Format()},
+			}
+
+			r.OnDataMetadata(track, func(_ *int64, data []byte) error {
+				if td == nil {
+					return nil
+				}
+
+				// move splice times into the timeline of units, that starts from zero.
+				offset := lastRawPTS - durationGoToMPEGTS(lastPTS)
+				data = metadata.SCTE35AddPTSAdjustment(data, -offset)
+
+				return cb(medi, &unit.Generic{
+					Base: unit.Base{
+						NTP: time.Now(),
+						PTS: lastPTS,
+					},
+					Payload: data,
+				})
+			})
+		}
+
+		medias = append(medias, medi)
+	}
+
 	return medias, nil
 }
@@ -0,0 +1,47 @@
+package webrtc
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/pion/webrtc/v3"
+
+	"github.com/bluenviron/mediamtx/internal/captions"
+)
+
+// label of the data channel that readers can open in order to receive closed captions.
+const captionsChannelLabel = "captions"
+
+type captionsMessage struct {
+	Text string `json:"text"`
+}
+
+// captionsSender decodes closed captions and sends their text through a data channel.
+type captionsSender struct {
+	mutex   sync.Mutex
+	channel *webrtc.DataChannel
+	decoder captions.Decoder
+}
+
+func (s *captionsSender) setChannel(channel *webrtc.DataChannel) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.channel = channel
+}
+
+func (s *captionsSender) write(data []captions.CCData) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.channel == nil {
+		return
+	}
+
+	text, changed := s.decoder.Decode(data)
+	if !changed {
+		return
+	}
+
+	byts, _ := json.Marshal(captionsMessage{Text: text})
+	s.channel.SendText(string(byts)) //nolint:errcheck
+}
@@ -0,0 +1,269 @@
+package webrtc
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bluenviron/gortsplib/v4/pkg/description"
+	"github.com/bluenviron/gortsplib/v4/pkg/format"
+	"github.com/bluenviron/gortsplib/v4/pkg/format/rtpav1"
+	"github.com/bluenviron/gortsplib/v4/pkg/format/rtph264"
+	"github.com/bluenviron/gortsplib/v4/pkg/format/rtpvp8"
+	"github.com/bluenviron/gortsplib/v4/pkg/format/rtpvp9"
+
+	"github.com/bluenviron/mediamtx/internal/asyncwriter"
+	"github.com/bluenviron/mediamtx/internal/stream"
+	"github.com/bluenviron/mediamtx/internal/unit"
+)
+
+const (
+	payloadMaxSize = 1188 // 1200 - 12 (RTP header)
+)
+
+// ErrNoSupportedCodecs is returned when the stream doesn't contain any codec supported by WebRTC.
+var ErrNoSupportedCodecs = errors.New(
+	"the stream doesn't contain any supported codec, which are currently AV1, VP9, VP8, H264, Opus, G722, G711")
+
+// SetupStreamFunc links a server stream to an outgoing track.
+type SetupStreamFunc func(*OutgoingTrack) error
+
+// FindVideoTrack finds the first video track supported by WebRTC.
+func FindVideoTrack(
+	stream *stream.Stream,
+	desc *description.Session,
+	writer *asyncwriter.Writer,
+) (format.Format, SetupStreamFunc) {
+	var av1Format *format.AV1
+	media := desc.FindFormat(&av1Format)
+
+	if av1Format != nil {
+		return av1Format, func(track *OutgoingTrack) error {
+			encoder := &rtpav1.Encoder{
+				PayloadType:    105,
+				PayloadMaxSize: payloadMaxSize,
+			}
+			err := encoder.Init()
+			if err != nil {
+				return err
+			}
+
+			stream.AddReader(writer, media, av1Format, func(u unit.Unit) error {
+				tunit := u.(*unit.AV1)
+
+				if tunit.TU == nil {
+					return nil
+				}
+
+				packets, err := encoder.Encode(tunit.TU)
+				if err != nil {
+					return nil //nolint:nilerr
+				}
+
+				for _, pkt := range packets {
+					pkt.Timestamp += tunit.RTPPackets[0].Timestamp
+					track.WriteRTP(pkt) //nolint:errcheck
+				}
+
+				return nil
+			})
+
+			return nil
+		}
+	}
+
+	var vp9Format *format.VP9
+	media = desc.FindFormat(&vp9Format)
+
+	if vp9Format != nil {
+		return vp9Format, func(track *OutgoingTrack) error {
+			encoder := &rtpvp9.Encoder{
+				PayloadType:    96,
+				PayloadMaxSize: payloadMaxSize,
+			}
+			err := encoder.Init()
+			if err != nil {
+				return err
+			}
+
+			stream.AddReader(writer, media, vp9Format, func(u unit.Unit) error {
+				tunit := u.(*unit.VP9)
+
+				if tunit.Frame == nil {
+					return nil
+				}
+
+				packets, err := encoder.Encode(tunit.Frame)
+				if err != nil {
+					return nil //nolint:nilerr
+				}
+
+				for _, pkt := range packets {
+					pkt.Timestamp += tunit.RTPPackets[0].Timestamp
+					track.WriteRTP(pkt) //nolint:errcheck
+				}
+
+				return nil
+			})
+
+			return nil
+		}
+	}
+
+	var vp8Format *format.VP8
+	media = desc.FindFormat(&vp8Format)
+
+	if vp8Format != nil {
+		return vp8Format, func(track *OutgoingTrack) error {
+			encoder := &rtpvp8.Encoder{
+				PayloadType:    96,
+				PayloadMaxSize: payloadMaxSize,
+			}
+			err := encoder.Init()
+			if err != nil {
+				return err
+			}
+
+			stream.AddReader(writer, media, vp8Format, func(u unit.Unit) error {
+				tunit := u.(*unit.VP8)
+
+				if tunit.Frame == nil {
+					return nil
+				}
+
+				packets, err := encoder.Encode(tunit.Frame)
+				if err != nil {
+					return nil //nolint:nilerr
+				}
+
+				for _, pkt := range packets {
+					pkt.Timestamp += tunit.RTPPackets[0].Timestamp
+					track.WriteRTP(pkt) //nolint:errcheck
+				}
+
+				return nil
+			})
+
+			return nil
+		}
+	}
+
+	var h264Format *format.H264
+	media = desc.FindFormat(&h264Format)
+
+	if h264Format != nil {
+		return h264Format, func(track *OutgoingTrack) error {
+			encoder := &rtph264.Encoder{
+				PayloadType:    96,
+				PayloadMaxSize: payloadMaxSize,
+			}
+			err := encoder.Init()
+			if err != nil {
+				return err
+			}
+
+			firstReceived := false
+			var lastPTS time.Duration
+
+			stream.AddReader(writer, media, h264Format, func(u unit.Unit) error {
+				tunit := u.(*unit.H264)
+
+				if tunit.AU == nil {
+					return nil
+				}
+
+				if !firstReceived {
+					firstReceived = true
+				} else if tunit.PTS < lastPTS {
+					return fmt.Errorf("WebRTC doesn't support H264 streams with B-frames")
+				}
+				lastPTS = tunit.PTS
+
+				packets, err := encoder.Encode(tunit.AU)
+				if err != nil {
+					return nil //nolint:nilerr
+				}
+
+				for _, pkt := range packets {
+					pkt.Timestamp += tunit.RTPPackets[0].Timestamp
+					track.WriteRTP(pkt) //nolint:errcheck
+				}
+
+				if tunit.Captions != nil {
+					track.WriteCaptions(tunit.Captions)
+				}
+
+				return nil
+			})
+
+			return nil
+		}
+	}
+
+	return nil, nil
+}
+
+// FindAudioTrack finds the first audio track supported by WebRTC.
+func FindAudioTrack(
+	stream *stream.Stream,
+	desc *description.Session,
+	writer *asyncwriter.Writer,
+) (format.Format, SetupStreamFunc) {
+	var opusFormat *format.Opus
+	media := desc.FindFormat(&opusFormat)
+
+	if opusFormat != nil {
+		return opusFormat, func(track *OutgoingTrack) error {
+			stream.AddReader(writer, media, opusFormat, func(u unit.Unit) error {
+				for _, pkt := range u.GetRTPPackets() {
+					track.WriteRTP(pkt) //nolint:errcheck
+				}
+
+				return nil
+			})
+			return nil
+		}
+	}
+
+	var g722Format *format.G722
+	media = desc.FindFormat(&g722Format)
+
+	if g722Format != nil {
+		return g722Format, func(track *OutgoingTrack) error {
+			stream.AddReader(writer, media, g722Format, func(u unit.Unit) error {
+				for _, pkt := range u.GetRTPPackets() {
+					track.WriteRTP(pkt) //nolint:errcheck
+				}
+
+				return nil
+			})
+			return nil
+		}
+	}
+
+	var g711Format *format.G711
+	media = desc.FindFormat(&g711Format)
+
+	if g711Format != nil {
+		return g711Format, func(track *OutgoingTrack) error {
+			if g711Format.SampleRate != 8000 {
+				return fmt.Errorf("unsupported G711 sample rate")
+			}
+
+			if g711Format.ChannelCount != 1 {
+				return fmt.Errorf("unsupported G711 channel count")
+			}
+
+			stream.AddReader(writer, media, g711Format, func(u unit.Unit) error {
+				for _, pkt := range u.GetRTPPackets() {
+					track.WriteRTP(pkt) //nolint:errcheck
+				}
+
+				return nil
+			})
+			return nil
+		}
+	}
+
+	return nil, nil
+}
@@ -6,13 +6,16 @@ import (
 	"github.com/bluenviron/gortsplib/v4/pkg/format"
 	"github.com/pion/rtp"
 	"github.com/pion/webrtc/v3"
+
+	"github.com/bluenviron/mediamtx/internal/captions"
 )
 
 type addTrackFunc func(webrtc.TrackLocal) (*webrtc.RTPSender, error)
 
 // OutgoingTrack is a WebRTC outgoing track
 type OutgoingTrack struct {
-	track *webrtc.TrackLocalStaticRTP
+	track    *webrtc.TrackLocalStaticRTP
+	captions *captionsSender
 }
 
 func newOutgoingTrack(forma format.Format, addTrack addTrackFunc) (*OutgoingTrack, error) {
@@ -152,3 +155,11 @@ func newOutgoingTrack(forma format.Format, addTrack addTrackFunc) (*OutgoingTrac
 func (t *OutgoingTrack) WriteRTP(pkt *rtp.Packet) error {
 	return t.track.WriteRTP(pkt)
 }
+
+// WriteCaptions writes closed captions.
+// They are sent to the remote peer only if it opened the captions data channel.
+func (t *OutgoingTrack) WriteCaptions(data []captions.CCData) {
+	if t.captions != nil {
+		t.captions.write(data)
+	}
+}
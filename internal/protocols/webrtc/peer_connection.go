@@ -44,6 +44,7 @@ type PeerConnection struct {
 	closed            chan struct{}
 	gatheringDone     chan struct{}
 	incomingTrack     chan trackRecvPair
+	captions          *captionsSender
 }
 
 // Start starts the peer connection.
@@ -94,6 +95,16 @@ func (co *PeerConnection) Start() error {
 		})
 	}
 
+	co.captions = &captionsSender{}
+
+	co.wr.OnDataChannel(func(channel *webrtc.DataChannel) {
+		if channel.Label() == captionsChannelLabel {
+			channel.OnOpen(func() {
+				co.captions.setChannel(channel)
+			})
+		}
+	})
+
 	co.wr.OnConnectionStateChange(func(state webrtc.PeerConnectionState) {
 		co.stateChangeMutex.Lock()
 		defer co.stateChangeMutex.Unlock()
@@ -285,6 +296,10 @@ func (co *PeerConnection) SetupOutgoingTracks(
 				return nil, err
 			}
 
+			if forma == videoTrack {
+				track.captions = co.captions
+			}
+
 			tracks = append(tracks, track)
 		}
 	}
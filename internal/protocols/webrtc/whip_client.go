@@ -207,6 +207,11 @@ func (c *WHIPClient) Close() error {
 	return err
 }
 
+// BytesSent returns the number of bytes sent.
+func (c *WHIPClient) BytesSent() uint64 {
+	return c.pc.BytesSent()
+}
+
 // Wait waits for client errors.
 func (c *WHIPClient) Wait(ctx context.Context) error {
 	select {
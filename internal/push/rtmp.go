@@ -0,0 +1,63 @@
+package push
+
+import (
+	"context"
+	ctls "crypto/tls"
+	"net"
+	"net/url"
+	"time"
+
+	"github.com/bluenviron/mediamtx/internal/asyncwriter"
+	"github.com/bluenviron/mediamtx/internal/protocols/rtmp"
+	"github.com/bluenviron/mediamtx/internal/protocols/tls"
+)
+
+func (t *Target) runRTMP(u *url.URL) error {
+	// add default port
+	_, _, err := net.SplitHostPort(u.Host)
+	if err != nil {
+		if u.Scheme == "rtmp" {
+			u.Host = net.JoinHostPort(u.Host, "1935")
+		} else {
+			u.Host = net.JoinHostPort(u.Host, "1936")
+		}
+	}
+
+	nconn, err := func() (net.Conn, error) {
+		ctx2, cancel2 := context.WithTimeout(t.ctx, time.Duration(t.ReadTimeout))
+		defer cancel2()
+
+		if u.Scheme == "rtmp" {
+			return (&net.Dialer{}).DialContext(ctx2, "tcp", u.Host)
+		}
+
+		return (&ctls.Dialer{
+			Config: tls.ConfigForFingerprint(t.Conf.Fingerprint),
+		}).DialContext(ctx2, "tcp", u.Host)
+	}()
+	if err != nil {
+		return err
+	}
+	defer nconn.Close()
+
+	nconn.SetReadDeadline(time.Now().Add(time.Duration(t.ReadTimeout)))
+	nconn.SetWriteDeadline(time.Now().Add(time.Duration(t.WriteTimeout)))
+	conn, err := rtmp.NewClientConn(nconn, u, true)
+	if err != nil {
+		return err
+	}
+
+	writer := asyncwriter.New(t.WriteQueueSize, t)
+
+	defer t.Stream.RemoveReader(writer)
+
+	err = rtmp.FromStream(t.Stream, writer, conn, nconn, time.Duration(t.WriteTimeout))
+	if err != nil {
+		return err
+	}
+
+	// disable read deadline
+	nconn.SetReadDeadline(time.Time{})
+
+	return t.runWriter(writer, conn.BytesSent, nil)
+}
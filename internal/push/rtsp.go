@@ -0,0 +1,83 @@
+package push
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/bluenviron/gortsplib/v4"
+	"github.com/bluenviron/gortsplib/v4/pkg/base"
+	"github.com/bluenviron/gortsplib/v4/pkg/description"
+
+	"github.com/bluenviron/mediamtx/internal/asyncwriter"
+	"github.com/bluenviron/mediamtx/internal/logger"
+	"github.com/bluenviron/mediamtx/internal/protocols/tls"
+	"github.com/bluenviron/mediamtx/internal/unit"
+)
+
+func (t *Target) runRTSP() error {
+	c := &gortsplib.Client{
+		TLSConfig:      tls.ConfigForFingerprint(t.Conf.Fingerprint),
+		ReadTimeout:    time.Duration(t.ReadTimeout),
+		WriteTimeout:   time.Duration(t.WriteTimeout),
+		WriteQueueSize: t.WriteQueueSize,
+		OnRequest: func(req *base.Request) {
+			t.Log(logger.Debug, "[c->s] %v", req)
+		},
+		OnResponse: func(res *base.Response) {
+			t.Log(logger.Debug, "[s->c] %v", res)
+		},
+		OnTransportSwitch: func(err error) {
+			t.Log(logger.Warn, err.Error())
+		},
+	}
+
+	// the client writes control attributes into medias,
+	// therefore medias of the stream can't be shared with it.
+	desc := &description.Session{}
+	medias := make(map[*description.Media]*description.Media)
+
+	for _, medi := range t.Stream.Desc().Medias {
+		clone := &description.Media{
+			Type:    medi.Type,
+			ID:      medi.ID,
+			Formats: medi.Formats,
+		}
+		desc.Medias = append(desc.Medias, clone)
+		medias[medi] = clone
+	}
+
+	err := c.StartRecording(t.Conf.URL, desc)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	writer := asyncwriter.New(t.WriteQueueSize, t)
+
+	defer t.Stream.RemoveReader(writer)
+
+	for medi, clone := range medias {
+		cclone := clone
+
+		for _, forma := range medi.Formats {
+			t.Stream.AddReader(writer, medi, forma, func(u unit.Unit) error {
+				for _, pkt := range u.GetRTPPackets() {
+					err := c.WritePacketRTPWithNTP(cclone, pkt, u.GetNTP())
+					if err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+		}
+	}
+
+	connErr := make(chan error, 1)
+	go func() {
+		connErr <- c.Wait()
+	}()
+
+	return t.runWriter(writer, func() uint64 {
+		return atomic.LoadUint64(c.BytesSent)
+	}, connErr)
+}
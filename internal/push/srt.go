@@ -0,0 +1,53 @@
+package push
+
+import (
+	"bufio"
+	"time"
+
+	srt "github.com/datarhei/gosrt"
+
+	"github.com/bluenviron/mediamtx/internal/asyncwriter"
+	"github.com/bluenviron/mediamtx/internal/protocols/mpegts"
+)
+
+func srtMaxPayloadSize(u int) int {
+	return ((u - 16) / 188) * 188 // 16 = SRT header, 188 = MPEG-TS packet
+}
+
+func (t *Target) runSRT() error {
+	conf := srt.DefaultConfig()
+	address, err := conf.UnmarshalURL(t.Conf.URL)
+	if err != nil {
+		return err
+	}
+
+	conf.PayloadSize = uint32(srtMaxPayloadSize(t.UDPMaxPayloadSize))
+
+	err = conf.Validate()
+	if err != nil {
+		return err
+	}
+
+	sconn, err := srt.Dial("srt", address, conf)
+	if err != nil {
+		return err
+	}
+	defer sconn.Close()
+
+	writer := asyncwriter.New(t.WriteQueueSize, t)
+
+	defer t.Stream.RemoveReader(writer)
+
+	bw := bufio.NewWriterSize(sconn, srtMaxPayloadSize(t.UDPMaxPayloadSize))
+
+	err = mpegts.FromStream(t.Stream, t.Stream.Desc(), writer, bw, sconn, time.Duration(t.WriteTimeout))
+	if err != nil {
+		return err
+	}
+
+	return t.runWriter(writer, func() uint64 {
+		var s srt.Statistics
+		sconn.Stats(&s)
+		return s.Accumulated.ByteSent
+	}, nil)
+}
@@ -0,0 +1,232 @@
+// Package push contains the push targets.
+package push
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bluenviron/mediamtx/internal/asyncwriter"
+	"github.com/bluenviron/mediamtx/internal/conf"
+	"github.com/bluenviron/mediamtx/internal/defs"
+	"github.com/bluenviron/mediamtx/internal/logger"
+	"github.com/bluenviron/mediamtx/internal/stream"
+)
+
+// redactURL removes credentials, stream keys and query parameters from a URL,
+// since they can contain secrets.
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+
+	// RTMP stream keys are the last path segment
+	if u.Scheme == "rtmp" || u.Scheme == "rtmps" {
+		if i := strings.LastIndex(u.Path, "/"); i > 0 {
+			u.Path = u.Path[:i+1] + "xxxxx"
+			u.RawPath = ""
+		}
+	}
+
+	// SRT stream IDs and passphrases, WHIP tokens, ... are query parameters
+	if u.RawQuery != "" {
+		q := u.Query()
+		for k := range q {
+			q.Set(k, "xxxxx")
+		}
+		u.RawQuery = q.Encode()
+	}
+
+	return u.Redacted()
+}
+
+// Target pushes the stream of a path to a remote server.
+type Target struct {
+	Conf              conf.PushTarget
+	ReadTimeout       conf.StringDuration
+	WriteTimeout      conf.StringDuration
+	WriteQueueSize    int
+	UDPMaxPayloadSize int
+	Stream            *stream.Stream
+	Parent            logger.Writer
+
+	ctx       context.Context
+	ctxCancel func()
+	logPrefix string
+
+	mutex         sync.Mutex
+	state         defs.APIPathPushTargetState
+	retries       int
+	lastError     error
+	prevBytesSent uint64
+	bytesSent     func() uint64
+
+	done chan struct{}
+}
+
+// Initialize initializes Target.
+func (t *Target) Initialize() {
+	t.ctx, t.ctxCancel = context.WithCancel(context.Background())
+
+	// do not print credentials
+	t.logPrefix = redactURL(t.Conf.URL)
+
+	t.state = defs.APIPathPushTargetStateConnecting
+	t.done = make(chan struct{})
+
+	go t.run()
+}
+
+// Close closes the target.
+func (t *Target) Close() {
+	t.ctxCancel()
+	<-t.done
+}
+
+// Log implements logger.Writer.
+func (t *Target) Log(level logger.Level, format string, args ...interface{}) {
+	t.Parent.Log(level, "[push %s] "+format, append([]interface{}{t.logPrefix}, args...)...)
+}
+
+func (t *Target) setState(state defs.APIPathPushTargetState) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.state = state
+}
+
+func (t *Target) setBytesSent(bytesSent func() uint64) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.bytesSent != nil {
+		t.prevBytesSent += t.bytesSent()
+	}
+	t.bytesSent = bytesSent
+}
+
+func (t *Target) run() {
+	defer close(t.done)
+
+	for {
+		t.setState(defs.APIPathPushTargetStateConnecting)
+
+		err := t.runInner()
+
+		// the connection is not used anymore, freeze the byte counter
+		t.setBytesSent(nil)
+
+		if t.ctx.Err() != nil {
+			return
+		}
+
+		t.Log(logger.Error, err.Error())
+
+		t.mutex.Lock()
+		t.lastError = err
+		t.retries++
+		failed := t.Conf.MaxRetries != 0 && t.retries > t.Conf.MaxRetries
+		if failed {
+			t.state = defs.APIPathPushTargetStateFailed
+		} else {
+			t.state = defs.APIPathPushTargetStateWaiting
+		}
+		t.mutex.Unlock()
+
+		if failed {
+			t.Log(logger.Error, "maximum number of retries reached, giving up")
+			return
+		}
+
+		select {
+		case <-time.After(time.Duration(t.Conf.RetryPause)):
+		case <-t.ctx.Done():
+			return
+		}
+	}
+}
+
+func (t *Target) runInner() error {
+	t.Log(logger.Debug, "connecting")
+
+	u, err := url.Parse(t.Conf.URL)
+	if err != nil {
+		return err
+	}
+
+	switch u.Scheme {
+	case "rtmp", "rtmps":
+		return t.runRTMP(u)
+
+	case "rtsp", "rtsps":
+		return t.runRTSP()
+
+	case "srt":
+		return t.runSRT()
+
+	case "whip", "whips":
+		return t.runWebRTC(u)
+	}
+
+	return fmt.Errorf("unsupported scheme: %s", u.Scheme)
+}
+
+// runWriter starts the writer and waits until the connection fails or the target is closed.
+func (t *Target) runWriter(
+	writer *asyncwriter.Writer,
+	bytesSent func() uint64,
+	connErr <-chan error,
+) error {
+	t.setBytesSent(bytesSent)
+
+	t.mutex.Lock()
+	t.state = defs.APIPathPushTargetStatePushing
+	t.retries = 0
+	t.mutex.Unlock()
+
+	t.Log(logger.Info, "is pushing, %s",
+		defs.FormatsInfo(t.Stream.FormatsForReader(writer)))
+
+	writer.Start()
+
+	select {
+	case err := <-writer.Error():
+		return err
+
+	case err := <-connErr:
+		writer.Stop()
+		return err
+
+	case <-t.ctx.Done():
+		writer.Stop()
+		return fmt.Errorf("terminated")
+	}
+}
+
+// APIItem returns the API description of the target.
+func (t *Target) APIItem() defs.APIPathPushTarget {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	bytesSent := t.prevBytesSent
+	if t.bytesSent != nil {
+		bytesSent += t.bytesSent()
+	}
+
+	return defs.APIPathPushTarget{
+		URL:     redactURL(t.Conf.URL),
+		State:   t.state,
+		Retries: t.retries,
+		LastError: func() *string {
+			if t.lastError == nil {
+				return nil
+			}
+			v := t.lastError.Error()
+			return &v
+		}(),
+		BytesSent: bytesSent,
+	}
+}
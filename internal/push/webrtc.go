@@ -0,0 +1,68 @@
+package push
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/bluenviron/mediamtx/internal/asyncwriter"
+	"github.com/bluenviron/mediamtx/internal/protocols/tls"
+	"github.com/bluenviron/mediamtx/internal/protocols/webrtc"
+)
+
+func (t *Target) runWebRTC(u *url.URL) error {
+	u.Scheme = strings.ReplaceAll(u.Scheme, "whip", "http")
+
+	writer := asyncwriter.New(t.WriteQueueSize, t)
+
+	videoTrack, videoSetup := webrtc.FindVideoTrack(t.Stream, t.Stream.Desc(), writer)
+	audioTrack, audioSetup := webrtc.FindAudioTrack(t.Stream, t.Stream.Desc(), writer)
+
+	if videoTrack == nil && audioTrack == nil {
+		return webrtc.ErrNoSupportedCodecs
+	}
+
+	client := webrtc.WHIPClient{
+		HTTPClient: &http.Client{
+			Timeout: time.Duration(t.ReadTimeout),
+			Transport: &http.Transport{
+				TLSClientConfig: tls.ConfigForFingerprint(t.Conf.Fingerprint),
+			},
+		},
+		URL: u,
+		Log: t,
+	}
+
+	tracks, err := client.Publish(t.ctx, videoTrack, audioTrack)
+	if err != nil {
+		return err
+	}
+	defer client.Close() //nolint:errcheck
+
+	defer t.Stream.RemoveReader(writer)
+
+	n := 0
+
+	if videoTrack != nil {
+		err := videoSetup(tracks[n])
+		if err != nil {
+			return err
+		}
+		n++
+	}
+
+	if audioTrack != nil {
+		err := audioSetup(tracks[n])
+		if err != nil {
+			return err
+		}
+	}
+
+	connErr := make(chan error, 1)
+	go func() {
+		connErr <- client.Wait(t.ctx)
+	}()
+
+	return t.runWriter(writer, client.BytesSent, connErr)
+}
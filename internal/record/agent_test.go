@@ -73,11 +73,11 @@ func TestAgent(t *testing.T) {
 		},
 	}}
 
-	writeToStream := func(stream *stream.Stream, ntp time.Time) {
+	writeToStream := func(stream *stream.Stream, ntp time.Time, startPTS time.Duration) {
 		for i := 0; i < 3; i++ {
 			stream.WriteUnit(desc.Medias[0], desc.Medias[0].Formats[0], &unit.H265{
 				Base: unit.Base{
-					PTS: (50 + time.Duration(i)) * time.Second,
+					PTS: startPTS + time.Duration(i)*time.Second,
 					NTP: ntp.Add(time.Duration(i) * 60 * time.Second),
 				},
 				AU: [][]byte{
@@ -105,7 +105,7 @@ func TestAgent(t *testing.T) {
 
 			stream.WriteUnit(desc.Medias[1], desc.Medias[1].Formats[0], &unit.H264{
 				Base: unit.Base{
-					PTS: (50 + time.Duration(i)) * time.Second,
+					PTS: startPTS + time.Duration(i)*time.Second,
 				},
 				AU: [][]byte{
 					{ // SPS
@@ -122,21 +122,21 @@ func TestAgent(t *testing.T) {
 
 			stream.WriteUnit(desc.Medias[2], desc.Medias[2].Formats[0], &unit.MPEG4Audio{
 				Base: unit.Base{
-					PTS: (50 + time.Duration(i)) * time.Second,
+					PTS: startPTS + time.Duration(i)*time.Second,
 				},
 				AUs: [][]byte{{1, 2, 3, 4}},
 			})
 
 			stream.WriteUnit(desc.Medias[3], desc.Medias[3].Formats[0], &unit.G711{
 				Base: unit.Base{
-					PTS: (50 + time.Duration(i)) * time.Second,
+					PTS: startPTS + time.Duration(i)*time.Second,
 				},
 				Samples: []byte{1, 2, 3, 4},
 			})
 
 			stream.WriteUnit(desc.Medias[4], desc.Medias[4].Formats[0], &unit.G711{
 				Base: unit.Base{
-					PTS: (50 + time.Duration(i)) * time.Second,
+					PTS: startPTS + time.Duration(i)*time.Second,
 				},
 				Samples: []byte{1, 2, 3, 4},
 			})
@@ -149,6 +149,8 @@ func TestAgent(t *testing.T) {
 				1460,
 				desc,
 				true,
+				false,
+				0,
 				&nilLogger{},
 			)
 			require.NoError(t, err)
@@ -189,15 +191,18 @@ func TestAgent(t *testing.T) {
 			}
 			w.Initialize()
 
-			writeToStream(stream, time.Date(2008, 0o5, 20, 22, 15, 25, 0, time.UTC))
+			writeToStream(stream, time.Date(2008, 0o5, 20, 22, 15, 25, 0, time.UTC), 50*time.Second)
 
-			// simulate a write error
+			// simulate a write error.
+			// The access unit doesn't contain any slice, therefore the DTS extractor fails.
+			// Its timestamp is continuous with previous ones, otherwise the stream would
+			// rebase it instead of passing it to the recorder.
 			stream.WriteUnit(desc.Medias[1], desc.Medias[1].Formats[0], &unit.H264{
 				Base: unit.Base{
-					PTS: 0,
+					PTS: 53 * time.Second,
 				},
 				AU: [][]byte{
-					{5}, // IDR
+					{6}, // SEI
 				},
 			})
 
@@ -221,7 +226,7 @@ func TestAgent(t *testing.T) {
 
 			time.Sleep(50 * time.Millisecond)
 
-			writeToStream(stream, time.Date(2010, 0o5, 20, 22, 15, 25, 0, time.UTC))
+			writeToStream(stream, time.Date(2010, 0o5, 20, 22, 15, 25, 0, time.UTC), 60*time.Second)
 
 			time.Sleep(50 * time.Millisecond)
 
@@ -265,6 +270,8 @@ func TestAgentFMP4NegativeDTS(t *testing.T) {
 		1460,
 		desc,
 		true,
+		false,
+		0,
 		&nilLogger{},
 	)
 	require.NoError(t, err)
@@ -339,3 +346,137 @@ func TestAgentFMP4NegativeDTS(t *testing.T) {
 
 	require.Equal(t, true, found)
 }
+
+func TestAgentDiscontinuity(t *testing.T) {
+	desc := &description.Session{Medias: []*description.Media{
+		{
+			Type: description.MediaTypeVideo,
+			Formats: []rtspformat.Format{&rtspformat.H264{
+				PayloadTyp:        96,
+				PacketizationMode: 1,
+			}},
+		},
+	}}
+
+	for _, ca := range []string{"fmp4", "mpegts"} {
+		t.Run(ca, func(t *testing.T) {
+			stream, err := stream.New(
+				1460,
+				desc,
+				true,
+				false,
+				0,
+				&nilLogger{},
+			)
+			require.NoError(t, err)
+			defer stream.Close()
+
+			dir, err := os.MkdirTemp("", "mediamtx-agent")
+			require.NoError(t, err)
+			defer os.RemoveAll(dir)
+
+			recordPath := filepath.Join(dir, "%path/%Y-%m-%d_%H-%M-%S-%f")
+
+			segCreated := make(chan struct{}, 4)
+			segDone := make(chan struct{}, 4)
+
+			var f conf.RecordFormat
+			if ca == "fmp4" {
+				f = conf.RecordFormatFMP4
+			} else {
+				f = conf.RecordFormatMPEGTS
+			}
+
+			w := &Agent{
+				WriteQueueSize:  1024,
+				PathFormat:      recordPath,
+				Format:          f,
+				PartDuration:    100 * time.Millisecond,
+				SegmentDuration: 1 * time.Hour,
+				PathName:        "mypath",
+				Stream:          stream,
+				OnSegmentCreate: func(_ string) {
+					segCreated <- struct{}{}
+				},
+				OnSegmentComplete: func(_ string) {
+					segDone <- struct{}{}
+				},
+				Parent: &nilLogger{},
+			}
+			w.Initialize()
+
+			ntp := time.Date(2008, 0o5, 20, 22, 15, 25, 0, time.UTC)
+
+			// timestamps jump back after the third unit, as if the source restarted.
+			// The new segment must start with the next IDR.
+			for i, ca := range []struct {
+				pts time.Duration
+				idr bool
+			}{
+				{50 * time.Second, true},
+				{50*time.Second + 200*time.Millisecond, false},
+				{50*time.Second + 400*time.Millisecond, true},
+				{0, false},
+				{200 * time.Millisecond, false},
+				{400 * time.Millisecond, true},
+				{600 * time.Millisecond, false},
+				{800 * time.Millisecond, false},
+				{1000 * time.Millisecond, false},
+			} {
+				au := [][]byte{{1}} // non-IDR
+				if ca.idr {
+					au = [][]byte{
+						{ // SPS
+							0x67, 0x42, 0xc0, 0x28, 0xd9, 0x00, 0x78, 0x02,
+							0x27, 0xe5, 0x84, 0x00, 0x00, 0x03, 0x00, 0x04,
+							0x00, 0x00, 0x03, 0x00, 0xf0, 0x3c, 0x60, 0xc9, 0x20,
+						},
+						{ // PPS
+							0x08, 0x06, 0x07, 0x08,
+						},
+						{5}, // IDR
+					}
+				}
+
+				stream.WriteUnit(desc.Medias[0], desc.Medias[0].Formats[0], &unit.H264{
+					Base: unit.Base{
+						PTS: ca.pts,
+						NTP: ntp.Add(time.Duration(i) * time.Second),
+					},
+					AU: au,
+				})
+			}
+
+			// a new segment is started without waiting for the agent to restart
+			for _, ch := range []chan struct{}{segCreated, segDone, segCreated} {
+				select {
+				case <-ch:
+				case <-time.After(1 * time.Second):
+					t.Fatal("segment not started")
+				}
+			}
+
+			w.Close()
+
+			var ext string
+			if ca == "fmp4" {
+				ext = "mp4"
+			} else {
+				ext = "ts"
+			}
+
+			entries, err := os.ReadDir(filepath.Join(dir, "mypath"))
+			require.NoError(t, err)
+
+			var names []string
+			for _, e := range entries {
+				names = append(names, e.Name())
+			}
+
+			require.Equal(t, []string{
+				"2008-05-20_22-15-25-000000." + ext,
+				"2008-05-20_22-15-30-000000." + ext, // IDR that follows the discontinuity
+			}, names)
+		})
+	}
+}
@@ -25,6 +25,10 @@ type Cleaner struct {
 	Entries []CleanerEntry
 	Parent  logger.Writer
 
+	// called after a segment is removed.
+	// pathName is filled only when the record path contains %path.
+	OnSegmentDelete func(pathName string, segmentPath string)
+
 	ctx       context.Context
 	ctxCancel func()
 
@@ -100,7 +104,10 @@ func (c *Cleaner) doRunEntry(e *CleanerEntry) error {
 			if ok {
 				if now.Sub(time.Time(pa)) > e.DeleteAfter {
 					c.Log(logger.Debug, "removing %s", fpath)
-					os.Remove(fpath)
+					err := os.Remove(fpath)
+					if err == nil && c.OnSegmentDelete != nil {
+						c.OnSegmentDelete(decodeValues(entryPath, fpath)["%path"], fpath)
+					}
 				}
 			}
 		}
@@ -30,6 +30,8 @@ func TestCleaner(t *testing.T) {
 	err = os.WriteFile(filepath.Join(dir, specialChars+"_mypath", "2009-05-20_22-15-25-000427.mp4"), []byte{1}, 0o644)
 	require.NoError(t, err)
 
+	deleted := make(chan [2]string, 1)
+
 	c := &Cleaner{
 		Entries: []CleanerEntry{{
 			Path:        filepath.Join(dir, specialChars+"_%path/%Y-%m-%d_%H-%M-%S-%f"),
@@ -37,10 +39,17 @@ func TestCleaner(t *testing.T) {
 			DeleteAfter: 10 * time.Second,
 		}},
 		Parent: nilLogger{},
+		OnSegmentDelete: func(pathName string, segmentPath string) {
+			deleted <- [2]string{pathName, segmentPath}
+		},
 	}
 	c.Initialize()
 	defer c.Close()
 
+	d := <-deleted
+	require.Equal(t, "mypath", d[0])
+	require.Equal(t, filepath.Join(dir, specialChars+"_mypath", "2008-05-20_22-15-25-000125.mp4"), d[1])
+
 	time.Sleep(500 * time.Millisecond)
 
 	_, err = os.Stat(filepath.Join(dir, specialChars+"_mypath", "2008-05-20_22-15-25-000125.mp4"))
@@ -42,7 +42,9 @@ func (t *formatFMP4Track) record(sample *sample) error {
 
 	if (!t.f.hasVideo || t.initTrack.Codec.IsVideo()) &&
 		!t.nextSample.IsNonSyncSample &&
-		(t.nextSample.dts-t.f.currentSegment.startDTS) >= t.f.a.agent.SegmentDuration {
+		(t.f.splitPending || (t.nextSample.dts-t.f.currentSegment.startDTS) >= t.f.a.agent.SegmentDuration) {
+		t.f.splitPending = false
+
 		err := t.f.currentSegment.close()
 		if err != nil {
 			return err
@@ -7,6 +7,7 @@ import (
 	"io"
 	"time"
 
+	"github.com/bluenviron/gortsplib/v4/pkg/description"
 	rtspformat "github.com/bluenviron/gortsplib/v4/pkg/format"
 	"github.com/bluenviron/mediacommon/pkg/codecs/ac3"
 	"github.com/bluenviron/mediacommon/pkg/codecs/h264"
@@ -16,6 +17,8 @@ import (
 
 	"github.com/bluenviron/mediamtx/internal/defs"
 	"github.com/bluenviron/mediamtx/internal/logger"
+	"github.com/bluenviron/mediamtx/internal/metadata"
+	mtxmpegts "github.com/bluenviron/mediamtx/internal/protocols/mpegts"
 	"github.com/bluenviron/mediamtx/internal/unit"
 )
 
@@ -45,13 +48,16 @@ type formatMPEGTS struct {
 	dw             *dynamicWriter
 	bw             *bufio.Writer
 	mw             *mpegts.Writer
+	mdw            *mtxmpegts.MetadataWriter
 	hasVideo       bool
 	currentSegment *formatMPEGTSSegment
+	splitPending   bool
 }
 
 func (f *formatMPEGTS) initialize() {
 	var tracks []*mpegts.Track
 	var formats []rtspformat.Format
+	f.mdw = &mtxmpegts.MetadataWriter{}
 
 	addTrack := func(format rtspformat.Format, codec mpegts.Codec) *mpegts.Track {
 		track := &mpegts.Track{
@@ -63,6 +69,16 @@ func (f *formatMPEGTS) initialize() {
 		return track
 	}
 
+	addMetadataTrack := func(format rtspformat.Format, typ mtxmpegts.MetadataType) *mtxmpegts.MetadataTrack {
+		track := &mtxmpegts.MetadataTrack{
+			Type: typ,
+		}
+
+		f.mdw.Tracks = append(f.mdw.Tracks, track)
+		formats = append(formats, format)
+		return track
+	}
+
 	for _, media := range f.a.agent.Stream.Desc().Medias {
 		for _, forma := range media.Formats {
 			switch forma := forma.(type) {
@@ -71,7 +87,7 @@ func (f *formatMPEGTS) initialize() {
 
 				var dtsExtractor *h265.DTSExtractor
 
-				f.a.agent.Stream.AddReader(f.a.writer, media, forma, func(u unit.Unit) error {
+				f.addReader(media, forma, func(u unit.Unit) error {
 					tunit := u.(*unit.H265)
 					if tunit.AU == nil {
 						return nil
@@ -99,7 +115,7 @@ func (f *formatMPEGTS) initialize() {
 
 				var dtsExtractor *h264.DTSExtractor
 
-				f.a.agent.Stream.AddReader(f.a.writer, media, forma, func(u unit.Unit) error {
+				f.addReader(media, forma, func(u unit.Unit) error {
 					tunit := u.(*unit.H264)
 					if tunit.AU == nil {
 						return nil
@@ -128,7 +144,7 @@ func (f *formatMPEGTS) initialize() {
 				firstReceived := false
 				var lastPTS time.Duration
 
-				f.a.agent.Stream.AddReader(f.a.writer, media, forma, func(u unit.Unit) error {
+				f.addReader(media, forma, func(u unit.Unit) error {
 					tunit := u.(*unit.MPEG4Video)
 					if tunit.Frame == nil {
 						return nil
@@ -158,7 +174,7 @@ func (f *formatMPEGTS) initialize() {
 				firstReceived := false
 				var lastPTS time.Duration
 
-				f.a.agent.Stream.AddReader(f.a.writer, media, forma, func(u unit.Unit) error {
+				f.addReader(media, forma, func(u unit.Unit) error {
 					tunit := u.(*unit.MPEG1Video)
 					if tunit.Frame == nil {
 						return nil
@@ -192,7 +208,7 @@ func (f *formatMPEGTS) initialize() {
 					}(),
 				})
 
-				f.a.agent.Stream.AddReader(f.a.writer, media, forma, func(u unit.Unit) error {
+				f.addReader(media, forma, func(u unit.Unit) error {
 					tunit := u.(*unit.Opus)
 					if tunit.Packets == nil {
 						return nil
@@ -211,7 +227,7 @@ func (f *formatMPEGTS) initialize() {
 					Config: *forma.GetConfig(),
 				})
 
-				f.a.agent.Stream.AddReader(f.a.writer, media, forma, func(u unit.Unit) error {
+				f.addReader(media, forma, func(u unit.Unit) error {
 					tunit := u.(*unit.MPEG4Audio)
 					if tunit.AUs == nil {
 						return nil
@@ -228,7 +244,7 @@ func (f *formatMPEGTS) initialize() {
 			case *rtspformat.MPEG1Audio:
 				track := addTrack(forma, &mpegts.CodecMPEG1Audio{})
 
-				f.a.agent.Stream.AddReader(f.a.writer, media, forma, func(u unit.Unit) error {
+				f.addReader(media, forma, func(u unit.Unit) error {
 					tunit := u.(*unit.MPEG1Audio)
 					if tunit.Frames == nil {
 						return nil
@@ -247,7 +263,7 @@ func (f *formatMPEGTS) initialize() {
 
 				sampleRate := time.Duration(forma.SampleRate)
 
-				f.a.agent.Stream.AddReader(f.a.writer, media, forma, func(u unit.Unit) error {
+				f.addReader(media, forma, func(u unit.Unit) error {
 					tunit := u.(*unit.AC3)
 					if tunit.Frames == nil {
 						return nil
@@ -265,18 +281,64 @@ func (f *formatMPEGTS) initialize() {
 
 					return nil
 				})
+
+			case *rtspformat.Generic:
+				switch {
+				case metadata.IsKLV(forma):
+					track := addMetadataTrack(forma, mtxmpegts.MetadataTypeKLV)
+
+					f.addReader(media, forma, func(u unit.Unit) error {
+						tunit := u.(*unit.Generic)
+						if tunit.Payload == nil || f.currentSegment == nil {
+							return nil
+						}
+
+						return f.mdw.WriteKLV(track, durationGoToMPEGTS(tunit.PTS), tunit.Payload)
+					})
+
+				case metadata.IsSCTE35(forma):
+					track := addMetadataTrack(forma, mtxmpegts.MetadataTypeSCTE35)
+
+					f.addReader(media, forma, func(u unit.Unit) error {
+						tunit := u.(*unit.Generic)
+						if tunit.Payload == nil || f.currentSegment == nil {
+							return nil
+						}
+
+						return f.mdw.WriteSCTE35(track, tunit.Payload)
+					})
+				}
 			}
 		}
 	}
 
 	f.dw = &dynamicWriter{}
 	f.bw = bufio.NewWriterSize(f.dw, mpegtsMaxBufferSize)
-	f.mw = mpegts.NewWriter(f.bw, tracks)
+	f.mdw.W = f.bw
+	f.mw = mpegts.NewWriter(f.mdw, tracks)
+	f.mdw.Initialize(tracks)
 
 	f.a.agent.Log(logger.Info, "recording %s",
 		defs.FormatsInfo(formats))
 }
 
+// addReader adds a reader to the stream.
+// When timestamps of a track jump, a new segment is started at the next random access unit.
+func (f *formatMPEGTS) addReader(
+	medi *description.Media,
+	forma rtspformat.Format,
+	cb func(unit.Unit) error,
+) {
+	f.a.agent.Stream.AddReader(f.a.writer, medi, forma, func(u unit.Unit) error {
+		if u.GetDiscontinuity() && f.currentSegment != nil && !f.splitPending {
+			f.a.agent.Log(logger.Info, "timestamp discontinuity, starting a new segment")
+			f.splitPending = true
+		}
+
+		return cb(u)
+	})
+}
+
 func (f *formatMPEGTS) close() {
 	if f.currentSegment != nil {
 		f.currentSegment.close() //nolint:errcheck
@@ -299,7 +361,9 @@ func (f *formatMPEGTS) setupSegment(
 		f.currentSegment.initialize()
 	case (!f.hasVideo || isVideo) &&
 		randomAccess &&
-		(dts-f.currentSegment.startDTS) >= f.a.agent.SegmentDuration:
+		(f.splitPending || (dts-f.currentSegment.startDTS) >= f.a.agent.SegmentDuration):
+		f.splitPending = false
+
 		err := f.currentSegment.close()
 		if err != nil {
 			return err
@@ -62,11 +62,8 @@ func CommonPath(v string) string {
 	return common
 }
 
-// Path is a record path.
-type Path time.Time
-
-// Decode decodes a Path.
-func (p *Path) Decode(format string, v string) bool {
+// decodeValues extracts the values of placeholders from a record path.
+func decodeValues(format string, v string) map[string]string {
 	re := format
 
 	for _, ch := range []uint8{
@@ -130,7 +127,7 @@ func (p *Path) Decode(format string, v string) bool {
 
 	matches := r.FindStringSubmatch(v)
 	if matches == nil {
-		return false
+		return nil
 	}
 
 	values := make(map[string]string)
@@ -139,6 +136,19 @@ func (p *Path) Decode(format string, v string) bool {
 		values[groupMapping[i]] = match
 	}
 
+	return values
+}
+
+// Path is a record path.
+type Path time.Time
+
+// Decode decodes a Path.
+func (p *Path) Decode(format string, v string) bool {
+	values := decodeValues(format, v)
+	if values == nil {
+		return false
+	}
+
 	var year int
 	var month time.Month = 1
 	day := 1
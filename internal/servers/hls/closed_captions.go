@@ -0,0 +1,112 @@
+package hls
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/bluenviron/mediamtx/internal/captions"
+)
+
+const closedCaptionsGroupID = "cc"
+
+// closedCaptionsTracker stores the closed caption services found in the stream
+// and declares them in multivariant playlists with EXT-X-MEDIA.
+// Captions are left inside the video track, where they are read by players.
+type closedCaptionsTracker struct {
+	mutex    sync.Mutex
+	services []string
+}
+
+func (t *closedCaptionsTracker) add(data []captions.CCData) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	for _, cc := range data {
+		var service string
+
+		switch cc.Type {
+		case captions.CCTypeNTSCField1:
+			service = "CC1"
+
+		case captions.CCTypeNTSCField2:
+			service = "CC3"
+
+		default:
+			service = "SERVICE1"
+		}
+
+		// padding
+		if service != "SERVICE1" && cc.Data1&0x7F == 0 && cc.Data2&0x7F == 0 {
+			continue
+		}
+
+		if !t.has(service) {
+			t.services = append(t.services, service)
+		}
+	}
+}
+
+func (t *closedCaptionsTracker) has(service string) bool {
+	for _, cur := range t.services {
+		if cur == service {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *closedCaptionsTracker) active() bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return len(t.services) != 0
+}
+
+// process declares closed caption services in a multivariant playlist.
+func (t *closedCaptionsTracker) process(byts []byte) []byte {
+	s := string(byts)
+	if !strings.Contains(s, "#EXT-X-STREAM-INF:") {
+		return byts
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if len(t.services) == 0 {
+		return byts
+	}
+
+	var out []string
+	declared := false
+
+	for _, line := range strings.Split(s, "\n") {
+		if strings.HasPrefix(line, "#EXT-X-STREAM-INF:") {
+			if !declared {
+				declared = true
+				out = append(out, t.renditions()...)
+			}
+
+			line += ",CLOSED-CAPTIONS=\"" + closedCaptionsGroupID + "\""
+		}
+
+		out = append(out, line)
+	}
+
+	return []byte(strings.Join(out, "\n"))
+}
+
+func (t *closedCaptionsTracker) renditions() []string {
+	ret := make([]string, len(t.services))
+
+	for i, service := range t.services {
+		media := "#EXT-X-MEDIA:TYPE=CLOSED-CAPTIONS,GROUP-ID=\"" + closedCaptionsGroupID + "\"" +
+			",NAME=\"" + service + "\""
+		if i == 0 {
+			media += ",DEFAULT=YES"
+		}
+		media += ",AUTOSELECT=YES,INSTREAM-ID=\"" + service + "\""
+		ret[i] = media
+	}
+
+	return ret
+}
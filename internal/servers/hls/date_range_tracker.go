@@ -0,0 +1,253 @@
+package hls
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bluenviron/mediamtx/internal/metadata"
+)
+
+const (
+	// maximum number of date ranges kept in memory.
+	dateRangesMaxCount = 64
+
+	// same format of program date times.
+	dateRangeTimeFormat = "2006-01-02T15:04:05.999Z07:00"
+)
+
+type dateRange struct {
+	id              string
+	start           time.Time
+	end             *time.Time
+	plannedDuration *time.Duration
+	scte35Cmd       string
+	scte35Out       string
+	scte35In        string
+}
+
+func (r *dateRange) expiry() time.Time {
+	switch {
+	case r.end != nil:
+		return *r.end
+
+	case r.plannedDuration != nil:
+		return r.start.Add(*r.plannedDuration)
+
+	case r.scte35Out != "":
+		// the break is still in progress
+		return time.Time{}
+
+	default:
+		return r.start
+	}
+}
+
+func (r *dateRange) marshal() string {
+	ret := "#EXT-X-DATERANGE:ID=\"" + r.id + "\"" +
+		",START-DATE=\"" + r.start.UTC().Format(dateRangeTimeFormat) + "\""
+
+	if r.end != nil {
+		ret += ",END-DATE=\"" + r.end.UTC().Format(dateRangeTimeFormat) + "\""
+	}
+
+	if r.plannedDuration != nil {
+		ret += ",PLANNED-DURATION=" + strconv.FormatFloat(r.plannedDuration.Seconds(), 'f', 3, 64)
+	}
+
+	if r.scte35Cmd != "" {
+		ret += ",SCTE35-CMD=" + r.scte35Cmd
+	}
+
+	if r.scte35Out != "" {
+		ret += ",SCTE35-OUT=" + r.scte35Out
+	}
+
+	if r.scte35In != "" {
+		ret += ",SCTE35-IN=" + r.scte35In
+	}
+
+	return ret
+}
+
+// dateRangeTracker stores SCTE-35 cues of the stream
+// and signals them in media playlists with EXT-X-DATERANGE.
+type dateRangeTracker struct {
+	mutex  sync.Mutex
+	ranges []*dateRange
+}
+
+// addSCTE35 adds a splice_info_section.
+// ntp and pts are the timestamps of the unit that contains the section.
+func (t *dateRangeTracker) addSCTE35(ntp time.Time, pts time.Duration, section []byte) {
+	var s metadata.SCTE35
+	err := s.Unmarshal(section)
+	if err != nil {
+		return
+	}
+
+	start := ntp
+	if s.SpliceTime != nil {
+		// difference between splice time and unit PTS, taking into account 33-bit wrap-around
+		diff := (*s.SpliceTime - int64(pts.Seconds()*90000)) & (1<<33 - 1)
+		if diff >= 1<<32 {
+			diff -= 1 << 33
+		}
+		start = ntp.Add(time.Duration(diff) * time.Second / 90000)
+	}
+
+	encoded := "0x" + strings.ToUpper(hex.EncodeToString(section))
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	switch s.CommandType {
+	case metadata.SCTE35CommandTypeSpliceInsert:
+		id := "splice-" + strconv.FormatUint(uint64(s.EventID), 10)
+		existing := t.find(id)
+
+		switch {
+		case s.Cancel:
+			if existing != nil {
+				t.remove(existing)
+			}
+
+		case s.OutOfNetwork:
+			// cues are usually repeated
+			if existing != nil {
+				return
+			}
+
+			r := &dateRange{
+				id:        id,
+				start:     start,
+				scte35Out: encoded,
+			}
+
+			if s.BreakDuration != nil {
+				d := time.Duration(*s.BreakDuration) * time.Second / 90000
+				r.plannedDuration = &d
+			}
+
+			t.append(r)
+
+		default:
+			if existing != nil {
+				if existing.scte35In == "" && !start.Before(existing.start) {
+					existing.scte35In = encoded
+					existing.end = &start
+				}
+				return
+			}
+
+			t.append(&dateRange{
+				id:       id,
+				start:    start,
+				scte35In: encoded,
+			})
+		}
+
+	case metadata.SCTE35CommandTypeTimeSignal:
+		crc := binary.BigEndian.Uint32(section[len(section)-4:])
+		id := "scte35-" + strconv.FormatUint(uint64(crc), 16)
+
+		if t.find(id) != nil {
+			return
+		}
+
+		t.append(&dateRange{
+			id:        id,
+			start:     start,
+			scte35Cmd: encoded,
+		})
+	}
+}
+
+func (t *dateRangeTracker) find(id string) *dateRange {
+	for _, r := range t.ranges {
+		if r.id == id {
+			return r
+		}
+	}
+	return nil
+}
+
+func (t *dateRangeTracker) remove(r *dateRange) {
+	for i, cur := range t.ranges {
+		if cur == r {
+			t.ranges = append(t.ranges[:i], t.ranges[i+1:]...)
+			return
+		}
+	}
+}
+
+func (t *dateRangeTracker) append(r *dateRange) {
+	t.ranges = append(t.ranges, r)
+	if len(t.ranges) > dateRangesMaxCount {
+		t.ranges = t.ranges[1:]
+	}
+}
+
+func (t *dateRangeTracker) active() bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return len(t.ranges) != 0
+}
+
+// process inserts date ranges into a media playlist.
+// Date ranges are removed when they end before the first segment of the playlist.
+func (t *dateRangeTracker) process(byts []byte) []byte {
+	lines := strings.Split(string(byts), "\n")
+
+	segments := parseMediaPlaylistSegments(lines)
+	if len(segments) == 0 {
+		return byts
+	}
+
+	starts := segmentStarts(segments)
+	if starts == nil {
+		return byts
+	}
+
+	// in delta updates, the first segments are skipped
+	isDeltaUpdate := bytes.Contains(byts, []byte("#EXT-X-SKIP:"))
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if !isDeltaUpdate {
+		var ranges []*dateRange
+		for _, r := range t.ranges {
+			expiry := r.expiry()
+			if expiry.IsZero() || !expiry.Before(starts[0]) {
+				ranges = append(ranges, r)
+			}
+		}
+		t.ranges = ranges
+	}
+
+	if len(t.ranges) == 0 {
+		return byts
+	}
+
+	firstLine := segments[0].firstLine
+
+	out := make([]string, 0, len(lines)+len(t.ranges))
+
+	for i, line := range lines {
+		if i == firstLine {
+			for _, r := range t.ranges {
+				out = append(out, r.marshal())
+			}
+		}
+
+		out = append(out, line)
+	}
+
+	return []byte(strings.Join(out, "\n"))
+}
@@ -0,0 +1,155 @@
+package hls
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bluenviron/mediamtx/internal/metadata"
+)
+
+func mustDecodeSection(t *testing.T, enc string) []byte {
+	buf, err := base64.StdEncoding.DecodeString(enc)
+	require.NoError(t, err)
+	return buf
+}
+
+// editSection edits a splice_info_section and updates its CRC.
+func editSection(sec []byte, edit func(sec []byte)) []byte {
+	sec = append([]byte(nil), sec...)
+	edit(sec)
+	binary.BigEndian.PutUint32(sec[len(sec)-4:], metadata.CRC32MPEG2(sec[:len(sec)-4]))
+	return sec
+}
+
+func encodeSection(sec []byte) string {
+	return "0x" + strings.ToUpper(hex.EncodeToString(sec))
+}
+
+func TestDateRangeTracker(t *testing.T) {
+	ntp := time.Date(2015, 2, 5, 1, 2, 2, 0, time.UTC)
+
+	spliceOut := mustDecodeSection(t,
+		"/DAvAAAAAAAA///wFAVIAACPf+/+c2nALv4AUsz1AAAAAAAKAAhDVUVJAAABNWLbowo=")
+
+	spliceIn := editSection(spliceOut, func(sec []byte) {
+		sec[19] &^= 0x80 // out_of_network_indicator
+	})
+
+	spliceCancel := editSection(spliceOut, func(sec []byte) {
+		sec[18] |= 0x80 // splice_event_cancel_indicator
+	})
+
+	timeSignal := mustDecodeSection(t,
+		"/DA0AAAAAAAA///wBQb+cr0AUAAeAhxDVUVJSAAAjn/PAAGlmbAICAAAAAAsoKGKNAIAmsnRfg==")
+
+	// PTS of units that contain sections, 2 seconds before the splice time
+	spliceOutPTS := time.Duration(0x07369c02e-180000) * time.Second / 90000
+	timeSignalPTS := time.Duration(0x072bd0050-180000) * time.Second / 90000
+
+	playlist := "#EXTM3U\n" +
+		"#EXT-X-MEDIA-SEQUENCE:5\n" +
+		"#EXT-X-PROGRAM-DATE-TIME:2015-02-05T01:02:02Z\n" +
+		"#EXTINF:2.00000,\n" +
+		"seg5.mp4\n" +
+		"#EXTINF:2.00000,\n" +
+		"seg6.mp4\n"
+
+	withRanges := func(ranges ...string) string {
+		return "#EXTM3U\n" +
+			"#EXT-X-MEDIA-SEQUENCE:5\n" +
+			strings.Join(ranges, "\n") + "\n" +
+			"#EXT-X-PROGRAM-DATE-TIME:2015-02-05T01:02:02Z\n" +
+			"#EXTINF:2.00000,\n" +
+			"seg5.mp4\n" +
+			"#EXTINF:2.00000,\n" +
+			"seg6.mp4\n"
+	}
+
+	type section struct {
+		ntp time.Time
+		pts time.Duration
+		buf []byte
+	}
+
+	for _, ca := range []struct {
+		name     string
+		sections []section
+		in       string
+		out      string
+	}{
+		{
+			"splice out",
+			[]section{{ntp, spliceOutPTS, spliceOut}},
+			playlist,
+			withRanges(`#EXT-X-DATERANGE:ID="splice-1207959695",START-DATE="2015-02-05T01:02:04Z",` +
+				`PLANNED-DURATION=60.294,SCTE35-OUT=` + encodeSection(spliceOut)),
+		},
+		{
+			"splice out repeated",
+			[]section{
+				{ntp, spliceOutPTS, spliceOut},
+				{ntp.Add(time.Second), spliceOutPTS + time.Second, spliceOut},
+			},
+			playlist,
+			withRanges(`#EXT-X-DATERANGE:ID="splice-1207959695",START-DATE="2015-02-05T01:02:04Z",` +
+				`PLANNED-DURATION=60.294,SCTE35-OUT=` + encodeSection(spliceOut)),
+		},
+		{
+			"splice in",
+			[]section{
+				{ntp, spliceOutPTS, spliceOut},
+				{ntp, spliceOutPTS - 3*time.Second, spliceIn},
+			},
+			playlist,
+			withRanges(`#EXT-X-DATERANGE:ID="splice-1207959695",START-DATE="2015-02-05T01:02:04Z",` +
+				`END-DATE="2015-02-05T01:02:07Z",PLANNED-DURATION=60.294,` +
+				`SCTE35-OUT=` + encodeSection(spliceOut) + `,SCTE35-IN=` + encodeSection(spliceIn)),
+		},
+		{
+			"splice cancel",
+			[]section{
+				{ntp, spliceOutPTS, spliceOut},
+				{ntp, spliceOutPTS, spliceCancel},
+			},
+			playlist,
+			playlist,
+		},
+		{
+			"time signal",
+			[]section{{ntp, timeSignalPTS, timeSignal}},
+			playlist,
+			withRanges(`#EXT-X-DATERANGE:ID="scte35-` +
+				strings.TrimLeft(hex.EncodeToString(timeSignal[len(timeSignal)-4:]), "0") +
+				`",START-DATE="2015-02-05T01:02:04Z",SCTE35-CMD=` + encodeSection(timeSignal)),
+		},
+		{
+			"expired",
+			[]section{{ntp.Add(-70 * time.Second), spliceOutPTS, spliceOut}},
+			playlist,
+			playlist,
+		},
+		{
+			"invalid section",
+			[]section{{ntp, spliceOutPTS, spliceOut[:20]}},
+			playlist,
+			playlist,
+		},
+	} {
+		t.Run(ca.name, func(t *testing.T) {
+			tr := &dateRangeTracker{}
+
+			for _, sec := range ca.sections {
+				tr.addSCTE35(sec.ntp, sec.pts, sec.buf)
+			}
+
+			require.Equal(t, ca.out, string(tr.process([]byte(ca.in))))
+			require.Equal(t, ca.out != ca.in, tr.active())
+		})
+	}
+}
@@ -0,0 +1,205 @@
+package hls
+
+import (
+	"bytes"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// program date times are written with millisecond precision.
+const discontinuityTolerance = 1 * time.Millisecond
+
+type playlistSegment struct {
+	firstLine int
+	dateTime  *time.Time
+	duration  time.Duration
+}
+
+// parseMediaPlaylistSegments returns the segments of a media playlist.
+func parseMediaPlaylistSegments(lines []string) []*playlistSegment {
+	var segments []*playlistSegment
+	cur := &playlistSegment{firstLine: -1}
+
+	for i, line := range lines {
+		switch {
+		case line == "":
+
+		case strings.HasPrefix(line, "#EXT-X-PROGRAM-DATE-TIME:"):
+			if cur.firstLine < 0 {
+				cur.firstLine = i
+			}
+			t, err := time.Parse(time.RFC3339Nano, line[len("#EXT-X-PROGRAM-DATE-TIME:"):])
+			if err == nil {
+				cur.dateTime = &t
+			}
+
+		case strings.HasPrefix(line, "#EXTINF:"):
+			if cur.firstLine < 0 {
+				cur.firstLine = i
+			}
+			v := strings.Split(line[len("#EXTINF:"):], ",")[0]
+			secs, err := strconv.ParseFloat(v, 64)
+			if err == nil {
+				cur.duration = time.Duration(secs * float64(time.Second))
+			}
+
+		case strings.HasPrefix(line, "#EXT-X-GAP"),
+			strings.HasPrefix(line, "#EXT-X-BITRATE:"),
+			strings.HasPrefix(line, "#EXT-X-PART:"):
+			if cur.firstLine < 0 {
+				cur.firstLine = i
+			}
+
+		case line[0] != '#':
+			segments = append(segments, cur)
+			cur = &playlistSegment{firstLine: -1}
+		}
+	}
+
+	return segments
+}
+
+// segmentStarts returns the start time of each segment.
+// Program date times are present in a subset of segments only,
+// therefore start times of the others are computed from durations.
+func segmentStarts(segments []*playlistSegment) []time.Time {
+	ref := -1
+	for i, seg := range segments {
+		if seg.dateTime != nil {
+			ref = i
+			break
+		}
+	}
+	if ref < 0 {
+		return nil
+	}
+
+	starts := make([]time.Time, len(segments))
+	starts[ref] = *segments[ref].dateTime
+
+	for i := ref - 1; i >= 0; i-- {
+		starts[i] = starts[i+1].Add(-segments[i].duration)
+	}
+
+	for i := ref + 1; i < len(segments); i++ {
+		if segments[i].dateTime != nil {
+			starts[i] = *segments[i].dateTime
+		} else {
+			starts[i] = starts[i-1].Add(segments[i-1].duration)
+		}
+	}
+
+	return starts
+}
+
+// discontinuityTracker stores timestamp discontinuities of the stream
+// and signals them in media playlists with EXT-X-DISCONTINUITY.
+type discontinuityTracker struct {
+	mutex sync.Mutex
+
+	// NTP timestamps of discontinuities that have not left the playlist yet.
+	pending []time.Time
+
+	// discontinuities that have left the playlist.
+	sequence int
+}
+
+func (t *discontinuityTracker) add(ntp time.Time) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.pending = append(t.pending, ntp)
+	sort.Slice(t.pending, func(i, j int) bool {
+		return t.pending[i].Before(t.pending[j])
+	})
+}
+
+func (t *discontinuityTracker) active() bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return len(t.pending) != 0 || t.sequence != 0
+}
+
+// process inserts discontinuities into a media playlist.
+// A discontinuity is placed before the first segment that starts after it.
+func (t *discontinuityTracker) process(byts []byte) []byte {
+	lines := strings.Split(string(byts), "\n")
+
+	segments := parseMediaPlaylistSegments(lines)
+	if len(segments) == 0 {
+		return byts
+	}
+
+	starts := segmentStarts(segments)
+	if starts == nil {
+		return byts
+	}
+
+	// in delta updates, the first segments are skipped
+	isDeltaUpdate := bytes.Contains(byts, []byte("#EXT-X-SKIP:"))
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	tagged := make(map[int]struct{})
+	var pending []time.Time
+
+	for _, ntp := range t.pending {
+		index := -1
+		for i, start := range starts {
+			if !start.Before(ntp.Add(-discontinuityTolerance)) {
+				index = i
+				break
+			}
+		}
+
+		// the segment that follows the discontinuity has not been generated yet
+		if index < 0 {
+			pending = append(pending, ntp)
+			continue
+		}
+
+		// discontinuities of multiple tracks are merged
+		if _, ok := tagged[index]; ok {
+			continue
+		}
+		tagged[index] = struct{}{}
+
+		// the segment that precedes the discontinuity has left the playlist
+		if index == 0 && !isDeltaUpdate {
+			t.sequence++
+			continue
+		}
+
+		pending = append(pending, ntp)
+	}
+
+	t.pending = pending
+
+	taggedLines := make(map[int]struct{})
+	for index := range tagged {
+		if index != 0 {
+			taggedLines[segments[index].firstLine] = struct{}{}
+		}
+	}
+
+	var out []string
+
+	for i, line := range lines {
+		if _, ok := taggedLines[i]; ok {
+			out = append(out, "#EXT-X-DISCONTINUITY")
+		}
+
+		out = append(out, line)
+
+		if t.sequence != 0 && strings.HasPrefix(line, "#EXT-X-MEDIA-SEQUENCE:") {
+			out = append(out, "#EXT-X-DISCONTINUITY-SEQUENCE:"+strconv.FormatInt(int64(t.sequence), 10))
+		}
+	}
+
+	return []byte(strings.Join(out, "\n"))
+}
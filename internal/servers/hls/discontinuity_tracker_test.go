@@ -0,0 +1,214 @@
+package hls
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiscontinuityTrackerProcess(t *testing.T) {
+	base := time.Date(2015, 2, 5, 1, 2, 2, 0, time.UTC)
+
+	playlist := "#EXTM3U\n" +
+		"#EXT-X-VERSION:9\n" +
+		"#EXT-X-TARGETDURATION:2\n" +
+		"#EXT-X-MEDIA-SEQUENCE:5\n" +
+		"#EXT-X-PROGRAM-DATE-TIME:2015-02-05T01:02:02Z\n" +
+		"#EXTINF:2.00000,\n" +
+		"seg5.mp4\n" +
+		"#EXT-X-PROGRAM-DATE-TIME:2015-02-05T01:02:04Z\n" +
+		"#EXTINF:2.00000,\n" +
+		"seg6.mp4\n" +
+		"#EXTINF:2.00000,\n" +
+		"seg7.mp4\n"
+
+	for _, ca := range []struct {
+		name            string
+		discontinuities []time.Duration
+		in              string
+		out             string
+		active          bool
+	}{
+		{
+			"none",
+			nil,
+			playlist,
+			playlist,
+			false,
+		},
+		{
+			"program date time",
+			[]time.Duration{2 * time.Second},
+			playlist,
+			"#EXTM3U\n" +
+				"#EXT-X-VERSION:9\n" +
+				"#EXT-X-TARGETDURATION:2\n" +
+				"#EXT-X-MEDIA-SEQUENCE:5\n" +
+				"#EXT-X-PROGRAM-DATE-TIME:2015-02-05T01:02:02Z\n" +
+				"#EXTINF:2.00000,\n" +
+				"seg5.mp4\n" +
+				"#EXT-X-DISCONTINUITY\n" +
+				"#EXT-X-PROGRAM-DATE-TIME:2015-02-05T01:02:04Z\n" +
+				"#EXTINF:2.00000,\n" +
+				"seg6.mp4\n" +
+				"#EXTINF:2.00000,\n" +
+				"seg7.mp4\n",
+			true,
+		},
+		{
+			"interpolated start",
+			[]time.Duration{3 * time.Second},
+			playlist,
+			"#EXTM3U\n" +
+				"#EXT-X-VERSION:9\n" +
+				"#EXT-X-TARGETDURATION:2\n" +
+				"#EXT-X-MEDIA-SEQUENCE:5\n" +
+				"#EXT-X-PROGRAM-DATE-TIME:2015-02-05T01:02:02Z\n" +
+				"#EXTINF:2.00000,\n" +
+				"seg5.mp4\n" +
+				"#EXT-X-PROGRAM-DATE-TIME:2015-02-05T01:02:04Z\n" +
+				"#EXTINF:2.00000,\n" +
+				"seg6.mp4\n" +
+				"#EXT-X-DISCONTINUITY\n" +
+				"#EXTINF:2.00000,\n" +
+				"seg7.mp4\n",
+			true,
+		},
+		{
+			"tracks merged",
+			[]time.Duration{2 * time.Second, 2*time.Second + 500*time.Microsecond},
+			playlist,
+			"#EXTM3U\n" +
+				"#EXT-X-VERSION:9\n" +
+				"#EXT-X-TARGETDURATION:2\n" +
+				"#EXT-X-MEDIA-SEQUENCE:5\n" +
+				"#EXT-X-PROGRAM-DATE-TIME:2015-02-05T01:02:02Z\n" +
+				"#EXTINF:2.00000,\n" +
+				"seg5.mp4\n" +
+				"#EXT-X-DISCONTINUITY\n" +
+				"#EXT-X-PROGRAM-DATE-TIME:2015-02-05T01:02:04Z\n" +
+				"#EXTINF:2.00000,\n" +
+				"seg6.mp4\n" +
+				"#EXTINF:2.00000,\n" +
+				"seg7.mp4\n",
+			true,
+		},
+		{
+			"left playlist",
+			[]time.Duration{-1 * time.Second},
+			playlist,
+			"#EXTM3U\n" +
+				"#EXT-X-VERSION:9\n" +
+				"#EXT-X-TARGETDURATION:2\n" +
+				"#EXT-X-MEDIA-SEQUENCE:5\n" +
+				"#EXT-X-DISCONTINUITY-SEQUENCE:1\n" +
+				"#EXT-X-PROGRAM-DATE-TIME:2015-02-05T01:02:02Z\n" +
+				"#EXTINF:2.00000,\n" +
+				"seg5.mp4\n" +
+				"#EXT-X-PROGRAM-DATE-TIME:2015-02-05T01:02:04Z\n" +
+				"#EXTINF:2.00000,\n" +
+				"seg6.mp4\n" +
+				"#EXTINF:2.00000,\n" +
+				"seg7.mp4\n",
+			true,
+		},
+		{
+			"delta update",
+			[]time.Duration{-1 * time.Second},
+			"#EXTM3U\n" +
+				"#EXT-X-VERSION:9\n" +
+				"#EXT-X-TARGETDURATION:2\n" +
+				"#EXT-X-MEDIA-SEQUENCE:2\n" +
+				"#EXT-X-SKIP:SKIPPED-SEGMENTS=3\n" +
+				"#EXT-X-PROGRAM-DATE-TIME:2015-02-05T01:02:02Z\n" +
+				"#EXTINF:2.00000,\n" +
+				"seg5.mp4\n",
+			"#EXTM3U\n" +
+				"#EXT-X-VERSION:9\n" +
+				"#EXT-X-TARGETDURATION:2\n" +
+				"#EXT-X-MEDIA-SEQUENCE:2\n" +
+				"#EXT-X-SKIP:SKIPPED-SEGMENTS=3\n" +
+				"#EXT-X-PROGRAM-DATE-TIME:2015-02-05T01:02:02Z\n" +
+				"#EXTINF:2.00000,\n" +
+				"seg5.mp4\n",
+			true,
+		},
+		{
+			"future segment",
+			[]time.Duration{10 * time.Second},
+			playlist,
+			playlist,
+			true,
+		},
+	} {
+		t.Run(ca.name, func(t *testing.T) {
+			tr := &discontinuityTracker{}
+
+			for _, d := range ca.discontinuities {
+				tr.add(base.Add(d))
+			}
+
+			out := tr.process([]byte(ca.in))
+			require.Equal(t, ca.out, string(out))
+			require.Equal(t, ca.active, tr.active())
+		})
+	}
+}
+
+func TestDiscontinuityTrackerPending(t *testing.T) {
+	base := time.Date(2015, 2, 5, 1, 2, 2, 0, time.UTC)
+
+	tr := &discontinuityTracker{}
+	tr.add(base.Add(4 * time.Second))
+
+	// the segment that follows the discontinuity has not been generated yet
+	in := "#EXTM3U\n" +
+		"#EXT-X-MEDIA-SEQUENCE:5\n" +
+		"#EXT-X-PROGRAM-DATE-TIME:2015-02-05T01:02:02Z\n" +
+		"#EXTINF:2.00000,\n" +
+		"seg5.mp4\n"
+	require.Equal(t, in, string(tr.process([]byte(in))))
+
+	in = "#EXTM3U\n" +
+		"#EXT-X-MEDIA-SEQUENCE:5\n" +
+		"#EXT-X-PROGRAM-DATE-TIME:2015-02-05T01:02:02Z\n" +
+		"#EXTINF:2.00000,\n" +
+		"seg5.mp4\n" +
+		"#EXTINF:2.00000,\n" +
+		"seg6.mp4\n"
+	require.Equal(t, in, string(tr.process([]byte(in))))
+
+	in = "#EXTM3U\n" +
+		"#EXT-X-MEDIA-SEQUENCE:5\n" +
+		"#EXT-X-PROGRAM-DATE-TIME:2015-02-05T01:02:02Z\n" +
+		"#EXTINF:2.00000,\n" +
+		"seg5.mp4\n" +
+		"#EXTINF:2.00000,\n" +
+		"seg6.mp4\n" +
+		"#EXTINF:2.00000,\n" +
+		"seg7.mp4\n"
+	require.Equal(t, "#EXTM3U\n"+
+		"#EXT-X-MEDIA-SEQUENCE:5\n"+
+		"#EXT-X-PROGRAM-DATE-TIME:2015-02-05T01:02:02Z\n"+
+		"#EXTINF:2.00000,\n"+
+		"seg5.mp4\n"+
+		"#EXTINF:2.00000,\n"+
+		"seg6.mp4\n"+
+		"#EXT-X-DISCONTINUITY\n"+
+		"#EXTINF:2.00000,\n"+
+		"seg7.mp4\n", string(tr.process([]byte(in))))
+
+	// the segment that precedes the discontinuity leaves the playlist
+	in = "#EXTM3U\n" +
+		"#EXT-X-MEDIA-SEQUENCE:7\n" +
+		"#EXT-X-PROGRAM-DATE-TIME:2015-02-05T01:02:06Z\n" +
+		"#EXTINF:2.00000,\n" +
+		"seg7.mp4\n"
+	require.Equal(t, "#EXTM3U\n"+
+		"#EXT-X-MEDIA-SEQUENCE:7\n"+
+		"#EXT-X-DISCONTINUITY-SEQUENCE:1\n"+
+		"#EXT-X-PROGRAM-DATE-TIME:2015-02-05T01:02:06Z\n"+
+		"#EXTINF:2.00000,\n"+
+		"seg7.mp4\n", string(tr.process([]byte(in))))
+}
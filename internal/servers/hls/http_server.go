@@ -17,6 +17,7 @@ import (
 	"github.com/bluenviron/mediamtx/internal/logger"
 	"github.com/bluenviron/mediamtx/internal/protocols/httpserv"
 	"github.com/bluenviron/mediamtx/internal/restrictnetwork"
+	"github.com/bluenviron/mediamtx/internal/stream"
 )
 
 const (
@@ -145,6 +146,24 @@ func (s *httpServer) onRequest(ctx *gin.Context) {
 		return
 	}
 
+	tracks, err := conf.TrackSelectionFromQuery(ctx.Request.URL.RawQuery)
+	if err != nil {
+		s.Log(logger.Info, err.Error())
+		ctx.Writer.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	timeshift, err := stream.TimeshiftFromQuery(ctx.Request.URL.RawQuery)
+	if err != nil {
+		s.Log(logger.Info, err.Error())
+		ctx.Writer.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	// each delay requires a dedicated muxer, therefore
+	// delays are rounded in order to share muxers between readers.
+	timeshift = timeshift.Round(time.Second)
+
 	user, pass, hasCredentials := ctx.Request.BasicAuth()
 
 	res := s.pathManager.FindPathConf(defs.PathFindPathConfReq{
@@ -193,9 +212,11 @@ func (s *httpServer) onRequest(ctx *gin.Context) {
 
 	default:
 		s.parent.handleRequest(muxerHandleRequestReq{
-			path: dir,
-			file: fname,
-			ctx:  ctx,
+			path:      dir,
+			tracks:    tracks,
+			timeshift: timeshift,
+			file:      fname,
+			ctx:       ctx,
 		})
 	}
 }
@@ -7,12 +7,14 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/bluenviron/gohlslib"
 	"github.com/bluenviron/gohlslib/pkg/codecs"
+	"github.com/bluenviron/gortsplib/v4/pkg/description"
 	"github.com/bluenviron/gortsplib/v4/pkg/format"
 	"github.com/gin-gonic/gin"
 
@@ -20,6 +22,7 @@ import (
 	"github.com/bluenviron/mediamtx/internal/conf"
 	"github.com/bluenviron/mediamtx/internal/defs"
 	"github.com/bluenviron/mediamtx/internal/logger"
+	"github.com/bluenviron/mediamtx/internal/metadata"
 	"github.com/bluenviron/mediamtx/internal/stream"
 	"github.com/bluenviron/mediamtx/internal/unit"
 )
@@ -52,10 +55,38 @@ func (w *responseWriterWithCounter) Write(p []byte) (int, error) {
 }
 
 type muxerHandleRequestReq struct {
-	path string
-	file string
-	ctx  *gin.Context
-	res  chan *muxer
+	path      string
+	tracks    *conf.TrackSelection
+	timeshift time.Duration
+	file      string
+	ctx       *gin.Context
+	res       chan *muxer
+}
+
+// muxerQuery returns the query that identifies a secondary muxer of a path.
+func muxerQuery(tracks *conf.TrackSelection, timeshift time.Duration) string {
+	var entries []string
+
+	if tracks != nil {
+		entries = append(entries, "tracks="+tracks.String())
+	}
+
+	if timeshift != 0 {
+		entries = append(entries, "timeshift="+timeshift.String())
+	}
+
+	return strings.Join(entries, "&")
+}
+
+// muxerName returns the name of the muxer of a path.
+// Readers that select tracks or that are timeshifted use a dedicated muxer
+// for each track selection and delay, rounded to seconds.
+func muxerName(pathName string, tracks *conf.TrackSelection, timeshift time.Duration) string {
+	q := muxerQuery(tracks, timeshift)
+	if q == "" {
+		return pathName
+	}
+	return pathName + "?" + q
 }
 
 type muxer struct {
@@ -69,8 +100,11 @@ type muxer struct {
 	segmentMaxSize            conf.StringSize
 	directory                 string
 	writeQueueSize            int
+	writeQueueOverflow        conf.WriteQueueOverflow
 	wg                        *sync.WaitGroup
 	pathName                  string
+	tracks                    *conf.TrackSelection
+	timeshift                 time.Duration
 	pathManager               defs.PathManager
 	parent                    *Server
 
@@ -78,9 +112,13 @@ type muxer struct {
 	ctxCancel       func()
 	created         time.Time
 	path            defs.Path
+	writerMutex     sync.RWMutex
 	writer          *asyncwriter.Writer
 	lastRequestTime *int64
 	muxer           *gohlslib.Muxer
+	discontinuities *discontinuityTracker
+	closedCaptions  *closedCaptionsTracker
+	dateRanges      *dateRangeTracker
 	requests        []*muxerHandleRequestReq
 	bytesSent       *uint64
 
@@ -115,7 +153,7 @@ func (m *muxer) Close() {
 
 // Log implements logger.Writer.
 func (m *muxer) Log(level logger.Level, format string, args ...interface{}) {
-	m.parent.Log(level, "[muxer %s] "+format, append([]interface{}{m.pathName}, args...)...)
+	m.parent.Log(level, "[muxer %s] "+format, append([]interface{}{m.name()}, args...)...)
 }
 
 // PathName returns the path name.
@@ -123,6 +161,10 @@ func (m *muxer) PathName() string {
 	return m.pathName
 }
 
+func (m *muxer) name() string {
+	return muxerName(m.pathName, m.tracks, m.timeshift)
+}
+
 func (m *muxer) run() {
 	defer m.wg.Done()
 
@@ -227,12 +269,29 @@ func (m *muxer) runInner(innerCtx context.Context, innerReady chan struct{}) err
 
 	defer m.path.RemoveReader(defs.PathRemoveReaderReq{Author: m})
 
-	m.writer = asyncwriter.New(m.writeQueueSize, m)
+	writer := asyncwriter.New(m.writeQueueSize, m)
+	writer.SetOverflowPolicy(m.writeQueueOverflow)
+
+	m.writerMutex.Lock()
+	m.writer = writer
+	m.writerMutex.Unlock()
 
 	defer res.Stream.RemoveReader(m.writer)
 
-	videoTrack := m.createVideoTrack(res.Stream)
-	audioTrack := m.createAudioTrack(res.Stream)
+	if m.timeshift != 0 {
+		err := res.Stream.SetReaderTimeshift(m.writer, m.timeshift)
+		if err != nil {
+			return err
+		}
+	}
+
+	desc, err := m.tracks.Apply(res.Stream.Desc())
+	if err != nil {
+		return err
+	}
+
+	videoTrack := m.createVideoTrack(res.Stream, desc)
+	audioTrack := m.createAudioTrack(res.Stream, desc)
 
 	if videoTrack == nil && audioTrack == nil {
 		return fmt.Errorf(
@@ -242,10 +301,22 @@ func (m *muxer) runInner(innerCtx context.Context, innerReady chan struct{}) err
 	var muxerDirectory string
 	if m.directory != "" {
 		muxerDirectory = filepath.Join(m.directory, m.pathName)
+		if m.tracks != nil {
+			muxerDirectory = filepath.Join(muxerDirectory, "tracks"+m.tracks.String())
+		}
+		if m.timeshift != 0 {
+			muxerDirectory = filepath.Join(muxerDirectory, "timeshift"+m.timeshift.String())
+		}
 		os.MkdirAll(muxerDirectory, 0o755)
 		defer os.Remove(muxerDirectory)
 	}
 
+	m.discontinuities = &discontinuityTracker{}
+	m.closedCaptions = &closedCaptionsTracker{}
+	m.dateRanges = &dateRangeTracker{}
+
+	m.addSCTE35Readers(res.Stream, desc)
+
 	m.muxer = &gohlslib.Muxer{
 		Variant:         gohlslib.MuxerVariant(m.variant),
 		SegmentCount:    m.segmentCount,
@@ -257,7 +328,7 @@ func (m *muxer) runInner(innerCtx context.Context, innerReady chan struct{}) err
 		Directory:       muxerDirectory,
 	}
 
-	err := m.muxer.Start()
+	err = m.muxer.Start()
 	if err != nil {
 		return fmt.Errorf("muxer error: %w", err)
 	}
@@ -294,12 +365,49 @@ func (m *muxer) runInner(innerCtx context.Context, innerReady chan struct{}) err
 	}
 }
 
-func (m *muxer) createVideoTrack(stream *stream.Stream) *gohlslib.Track {
+// addReader adds a reader to the stream, keeping track of timestamp discontinuities.
+func (m *muxer) addReader(
+	stream *stream.Stream,
+	medi *description.Media,
+	forma format.Format,
+	cb func(unit.Unit) error,
+) {
+	stream.AddReader(m.writer, medi, forma, func(u unit.Unit) error {
+		if u.GetDiscontinuity() {
+			m.discontinuities.add(u.GetNTP())
+		}
+
+		return cb(u)
+	})
+}
+
+// addSCTE35Readers reads SCTE-35 cues, that are signaled in playlists as date ranges.
+func (m *muxer) addSCTE35Readers(stream *stream.Stream, desc *description.Session) {
+	for _, medi := range desc.Medias {
+		for _, forma := range medi.Formats {
+			if !metadata.IsSCTE35(forma) {
+				continue
+			}
+
+			m.addReader(stream, medi, forma, func(u unit.Unit) error {
+				tunit := u.(*unit.Generic)
+
+				if tunit.Payload != nil {
+					m.dateRanges.addSCTE35(tunit.NTP, tunit.PTS, tunit.Payload)
+				}
+
+				return nil
+			})
+		}
+	}
+}
+
+func (m *muxer) createVideoTrack(stream *stream.Stream, desc *description.Session) *gohlslib.Track {
 	var videoFormatAV1 *format.AV1
-	videoMedia := stream.Desc().FindFormat(&videoFormatAV1)
+	videoMedia := desc.FindFormat(&videoFormatAV1)
 
 	if videoFormatAV1 != nil {
-		stream.AddReader(m.writer, videoMedia, videoFormatAV1, func(u unit.Unit) error {
+		m.addReader(stream, videoMedia, videoFormatAV1, func(u unit.Unit) error {
 			tunit := u.(*unit.AV1)
 
 			if tunit.TU == nil {
@@ -320,10 +428,10 @@ func (m *muxer) createVideoTrack(stream *stream.Stream) *gohlslib.Track {
 	}
 
 	var videoFormatVP9 *format.VP9
-	videoMedia = stream.Desc().FindFormat(&videoFormatVP9)
+	videoMedia = desc.FindFormat(&videoFormatVP9)
 
 	if videoFormatVP9 != nil {
-		stream.AddReader(m.writer, videoMedia, videoFormatVP9, func(u unit.Unit) error {
+		m.addReader(stream, videoMedia, videoFormatVP9, func(u unit.Unit) error {
 			tunit := u.(*unit.VP9)
 
 			if tunit.Frame == nil {
@@ -344,16 +452,20 @@ func (m *muxer) createVideoTrack(stream *stream.Stream) *gohlslib.Track {
 	}
 
 	var videoFormatH265 *format.H265
-	videoMedia = stream.Desc().FindFormat(&videoFormatH265)
+	videoMedia = desc.FindFormat(&videoFormatH265)
 
 	if videoFormatH265 != nil {
-		stream.AddReader(m.writer, videoMedia, videoFormatH265, func(u unit.Unit) error {
+		m.addReader(stream, videoMedia, videoFormatH265, func(u unit.Unit) error {
 			tunit := u.(*unit.H265)
 
 			if tunit.AU == nil {
 				return nil
 			}
 
+			if tunit.Captions != nil {
+				m.closedCaptions.add(tunit.Captions)
+			}
+
 			err := m.muxer.WriteH26x(tunit.NTP, tunit.PTS, tunit.AU)
 			if err != nil {
 				return fmt.Errorf("muxer error: %w", err)
@@ -374,16 +486,20 @@ func (m *muxer) createVideoTrack(stream *stream.Stream) *gohlslib.Track {
 	}
 
 	var videoFormatH264 *format.H264
-	videoMedia = stream.Desc().FindFormat(&videoFormatH264)
+	videoMedia = desc.FindFormat(&videoFormatH264)
 
 	if videoFormatH264 != nil {
-		stream.AddReader(m.writer, videoMedia, videoFormatH264, func(u unit.Unit) error {
+		m.addReader(stream, videoMedia, videoFormatH264, func(u unit.Unit) error {
 			tunit := u.(*unit.H264)
 
 			if tunit.AU == nil {
 				return nil
 			}
 
+			if tunit.Captions != nil {
+				m.closedCaptions.add(tunit.Captions)
+			}
+
 			err := m.muxer.WriteH26x(tunit.NTP, tunit.PTS, tunit.AU)
 			if err != nil {
 				return fmt.Errorf("muxer error: %w", err)
@@ -405,12 +521,12 @@ func (m *muxer) createVideoTrack(stream *stream.Stream) *gohlslib.Track {
 	return nil
 }
 
-func (m *muxer) createAudioTrack(stream *stream.Stream) *gohlslib.Track {
+func (m *muxer) createAudioTrack(stream *stream.Stream, desc *description.Session) *gohlslib.Track {
 	var audioFormatOpus *format.Opus
-	audioMedia := stream.Desc().FindFormat(&audioFormatOpus)
+	audioMedia := desc.FindFormat(&audioFormatOpus)
 
 	if audioMedia != nil {
-		stream.AddReader(m.writer, audioMedia, audioFormatOpus, func(u unit.Unit) error {
+		m.addReader(stream, audioMedia, audioFormatOpus, func(u unit.Unit) error {
 			tunit := u.(*unit.Opus)
 
 			err := m.muxer.WriteOpus(
@@ -437,10 +553,10 @@ func (m *muxer) createAudioTrack(stream *stream.Stream) *gohlslib.Track {
 	}
 
 	var audioFormatMPEG4Audio *format.MPEG4Audio
-	audioMedia = stream.Desc().FindFormat(&audioFormatMPEG4Audio)
+	audioMedia = desc.FindFormat(&audioFormatMPEG4Audio)
 
 	if audioMedia != nil {
-		stream.AddReader(m.writer, audioMedia, audioFormatMPEG4Audio, func(u unit.Unit) error {
+		m.addReader(stream, audioMedia, audioFormatMPEG4Audio, func(u unit.Unit) error {
 			tunit := u.(*unit.MPEG4Audio)
 
 			if tunit.AUs == nil {
@@ -476,6 +592,28 @@ func (m *muxer) handleRequest(ctx *gin.Context) {
 		bytesSent:      m.bytesSent,
 	}
 
+	if strings.HasSuffix(ctx.Request.URL.Path, ".m3u8") &&
+		(m.tracks != nil || m.timeshift != 0 || m.discontinuities.active() || m.closedCaptions.active() ||
+			m.dateRanges.active()) {
+		pw := &playlistWriter{
+			ResponseWriter: w,
+			rewrite: func(byts []byte) []byte {
+				byts = m.discontinuities.process(byts)
+				byts = m.closedCaptions.process(byts)
+				byts = m.dateRanges.process(byts)
+
+				if q := muxerQuery(m.tracks, m.timeshift); q != "" {
+					byts = appendQueryToPlaylist(byts, q)
+				}
+
+				return byts
+			},
+		}
+		m.muxer.Handle(pw, ctx.Request)
+		pw.flush()
+		return
+	}
+
 	m.muxer.Handle(w, ctx.Request)
 }
 
@@ -497,10 +635,19 @@ func (m *muxer) APIReaderDescribe() defs.APIPathSourceOrReader {
 }
 
 func (m *muxer) apiItem() *defs.APIHLSMuxer {
+	unitsDropped := uint64(0)
+
+	m.writerMutex.RLock()
+	if m.writer != nil {
+		unitsDropped = m.writer.DroppedUnits()
+	}
+	m.writerMutex.RUnlock()
+
 	return &defs.APIHLSMuxer{
-		Path:        m.pathName,
-		Created:     m.created,
-		LastRequest: time.Unix(0, atomic.LoadInt64(m.lastRequestTime)),
-		BytesSent:   atomic.LoadUint64(m.bytesSent),
+		Path:         m.pathName,
+		Created:      m.created,
+		LastRequest:  time.Unix(0, atomic.LoadInt64(m.lastRequestTime)),
+		BytesSent:    atomic.LoadUint64(m.bytesSent),
+		UnitsDropped: unitsDropped,
 	}
 }
@@ -0,0 +1,60 @@
+package hls
+
+import (
+	"bytes"
+	"net/http"
+	"regexp"
+)
+
+var playlistURIAttribute = regexp.MustCompile(`URI="([^"?]*)"`)
+
+// appendQueryToPlaylist appends a query to all URIs of a playlist.
+// This is needed since URIs are relative and clients drop the query of the playlist when resolving them.
+func appendQueryToPlaylist(byts []byte, query string) []byte {
+	lines := bytes.Split(byts, []byte("\n"))
+
+	for i, line := range lines {
+		switch {
+		case len(line) == 0:
+
+		case line[0] == '#':
+			lines[i] = playlistURIAttribute.ReplaceAll(line, []byte(`URI="$1?`+query+`"`))
+
+		default:
+			lines[i] = append(line, []byte("?"+query)...)
+		}
+	}
+
+	return bytes.Join(lines, []byte("\n"))
+}
+
+// playlistWriter is a http.ResponseWriter that buffers playlists in order to rewrite them.
+type playlistWriter struct {
+	http.ResponseWriter
+	rewrite func([]byte) []byte
+
+	statusCode int
+	buf        bytes.Buffer
+}
+
+func (w *playlistWriter) WriteHeader(statusCode int) {
+	w.statusCode = statusCode
+}
+
+func (w *playlistWriter) Write(p []byte) (int, error) {
+	return w.buf.Write(p)
+}
+
+func (w *playlistWriter) flush() {
+	if w.statusCode == 0 {
+		w.statusCode = http.StatusOK
+	}
+
+	w.ResponseWriter.WriteHeader(w.statusCode)
+
+	if w.statusCode == http.StatusOK {
+		w.ResponseWriter.Write(w.rewrite(w.buf.Bytes())) //nolint:errcheck
+	} else {
+		w.ResponseWriter.Write(w.buf.Bytes()) //nolint:errcheck
+	}
+}
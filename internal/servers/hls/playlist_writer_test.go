@@ -0,0 +1,88 @@
+package hls
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppendQueryToPlaylist(t *testing.T) {
+	in := "#EXTM3U\n" +
+		"#EXT-X-VERSION:9\n" +
+		"#EXT-X-MAP:URI=\"init.mp4\"\n" +
+		"#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID=\"audio\",URI=\"audio.m3u8\"\n" +
+		"#EXT-X-PART:DURATION=0.20000,URI=\"part0.mp4\"\n" +
+		"#EXT-X-PRELOAD-HINT:TYPE=PART,URI=\"part1.mp4?existing=1\"\n" +
+		"#EXTINF:2.00000,\n" +
+		"seg5.mp4\n"
+
+	require.Equal(t, "#EXTM3U\n"+
+		"#EXT-X-VERSION:9\n"+
+		"#EXT-X-MAP:URI=\"init.mp4?timeshift=10s\"\n"+
+		"#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID=\"audio\",URI=\"audio.m3u8?timeshift=10s\"\n"+
+		"#EXT-X-PART:DURATION=0.20000,URI=\"part0.mp4?timeshift=10s\"\n"+
+		"#EXT-X-PRELOAD-HINT:TYPE=PART,URI=\"part1.mp4?existing=1\"\n"+
+		"#EXTINF:2.00000,\n"+
+		"seg5.mp4?timeshift=10s\n",
+		string(appendQueryToPlaylist([]byte(in), "timeshift=10s")))
+}
+
+func TestPlaylistWriter(t *testing.T) {
+	rewrite := func(byts []byte) []byte {
+		return append(byts, []byte("seg6.mp4\n")...)
+	}
+
+	for _, ca := range []struct {
+		name       string
+		statusCode int
+		out        string
+	}{
+		{
+			"default status",
+			0,
+			"#EXTM3U\nseg5.mp4\nseg6.mp4\n",
+		},
+		{
+			"ok",
+			http.StatusOK,
+			"#EXTM3U\nseg5.mp4\nseg6.mp4\n",
+		},
+		{
+			"error",
+			http.StatusNotFound,
+			"#EXTM3U\nseg5.mp4\n",
+		},
+	} {
+		t.Run(ca.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+
+			w := &playlistWriter{
+				ResponseWriter: rec,
+				rewrite:        rewrite,
+			}
+
+			if ca.statusCode != 0 {
+				w.WriteHeader(ca.statusCode)
+			}
+
+			// the response is written only when flushing
+			_, err := w.Write([]byte("#EXTM3U\n"))
+			require.NoError(t, err)
+			_, err = w.Write([]byte("seg5.mp4\n"))
+			require.NoError(t, err)
+			require.False(t, rec.Flushed)
+			require.Equal(t, 0, rec.Body.Len())
+
+			w.flush()
+
+			statusCode := ca.statusCode
+			if statusCode == 0 {
+				statusCode = http.StatusOK
+			}
+			require.Equal(t, statusCode, rec.Code)
+			require.Equal(t, ca.out, rec.Body.String())
+		})
+	}
+}
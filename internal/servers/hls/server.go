@@ -7,12 +7,17 @@ import (
 	"fmt"
 	"sort"
 	"sync"
+	"time"
 
 	"github.com/bluenviron/mediamtx/internal/conf"
 	"github.com/bluenviron/mediamtx/internal/defs"
 	"github.com/bluenviron/mediamtx/internal/logger"
 )
 
+// maximum number of secondary muxers of a path,
+// that serve readers that select tracks or that are timeshifted.
+const maxSecondaryMuxers = 8
+
 // ErrMuxerNotFound is returned when a muxer is not found.
 var ErrMuxerNotFound = errors.New("muxer not found")
 
@@ -57,6 +62,7 @@ type Server struct {
 	Directory                 string
 	ReadTimeout               conf.StringDuration
 	WriteQueueSize            int
+	WriteQueueOverflow        conf.WriteQueueOverflow
 	PathManager               defs.PathManager
 	Parent                    serverParent
 
@@ -135,7 +141,7 @@ outer:
 		case pa := <-s.chPathReady:
 			if s.AlwaysRemux && !pa.SafeConf().SourceOnDemand {
 				if _, ok := s.muxers[pa.Name()]; !ok {
-					s.createMuxer(pa.Name(), "")
+					s.createMuxer(pa.Name(), nil, 0, "")
 				}
 			}
 
@@ -147,21 +153,25 @@ outer:
 			}
 
 		case req := <-s.chHandleRequest:
-			r, ok := s.muxers[req.path]
+			r, ok := s.muxers[muxerName(req.path, req.tracks, req.timeshift)]
 			switch {
 			case ok:
 				r.processRequest(&req)
 
+			case (req.tracks != nil || req.timeshift != 0) && s.secondaryMuxerCount(req.path) >= maxSecondaryMuxers:
+				s.Log(logger.Warn, "path '%s' has too many secondary muxers, discarding request", req.path)
+				req.res <- nil
+
 			default:
-				r := s.createMuxer(req.path, req.ctx.ClientIP())
+				r := s.createMuxer(req.path, req.tracks, req.timeshift, req.ctx.ClientIP())
 				r.processRequest(&req)
 			}
 
 		case c := <-s.chCloseMuxer:
-			if c2, ok := s.muxers[c.PathName()]; !ok || c2 != c {
+			if c2, ok := s.muxers[c.name()]; !ok || c2 != c {
 				continue
 			}
-			delete(s.muxers, c.PathName())
+			delete(s.muxers, c.name())
 
 		case req := <-s.chAPIMuxerList:
 			data := &defs.APIHLSMuxerList{
@@ -199,7 +209,12 @@ outer:
 	s.httpServer.close()
 }
 
-func (s *Server) createMuxer(pathName string, remoteAddr string) *muxer {
+func (s *Server) createMuxer(
+	pathName string,
+	tracks *conf.TrackSelection,
+	timeshift time.Duration,
+	remoteAddr string,
+) *muxer {
 	r := &muxer{
 		parentCtx:                 s.ctx,
 		remoteAddr:                remoteAddr,
@@ -211,16 +226,29 @@ func (s *Server) createMuxer(pathName string, remoteAddr string) *muxer {
 		segmentMaxSize:            s.SegmentMaxSize,
 		directory:                 s.Directory,
 		writeQueueSize:            s.WriteQueueSize,
+		writeQueueOverflow:        s.WriteQueueOverflow,
 		wg:                        &s.wg,
 		pathName:                  pathName,
+		tracks:                    tracks,
+		timeshift:                 timeshift,
 		pathManager:               s.PathManager,
 		parent:                    s,
 	}
 	r.initialize()
-	s.muxers[pathName] = r
+	s.muxers[r.name()] = r
 	return r
 }
 
+func (s *Server) secondaryMuxerCount(pathName string) int {
+	n := 0
+	for _, m := range s.muxers {
+		if m.pathName == pathName && (m.tracks != nil || m.timeshift != 0) {
+			n++
+		}
+	}
+	return n
+}
+
 // closeMuxer is called by muxer.
 func (s *Server) closeMuxer(c *muxer) {
 	select {
@@ -17,6 +17,7 @@ import (
 	"github.com/bluenviron/mediamtx/internal/externalcmd"
 	"github.com/bluenviron/mediamtx/internal/logger"
 	"github.com/bluenviron/mediamtx/internal/restrictnetwork"
+	"github.com/bluenviron/mediamtx/internal/webhook"
 )
 
 // ErrConnNotFound is returned when a connection is not found.
@@ -60,6 +61,7 @@ type Server struct {
 	ReadTimeout         conf.StringDuration
 	WriteTimeout        conf.StringDuration
 	WriteQueueSize      int
+	WriteQueueOverflow  conf.WriteQueueOverflow
 	IsTLS               bool
 	ServerCert          string
 	ServerKey           string
@@ -68,6 +70,7 @@ type Server struct {
 	RunOnConnectRestart bool
 	RunOnDisconnect     string
 	ExternalCmdPool     *externalcmd.Pool
+	WebhookSender       *webhook.Sender
 	PathManager         defs.PathManager
 	Parent              serverParent
 
@@ -167,12 +170,14 @@ outer:
 				readTimeout:         s.ReadTimeout,
 				writeTimeout:        s.WriteTimeout,
 				writeQueueSize:      s.WriteQueueSize,
+				writeQueueOverflow:  s.WriteQueueOverflow,
 				runOnConnect:        s.RunOnConnect,
 				runOnConnectRestart: s.RunOnConnectRestart,
 				runOnDisconnect:     s.RunOnDisconnect,
 				wg:                  &s.wg,
 				nconn:               nconn,
 				externalCmdPool:     s.ExternalCmdPool,
+				webhookSender:       s.WebhookSender,
 				pathManager:         s.PathManager,
 				parent:              s,
 			}
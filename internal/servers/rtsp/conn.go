@@ -17,6 +17,8 @@ import (
 	"github.com/bluenviron/mediamtx/internal/externalcmd"
 	"github.com/bluenviron/mediamtx/internal/hooks"
 	"github.com/bluenviron/mediamtx/internal/logger"
+	"github.com/bluenviron/mediamtx/internal/stream"
+	"github.com/bluenviron/mediamtx/internal/webhook"
 )
 
 const (
@@ -32,6 +34,7 @@ type conn struct {
 	runOnConnectRestart bool
 	runOnDisconnect     string
 	externalCmdPool     *externalcmd.Pool
+	webhookSender       *webhook.Sender
 	pathManager         defs.PathManager
 	rconn               *gortsplib.ServerConn
 	rserver             *gortsplib.Server
@@ -63,6 +66,7 @@ func (c *conn) initialize() {
 	c.onDisconnectHook = hooks.OnConnect(hooks.OnConnectParams{
 		Logger:              c,
 		ExternalCmdPool:     c.externalCmdPool,
+		WebhookSender:       c.webhookSender,
 		RunOnConnect:        c.runOnConnect,
 		RunOnConnectRestart: c.runOnConnectRestart,
 		RunOnDisconnect:     c.runOnDisconnect,
@@ -166,11 +170,11 @@ func (c *conn) onDescribe(ctx *gortsplib.ServerHandlerOnDescribeCtx,
 		}, nil, nil
 	}
 
-	var stream *gortsplib.ServerStream
-	if !c.isTLS {
-		stream = res.Stream.RTSPStream(c.rserver)
-	} else {
-		stream = res.Stream.RTSPSStream(c.rserver)
+	stream, err := serverStreamForReader(res.Stream, c.rserver, c.isTLS, ctx.Query)
+	if err != nil {
+		return &base.Response{
+			StatusCode: base.StatusBadRequest,
+		}, nil, err
 	}
 
 	return &base.Response{
@@ -178,6 +182,35 @@ func (c *conn) onDescribe(ctx *gortsplib.ServerHandlerOnDescribeCtx,
 	}, stream, nil
 }
 
+// serverStreamForReader returns the RTSP stream that contains the tracks selected by the reader.
+func serverStreamForReader(
+	strm *stream.Stream,
+	server *gortsplib.Server,
+	isTLS bool,
+	query string,
+) (*gortsplib.ServerStream, error) {
+	ts, err := conf.TrackSelectionFromQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	if ts != nil {
+		desc, err := ts.Apply(strm.Desc())
+		if err != nil {
+			return nil, err
+		}
+
+		if desc != strm.Desc() {
+			return strm.FilteredRTSPStream(server, desc), nil
+		}
+	}
+
+	if !isTLS {
+		return strm.RTSPStream(server), nil
+	}
+	return strm.RTSPSStream(server), nil
+}
+
 func (c *conn) handleAuthError(authErr error) (*base.Response, error) {
 	c.authFailures++
 
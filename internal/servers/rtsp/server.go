@@ -21,6 +21,7 @@ import (
 	"github.com/bluenviron/mediamtx/internal/defs"
 	"github.com/bluenviron/mediamtx/internal/externalcmd"
 	"github.com/bluenviron/mediamtx/internal/logger"
+	"github.com/bluenviron/mediamtx/internal/webhook"
 )
 
 // ErrConnNotFound is returned when a connection is not found.
@@ -72,6 +73,7 @@ type Server struct {
 	RunOnConnectRestart bool
 	RunOnDisconnect     string
 	ExternalCmdPool     *externalcmd.Pool
+	WebhookSender       *webhook.Sender
 	PathManager         defs.PathManager
 	Parent              serverParent
 
@@ -184,6 +186,7 @@ func (s *Server) OnConnOpen(ctx *gortsplib.ServerHandlerOnConnOpenCtx) {
 		runOnConnectRestart: s.RunOnConnectRestart,
 		runOnDisconnect:     s.RunOnDisconnect,
 		externalCmdPool:     s.ExternalCmdPool,
+		webhookSender:       s.WebhookSender,
 		pathManager:         s.PathManager,
 		rconn:               ctx.Conn,
 		rserver:             s.srv,
@@ -227,6 +230,7 @@ func (s *Server) OnSessionOpen(ctx *gortsplib.ServerHandlerOnSessionOpenCtx) {
 		rconn:           ctx.Conn,
 		rserver:         s.srv,
 		externalCmdPool: s.ExternalCmdPool,
+		webhookSender:   s.WebhookSender,
 		pathManager:     s.PathManager,
 		parent:          s,
 	}
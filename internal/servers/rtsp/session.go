@@ -11,6 +11,7 @@ import (
 	"github.com/bluenviron/gortsplib/v4"
 	"github.com/bluenviron/gortsplib/v4/pkg/auth"
 	"github.com/bluenviron/gortsplib/v4/pkg/base"
+	"github.com/bluenviron/gortsplib/v4/pkg/description"
 	"github.com/google/uuid"
 	"github.com/pion/rtp"
 
@@ -20,6 +21,7 @@ import (
 	"github.com/bluenviron/mediamtx/internal/hooks"
 	"github.com/bluenviron/mediamtx/internal/logger"
 	"github.com/bluenviron/mediamtx/internal/stream"
+	"github.com/bluenviron/mediamtx/internal/webhook"
 )
 
 type session struct {
@@ -29,6 +31,7 @@ type session struct {
 	rconn           *gortsplib.ServerConn
 	rserver         *gortsplib.Server
 	externalCmdPool *externalcmd.Pool
+	webhookSender   *webhook.Sender
 	pathManager     defs.PathManager
 	parent          *Server
 
@@ -44,6 +47,9 @@ type session struct {
 	query           string
 	decodeErrLogger logger.Writer
 	writeErrLogger  logger.Writer
+	timeshift       time.Duration
+	timeshiftStream *gortsplib.ServerStream
+	timeshiftReader *stream.TimeshiftRTPReader
 }
 
 func (s *session) initialize() {
@@ -77,6 +83,16 @@ func (s *session) onClose(err error) {
 		s.onUnreadHook()
 	}
 
+	if s.timeshiftReader != nil {
+		s.timeshiftReader.Close()
+		s.timeshiftReader = nil
+	}
+
+	if s.timeshiftStream != nil {
+		s.timeshiftStream.Close()
+		s.timeshiftStream = nil
+	}
+
 	switch s.rsession.State() {
 	case gortsplib.ServerSessionStatePrePlay, gortsplib.ServerSessionStatePlay:
 		s.path.RemoveReader(defs.PathRemoveReaderReq{Author: s})
@@ -238,11 +254,22 @@ func (s *session) onSetup(c *conn, ctx *gortsplib.ServerHandlerOnSetupCtx,
 		s.query = ctx.Query
 		s.mutex.Unlock()
 
-		var stream *gortsplib.ServerStream
-		if !s.isTLS {
-			stream = res.Stream.RTSPStream(s.rserver)
-		} else {
-			stream = res.Stream.RTSPSStream(s.rserver)
+		timeshift, err := stream.TimeshiftFromQuery(ctx.Query)
+		if err != nil {
+			return &base.Response{
+				StatusCode: base.StatusBadRequest,
+			}, nil, err
+		}
+
+		if timeshift != 0 {
+			return s.setupTimeshift(ctx, timeshift)
+		}
+
+		stream, err := serverStreamForReader(res.Stream, s.rserver, s.isTLS, ctx.Query)
+		if err != nil {
+			return &base.Response{
+				StatusCode: base.StatusBadRequest,
+			}, nil, err
 		}
 
 		return &base.Response{
@@ -256,11 +283,71 @@ func (s *session) onSetup(c *conn, ctx *gortsplib.ServerHandlerOnSetupCtx,
 	}
 }
 
+// setupTimeshift returns a dedicated RTSP stream, that is fed by the timeshift buffer
+// once the session starts playing.
+func (s *session) setupTimeshift(ctx *gortsplib.ServerHandlerOnSetupCtx, timeshift time.Duration,
+) (*base.Response, *gortsplib.ServerStream, error) {
+	// packets are written into the session, that doesn't have a write buffer with multicast.
+	if ctx.Transport == gortsplib.TransportUDPMulticast {
+		return &base.Response{
+			StatusCode: base.StatusUnsupportedTransport,
+		}, nil, fmt.Errorf("timeshift is not supported with multicast")
+	}
+
+	if s.timeshiftStream == nil {
+		ts, err := conf.TrackSelectionFromQuery(ctx.Query)
+		if err != nil {
+			return &base.Response{
+				StatusCode: base.StatusBadRequest,
+			}, nil, err
+		}
+
+		desc, err := ts.Apply(s.stream.Desc())
+		if err != nil {
+			return &base.Response{
+				StatusCode: base.StatusBadRequest,
+			}, nil, err
+		}
+
+		s.timeshift = timeshift
+		s.timeshiftStream = gortsplib.NewServerStream(s.rserver, desc)
+	}
+
+	return &base.Response{
+		StatusCode: base.StatusOK,
+	}, s.timeshiftStream, nil
+}
+
+func (s *session) startTimeshift() error {
+	medias := make(map[*description.Media]struct{})
+	for _, medi := range s.rsession.SetuppedMedias() {
+		medias[medi] = struct{}{}
+	}
+
+	var err error
+	s.timeshiftReader, err = s.stream.NewTimeshiftRTPReader(s.timeshift,
+		func(medi *description.Media, pkt *rtp.Packet, _ time.Time) {
+			if _, ok := medias[medi]; ok {
+				s.rsession.WritePacketRTP(medi, pkt) //nolint:errcheck
+			}
+		})
+	return err
+}
+
 // onPlay is called by rtspServer.
 func (s *session) onPlay(_ *gortsplib.ServerHandlerOnPlayCtx) (*base.Response, error) {
 	h := make(base.Header)
 
 	if s.rsession.State() == gortsplib.ServerSessionStatePrePlay {
+		if s.timeshiftStream != nil {
+			err := s.startTimeshift()
+			if err != nil {
+				return &base.Response{
+					StatusCode: base.StatusBadRequest,
+				}, err
+			}
+		}
+
 		s.Log(logger.Info, "is reading from path '%s', with %s, %s",
 			s.path.Name(),
 			s.rsession.SetuppedTransport(),
@@ -269,6 +356,7 @@ func (s *session) onPlay(_ *gortsplib.ServerHandlerOnPlayCtx) (*base.Response, e
 		s.onUnreadHook = hooks.OnRead(hooks.OnReadParams{
 			Logger:          s,
 			ExternalCmdPool: s.externalCmdPool,
+			WebhookSender:   s.webhookSender,
 			Conf:            s.path.SafeConf(),
 			ExternalCmdEnv:  s.path.ExternalCmdEnv(),
 			Reader:          s.APIReaderDescribe(),
@@ -334,6 +422,11 @@ func (s *session) onPause(_ *gortsplib.ServerHandlerOnPauseCtx) (*base.Response,
 	case gortsplib.ServerSessionStatePlay:
 		s.onUnreadHook()
 
+		if s.timeshiftReader != nil {
+			s.timeshiftReader.Close()
+			s.timeshiftReader = nil
+		}
+
 		s.mutex.Lock()
 		s.state = gortsplib.ServerSessionStatePrePlay
 		s.mutex.Unlock()
@@ -22,6 +22,7 @@ import (
 	"github.com/bluenviron/mediamtx/internal/logger"
 	"github.com/bluenviron/mediamtx/internal/protocols/mpegts"
 	"github.com/bluenviron/mediamtx/internal/stream"
+	"github.com/bluenviron/mediamtx/internal/webhook"
 )
 
 const (
@@ -58,6 +59,7 @@ type conn struct {
 	readTimeout         conf.StringDuration
 	writeTimeout        conf.StringDuration
 	writeQueueSize      int
+	writeQueueOverflow  conf.WriteQueueOverflow
 	udpMaxPayloadSize   int
 	connReq             srt.ConnRequest
 	runOnConnect        string
@@ -65,6 +67,7 @@ type conn struct {
 	runOnDisconnect     string
 	wg                  *sync.WaitGroup
 	externalCmdPool     *externalcmd.Pool
+	webhookSender       *webhook.Sender
 	pathManager         defs.PathManager
 	parent              *Server
 
@@ -77,6 +80,7 @@ type conn struct {
 	pathName  string
 	query     string
 	sconn     srt.Conn
+	writer    *asyncwriter.Writer
 
 	chNew     chan srtNewConnReq
 	chSetConn chan srt.Conn
@@ -115,6 +119,7 @@ func (c *conn) run() { //nolint:dupl
 	onDisconnectHook := hooks.OnConnect(hooks.OnConnectParams{
 		Logger:              c,
 		ExternalCmdPool:     c.externalCmdPool,
+		WebhookSender:       c.webhookSender,
 		RunOnConnect:        c.runOnConnect,
 		RunOnConnectRestart: c.runOnConnectRestart,
 		RunOnDisconnect:     c.runOnDisconnect,
@@ -189,7 +194,7 @@ func (c *conn) runPublish(req srtNewConnReq, streamID *streamID) (bool, error) {
 
 	defer res.Path.RemovePublisher(defs.PathRemovePublisherReq{Author: c})
 
-	err := srtCheckPassphrase(req.connReq, res.Path.SafeConf().SRTPublishPassphrase)
+	err := srtCheckPassphrase(req.connReq, res.Path.SafeConf().SRTPublishPassphrase.GetValue())
 	if err != nil {
 		return false, err
 	}
@@ -225,7 +230,7 @@ func (c *conn) runPublish(req srtNewConnReq, streamID *streamID) (bool, error) {
 
 func (c *conn) runPublishReader(sconn srt.Conn, path defs.Path) error {
 	sconn.SetReadDeadline(time.Now().Add(time.Duration(c.readTimeout)))
-	r, err := mcmpegts.NewReader(mcmpegts.NewBufferedReader(sconn))
+	r, err := mpegts.NewReader(mcmpegts.NewBufferedReader(sconn))
 	if err != nil {
 		return err
 	}
@@ -288,7 +293,22 @@ func (c *conn) runRead(req srtNewConnReq, streamID *streamID) (bool, error) {
 
 	defer res.Path.RemoveReader(defs.PathRemoveReaderReq{Author: c})
 
-	err := srtCheckPassphrase(req.connReq, res.Path.SafeConf().SRTReadPassphrase)
+	err := srtCheckPassphrase(req.connReq, res.Path.SafeConf().SRTReadPassphrase.GetValue())
+	if err != nil {
+		return false, err
+	}
+
+	ts, err := conf.TrackSelectionFromQuery(streamID.query)
+	if err != nil {
+		return false, err
+	}
+
+	desc, err := ts.Apply(res.Stream.Desc())
+	if err != nil {
+		return false, err
+	}
+
+	timeshift, err := stream.TimeshiftFromQuery(streamID.query)
 	if err != nil {
 		return false, err
 	}
@@ -299,20 +319,29 @@ func (c *conn) runRead(req srtNewConnReq, streamID *streamID) (bool, error) {
 	}
 	defer sconn.Close()
 
+	writer := asyncwriter.New(c.writeQueueSize, c)
+	writer.SetOverflowPolicy(c.writeQueueOverflow)
+
 	c.mutex.Lock()
 	c.state = connStateRead
 	c.pathName = streamID.path
 	c.query = streamID.query
 	c.sconn = sconn
+	c.writer = writer
 	c.mutex.Unlock()
 
-	writer := asyncwriter.New(c.writeQueueSize, c)
-
 	defer res.Stream.RemoveReader(writer)
 
+	if timeshift != 0 {
+		err = res.Stream.SetReaderTimeshift(writer, timeshift)
+		if err != nil {
+			return true, err
+		}
+	}
+
 	bw := bufio.NewWriterSize(sconn, srtMaxPayloadSize(c.udpMaxPayloadSize))
 
-	err = mpegts.FromStream(res.Stream, writer, bw, sconn, time.Duration(c.writeTimeout))
+	err = mpegts.FromStream(res.Stream, desc, writer, bw, sconn, time.Duration(c.writeTimeout))
 	if err != nil {
 		return true, err
 	}
@@ -323,6 +352,7 @@ func (c *conn) runRead(req srtNewConnReq, streamID *streamID) (bool, error) {
 	onUnreadHook := hooks.OnRead(hooks.OnReadParams{
 		Logger:          c,
 		ExternalCmdPool: c.externalCmdPool,
+		WebhookSender:   c.webhookSender,
 		Conf:            res.Path.SafeConf(),
 		ExternalCmdEnv:  res.Path.ExternalCmdEnv(),
 		Reader:          c.APIReaderDescribe(),
@@ -395,6 +425,7 @@ func (c *conn) apiItem() *defs.APISRTConn {
 
 	bytesReceived := uint64(0)
 	bytesSent := uint64(0)
+	unitsDropped := uint64(0)
 
 	if c.sconn != nil {
 		var s srt.Statistics
@@ -403,6 +434,10 @@ func (c *conn) apiItem() *defs.APISRTConn {
 		bytesSent = s.Accumulated.ByteSent
 	}
 
+	if c.writer != nil {
+		unitsDropped = c.writer.DroppedUnits()
+	}
+
 	return &defs.APISRTConn{
 		ID:         c.uuid,
 		Created:    c.created,
@@ -423,5 +458,6 @@ func (c *conn) apiItem() *defs.APISRTConn {
 		Query:         c.query,
 		BytesReceived: bytesReceived,
 		BytesSent:     bytesSent,
+		UnitsDropped:  unitsDropped,
 	}
 }
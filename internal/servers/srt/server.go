@@ -16,6 +16,7 @@ import (
 	"github.com/bluenviron/mediamtx/internal/defs"
 	"github.com/bluenviron/mediamtx/internal/externalcmd"
 	"github.com/bluenviron/mediamtx/internal/logger"
+	"github.com/bluenviron/mediamtx/internal/webhook"
 )
 
 // ErrConnNotFound is returned when a connection is not found.
@@ -69,11 +70,13 @@ type Server struct {
 	ReadTimeout         conf.StringDuration
 	WriteTimeout        conf.StringDuration
 	WriteQueueSize      int
+	WriteQueueOverflow  conf.WriteQueueOverflow
 	UDPMaxPayloadSize   int
 	RunOnConnect        string
 	RunOnConnectRestart bool
 	RunOnDisconnect     string
 	ExternalCmdPool     *externalcmd.Pool
+	WebhookSender       *webhook.Sender
 	PathManager         defs.PathManager
 	Parent              serverParent
 
@@ -158,6 +161,7 @@ outer:
 				readTimeout:         s.ReadTimeout,
 				writeTimeout:        s.WriteTimeout,
 				writeQueueSize:      s.WriteQueueSize,
+				writeQueueOverflow:  s.WriteQueueOverflow,
 				udpMaxPayloadSize:   s.UDPMaxPayloadSize,
 				connReq:             req.connReq,
 				runOnConnect:        s.RunOnConnect,
@@ -165,6 +169,7 @@ outer:
 				runOnDisconnect:     s.RunOnDisconnect,
 				wg:                  &s.wg,
 				externalCmdPool:     s.ExternalCmdPool,
+				webhookSender:       s.WebhookSender,
 				pathManager:         s.PathManager,
 				parent:              s,
 			}
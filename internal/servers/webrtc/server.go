@@ -26,12 +26,12 @@ import (
 	"github.com/bluenviron/mediamtx/internal/logger"
 	"github.com/bluenviron/mediamtx/internal/protocols/webrtc"
 	"github.com/bluenviron/mediamtx/internal/restrictnetwork"
+	"github.com/bluenviron/mediamtx/internal/webhook"
 )
 
 const (
 	pauseAfterAuthError        = 2 * time.Second
 	webrtcTurnSecretExpiration = 24 * 3600 * time.Second
-	webrtcPayloadMaxSize       = 1188 // 1200 - 12 (RTP header)
 )
 
 // ErrSessionNotFound is returned when a session is not found.
@@ -178,6 +178,7 @@ type Server struct {
 	TrustedProxies        conf.IPsOrCIDRs
 	ReadTimeout           conf.StringDuration
 	WriteQueueSize        int
+	WriteQueueOverflow    conf.WriteQueueOverflow
 	LocalUDPAddress       string
 	LocalTCPAddress       string
 	IPsFromInterfaces     bool
@@ -185,6 +186,7 @@ type Server struct {
 	AdditionalHosts       []string
 	ICEServers            []conf.WebRTCICEServer
 	ExternalCmdPool       *externalcmd.Pool
+	WebhookSender         *webhook.Sender
 	PathManager           defs.PathManager
 	Parent                serverParent
 
@@ -317,14 +319,16 @@ outer:
 		select {
 		case req := <-s.chNewSession:
 			sx := &session{
-				parentCtx:       s.ctx,
-				writeQueueSize:  s.WriteQueueSize,
-				api:             s.api,
-				req:             req,
-				wg:              &wg,
-				externalCmdPool: s.ExternalCmdPool,
-				pathManager:     s.PathManager,
-				parent:          s,
+				parentCtx:          s.ctx,
+				writeQueueSize:     s.WriteQueueSize,
+				writeQueueOverflow: s.WriteQueueOverflow,
+				api:                s.api,
+				req:                req,
+				wg:                 &wg,
+				externalCmdPool:    s.ExternalCmdPool,
+				webhookSender:      s.WebhookSender,
+				pathManager:        s.PathManager,
+				parent:             s,
 			}
 			sx.initialize()
 			s.sessions[sx] = struct{}{}
@@ -12,260 +12,22 @@ import (
 	"time"
 
 	"github.com/bluenviron/gortsplib/v4/pkg/description"
-	"github.com/bluenviron/gortsplib/v4/pkg/format"
-	"github.com/bluenviron/gortsplib/v4/pkg/format/rtpav1"
-	"github.com/bluenviron/gortsplib/v4/pkg/format/rtph264"
-	"github.com/bluenviron/gortsplib/v4/pkg/format/rtpvp8"
-	"github.com/bluenviron/gortsplib/v4/pkg/format/rtpvp9"
 	"github.com/bluenviron/gortsplib/v4/pkg/rtptime"
 	"github.com/google/uuid"
 	"github.com/pion/sdp/v3"
 	pwebrtc "github.com/pion/webrtc/v3"
 
 	"github.com/bluenviron/mediamtx/internal/asyncwriter"
+	"github.com/bluenviron/mediamtx/internal/conf"
 	"github.com/bluenviron/mediamtx/internal/defs"
 	"github.com/bluenviron/mediamtx/internal/externalcmd"
 	"github.com/bluenviron/mediamtx/internal/hooks"
 	"github.com/bluenviron/mediamtx/internal/logger"
 	"github.com/bluenviron/mediamtx/internal/protocols/webrtc"
 	"github.com/bluenviron/mediamtx/internal/stream"
-	"github.com/bluenviron/mediamtx/internal/unit"
+	"github.com/bluenviron/mediamtx/internal/webhook"
 )
 
-type setupStreamFunc func(*webrtc.OutgoingTrack) error
-
-func findVideoTrack(
-	stream *stream.Stream,
-	writer *asyncwriter.Writer,
-) (format.Format, setupStreamFunc) {
-	var av1Format *format.AV1
-	media := stream.Desc().FindFormat(&av1Format)
-
-	if av1Format != nil {
-		return av1Format, func(track *webrtc.OutgoingTrack) error {
-			encoder := &rtpav1.Encoder{
-				PayloadType:    105,
-				PayloadMaxSize: webrtcPayloadMaxSize,
-			}
-			err := encoder.Init()
-			if err != nil {
-				return err
-			}
-
-			stream.AddReader(writer, media, av1Format, func(u unit.Unit) error {
-				tunit := u.(*unit.AV1)
-
-				if tunit.TU == nil {
-					return nil
-				}
-
-				packets, err := encoder.Encode(tunit.TU)
-				if err != nil {
-					return nil //nolint:nilerr
-				}
-
-				for _, pkt := range packets {
-					pkt.Timestamp += tunit.RTPPackets[0].Timestamp
-					track.WriteRTP(pkt) //nolint:errcheck
-				}
-
-				return nil
-			})
-
-			return nil
-		}
-	}
-
-	var vp9Format *format.VP9
-	media = stream.Desc().FindFormat(&vp9Format)
-
-	if vp9Format != nil {
-		return vp9Format, func(track *webrtc.OutgoingTrack) error {
-			encoder := &rtpvp9.Encoder{
-				PayloadType:    96,
-				PayloadMaxSize: webrtcPayloadMaxSize,
-			}
-			err := encoder.Init()
-			if err != nil {
-				return err
-			}
-
-			stream.AddReader(writer, media, vp9Format, func(u unit.Unit) error {
-				tunit := u.(*unit.VP9)
-
-				if tunit.Frame == nil {
-					return nil
-				}
-
-				packets, err := encoder.Encode(tunit.Frame)
-				if err != nil {
-					return nil //nolint:nilerr
-				}
-
-				for _, pkt := range packets {
-					pkt.Timestamp += tunit.RTPPackets[0].Timestamp
-					track.WriteRTP(pkt) //nolint:errcheck
-				}
-
-				return nil
-			})
-
-			return nil
-		}
-	}
-
-	var vp8Format *format.VP8
-	media = stream.Desc().FindFormat(&vp8Format)
-
-	if vp8Format != nil {
-		return vp8Format, func(track *webrtc.OutgoingTrack) error {
-			encoder := &rtpvp8.Encoder{
-				PayloadType:    96,
-				PayloadMaxSize: webrtcPayloadMaxSize,
-			}
-			err := encoder.Init()
-			if err != nil {
-				return err
-			}
-
-			stream.AddReader(writer, media, vp8Format, func(u unit.Unit) error {
-				tunit := u.(*unit.VP8)
-
-				if tunit.Frame == nil {
-					return nil
-				}
-
-				packets, err := encoder.Encode(tunit.Frame)
-				if err != nil {
-					return nil //nolint:nilerr
-				}
-
-				for _, pkt := range packets {
-					pkt.Timestamp += tunit.RTPPackets[0].Timestamp
-					track.WriteRTP(pkt) //nolint:errcheck
-				}
-
-				return nil
-			})
-
-			return nil
-		}
-	}
-
-	var h264Format *format.H264
-	media = stream.Desc().FindFormat(&h264Format)
-
-	if h264Format != nil {
-		return h264Format, func(track *webrtc.OutgoingTrack) error {
-			encoder := &rtph264.Encoder{
-				PayloadType:    96,
-				PayloadMaxSize: webrtcPayloadMaxSize,
-			}
-			err := encoder.Init()
-			if err != nil {
-				return err
-			}
-
-			firstReceived := false
-			var lastPTS time.Duration
-
-			stream.AddReader(writer, media, h264Format, func(u unit.Unit) error {
-				tunit := u.(*unit.H264)
-
-				if tunit.AU == nil {
-					return nil
-				}
-
-				if !firstReceived {
-					firstReceived = true
-				} else if tunit.PTS < lastPTS {
-					return fmt.Errorf("WebRTC doesn't support H264 streams with B-frames")
-				}
-				lastPTS = tunit.PTS
-
-				packets, err := encoder.Encode(tunit.AU)
-				if err != nil {
-					return nil //nolint:nilerr
-				}
-
-				for _, pkt := range packets {
-					pkt.Timestamp += tunit.RTPPackets[0].Timestamp
-					track.WriteRTP(pkt) //nolint:errcheck
-				}
-
-				return nil
-			})
-
-			return nil
-		}
-	}
-
-	return nil, nil
-}
-
-func findAudioTrack(
-	stream *stream.Stream,
-	writer *asyncwriter.Writer,
-) (format.Format, setupStreamFunc) {
-	var opusFormat *format.Opus
-	media := stream.Desc().FindFormat(&opusFormat)
-
-	if opusFormat != nil {
-		return opusFormat, func(track *webrtc.OutgoingTrack) error {
-			stream.AddReader(writer, media, opusFormat, func(u unit.Unit) error {
-				for _, pkt := range u.GetRTPPackets() {
-					track.WriteRTP(pkt) //nolint:errcheck
-				}
-
-				return nil
-			})
-			return nil
-		}
-	}
-
-	var g722Format *format.G722
-	media = stream.Desc().FindFormat(&g722Format)
-
-	if g722Format != nil {
-		return g722Format, func(track *webrtc.OutgoingTrack) error {
-			stream.AddReader(writer, media, g722Format, func(u unit.Unit) error {
-				for _, pkt := range u.GetRTPPackets() {
-					track.WriteRTP(pkt) //nolint:errcheck
-				}
-
-				return nil
-			})
-			return nil
-		}
-	}
-
-	var g711Format *format.G711
-	media = stream.Desc().FindFormat(&g711Format)
-
-	if g711Format != nil {
-		return g711Format, func(track *webrtc.OutgoingTrack) error {
-			if g711Format.SampleRate != 8000 {
-				return fmt.Errorf("unsupported G711 sample rate")
-			}
-
-			if g711Format.ChannelCount != 1 {
-				return fmt.Errorf("unsupported G711 channel count")
-			}
-
-			stream.AddReader(writer, media, g711Format, func(u unit.Unit) error {
-				for _, pkt := range u.GetRTPPackets() {
-					track.WriteRTP(pkt) //nolint:errcheck
-				}
-
-				return nil
-			})
-			return nil
-		}
-	}
-
-	return nil, nil
-}
-
 func whipOffer(body []byte) *pwebrtc.SessionDescription {
 	return &pwebrtc.SessionDescription{
 		Type: pwebrtc.SDPTypeOffer,
@@ -274,14 +36,16 @@ func whipOffer(body []byte) *pwebrtc.SessionDescription {
 }
 
 type session struct {
-	parentCtx       context.Context
-	writeQueueSize  int
-	api             *pwebrtc.API
-	req             webRTCNewSessionReq
-	wg              *sync.WaitGroup
-	externalCmdPool *externalcmd.Pool
-	pathManager     defs.PathManager
-	parent          *Server
+	parentCtx          context.Context
+	writeQueueSize     int
+	writeQueueOverflow conf.WriteQueueOverflow
+	api                *pwebrtc.API
+	req                webRTCNewSessionReq
+	wg                 *sync.WaitGroup
+	externalCmdPool    *externalcmd.Pool
+	webhookSender      *webhook.Sender
+	pathManager        defs.PathManager
+	parent             *Server
 
 	ctx       context.Context
 	ctxCancel func()
@@ -290,6 +54,7 @@ type session struct {
 	secret    uuid.UUID
 	mutex     sync.RWMutex
 	pc        *webrtc.PeerConnection
+	writer    *asyncwriter.Writer
 
 	chNew           chan webRTCNewSessionReq
 	chAddCandidates chan webRTCAddSessionCandidatesReq
@@ -524,6 +289,21 @@ func (s *session) runRead() (int, error) {
 
 	defer res.Path.RemoveReader(defs.PathRemoveReaderReq{Author: s})
 
+	ts, err := conf.TrackSelectionFromQuery(s.req.query)
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	desc, err := ts.Apply(res.Stream.Desc())
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	timeshift, err := stream.TimeshiftFromQuery(s.req.query)
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+
 	iceServers, err := s.parent.generateICEServers()
 	if err != nil {
 		return http.StatusInternalServerError, err
@@ -542,13 +322,13 @@ func (s *session) runRead() (int, error) {
 	defer pc.Close()
 
 	writer := asyncwriter.New(s.writeQueueSize, s)
+	writer.SetOverflowPolicy(s.writeQueueOverflow)
 
-	videoTrack, videoSetup := findVideoTrack(res.Stream, writer)
-	audioTrack, audioSetup := findAudioTrack(res.Stream, writer)
+	videoTrack, videoSetup := webrtc.FindVideoTrack(res.Stream, desc, writer)
+	audioTrack, audioSetup := webrtc.FindAudioTrack(res.Stream, desc, writer)
 
 	if videoTrack == nil && audioTrack == nil {
-		return http.StatusBadRequest, fmt.Errorf(
-			"the stream doesn't contain any supported codec, which are currently AV1, VP9, VP8, H264, Opus, G722, G711")
+		return http.StatusBadRequest, webrtc.ErrNoSupportedCodecs
 	}
 
 	tracks, err := pc.SetupOutgoingTracks(videoTrack, audioTrack)
@@ -574,10 +354,18 @@ func (s *session) runRead() (int, error) {
 
 	s.mutex.Lock()
 	s.pc = pc
+	s.writer = writer
 	s.mutex.Unlock()
 
 	defer res.Stream.RemoveReader(writer)
 
+	if timeshift != 0 {
+		err = res.Stream.SetReaderTimeshift(writer, timeshift)
+		if err != nil {
+			return 0, err
+		}
+	}
+
 	n := 0
 
 	if videoTrack != nil {
@@ -601,6 +389,7 @@ func (s *session) runRead() (int, error) {
 	onUnreadHook := hooks.OnRead(hooks.OnReadParams{
 		Logger:          s,
 		ExternalCmdPool: s.externalCmdPool,
+		WebhookSender:   s.webhookSender,
 		Conf:            res.Path.SafeConf(),
 		ExternalCmdEnv:  res.Path.ExternalCmdEnv(),
 		Reader:          s.APIReaderDescribe(),
@@ -695,6 +484,7 @@ func (s *session) apiItem() *defs.APIWebRTCSession {
 	remoteCandidate := ""
 	bytesReceived := uint64(0)
 	bytesSent := uint64(0)
+	unitsDropped := uint64(0)
 
 	if s.pc != nil {
 		peerConnectionEstablished = true
@@ -704,6 +494,10 @@ func (s *session) apiItem() *defs.APIWebRTCSession {
 		bytesSent = s.pc.BytesSent()
 	}
 
+	if s.writer != nil {
+		unitsDropped = s.writer.DroppedUnits()
+	}
+
 	return &defs.APIWebRTCSession{
 		ID:                        s.uuid,
 		Created:                   s.created,
@@ -721,5 +515,6 @@ func (s *session) apiItem() *defs.APIWebRTCSession {
 		Query:         s.req.query,
 		BytesReceived: bytesReceived,
 		BytesSent:     bytesSent,
+		UnitsDropped:  unitsDropped,
 	}
 }
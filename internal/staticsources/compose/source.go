@@ -0,0 +1,196 @@
+// Package compose contains the compose static source.
+package compose
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/bluenviron/gortsplib/v4/pkg/description"
+	"github.com/bluenviron/gortsplib/v4/pkg/format"
+
+	"github.com/bluenviron/mediamtx/internal/asyncwriter"
+	"github.com/bluenviron/mediamtx/internal/conf"
+	"github.com/bluenviron/mediamtx/internal/defs"
+	"github.com/bluenviron/mediamtx/internal/logger"
+	"github.com/bluenviron/mediamtx/internal/stream"
+	"github.com/bluenviron/mediamtx/internal/unit"
+)
+
+// cloneUnit returns a shallow copy of a unit, without RTP packets,
+// in order to allow the destination stream to generate its own packets.
+func cloneUnit(u unit.Unit) unit.Unit {
+	v := reflect.ValueOf(u).Elem()
+	c := reflect.New(v.Type())
+	c.Elem().Set(v)
+	rtpPackets := c.Elem().FieldByName("Base").FieldByName("RTPPackets")
+	rtpPackets.Set(reflect.Zero(rtpPackets.Type()))
+	return c.Interface().(unit.Unit)
+}
+
+// cloneFormat returns a copy of a format, in order to prevent the destination stream
+// from sharing parameters (that are updated in-band) with the source stream.
+func cloneFormat(mediaType description.MediaType, forma format.Format) (format.Format, error) {
+	return format.Unmarshal(string(mediaType), forma.PayloadType(), forma.RTPMap(), forma.FMTP())
+}
+
+// timeAligner maps timestamps of a path onto the timeline of the composed stream,
+// by using the NTP timestamp of the first received unit.
+type timeAligner struct {
+	start       time.Time
+	initialized bool
+	offset      time.Duration
+}
+
+func (a *timeAligner) align(u unit.Unit) time.Duration {
+	if !a.initialized {
+		a.initialized = true
+		a.offset = u.GetNTP().Sub(a.start) - u.GetPTS()
+	}
+	return u.GetPTS() + a.offset
+}
+
+// reader is the reader that is added to source paths.
+type reader struct {
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+// Close implements defs.Reader.
+func (r *reader) Close() {
+	r.closeOnce.Do(func() {
+		close(r.closed)
+	})
+}
+
+// APIReaderDescribe implements defs.Reader.
+func (*reader) APIReaderDescribe() defs.APIPathSourceOrReader {
+	return defs.APIPathSourceOrReader{
+		Type: "composeSource",
+		ID:   "",
+	}
+}
+
+// Source is a static source that combines tracks of other paths.
+type Source struct {
+	ResolvedSource string
+	WriteQueueSize int
+	PathManager    defs.PathManager
+	Parent         defs.StaticSourceParent
+}
+
+// Log implements logger.Writer.
+func (s *Source) Log(level logger.Level, format string, args ...interface{}) {
+	s.Parent.Log(level, "[compose source] "+format, args...)
+}
+
+// Run implements StaticSource.
+func (s *Source) Run(params defs.StaticSourceRunParams) error {
+	entries, err := conf.ParseComposeSource(s.ResolvedSource)
+	if err != nil {
+		return err
+	}
+
+	r := &reader{closed: make(chan struct{})}
+	writer := asyncwriter.New(s.WriteQueueSize, s)
+	start := time.Now()
+	aligners := make(map[string]*timeAligner)
+	var medias []*description.Media
+	var strm *stream.Stream
+
+	for _, e := range entries {
+		res := s.PathManager.AddReader(defs.PathAddReaderReq{
+			Author: r,
+			AccessRequest: defs.PathAccessRequest{
+				Name:     e.Path,
+				SkipAuth: true,
+			},
+		})
+		if res.Err != nil {
+			return fmt.Errorf("unable to read from path '%s': %w", e.Path, res.Err)
+		}
+
+		defer res.Path.RemoveReader(defs.PathRemoveReaderReq{Author: r})
+		defer res.Stream.RemoveReader(writer)
+
+		aligner, ok := aligners[e.Path]
+		if !ok {
+			aligner = &timeAligner{start: start}
+			aligners[e.Path] = aligner
+		}
+
+		found := false
+
+		for _, srcMedia := range res.Stream.Desc().Medias {
+			if e.MediaType != "" && string(srcMedia.Type) != e.MediaType {
+				continue
+			}
+
+			found = true
+
+			medi := &description.Media{
+				Type: srcMedia.Type,
+			}
+
+			for _, forma := range srcMedia.Formats {
+				cforma, err := cloneFormat(srcMedia.Type, forma)
+				if err != nil {
+					return fmt.Errorf("unable to clone format %s of path '%s': %w", forma.Codec(), e.Path, err)
+				}
+				medi.Formats = append(medi.Formats, cforma)
+
+				res.Stream.AddReader(writer, srcMedia, forma, func(u unit.Unit) error {
+					u = cloneUnit(u)
+					u.SetPTS(aligner.align(u))
+					strm.WriteUnit(medi, cforma, u)
+					return nil
+				})
+			}
+
+			medias = append(medias, medi)
+		}
+
+		if !found {
+			if e.MediaType != "" {
+				return fmt.Errorf("path '%s' has no %s tracks", e.Path, e.MediaType)
+			}
+			return fmt.Errorf("path '%s' has no tracks", e.Path)
+		}
+	}
+
+	res := s.Parent.SetReady(defs.PathSourceStaticSetReadyReq{
+		Desc:               &description.Session{Medias: medias},
+		GenerateRTPPackets: true,
+	})
+	if res.Err != nil {
+		return res.Err
+	}
+
+	defer s.Parent.SetNotReady(defs.PathSourceStaticSetNotReadyReq{})
+
+	strm = res.Stream
+
+	writer.Start()
+
+	select {
+	case err := <-writer.Error():
+		return err
+
+	case <-r.closed:
+		writer.Stop()
+		return fmt.Errorf("a source path is not available anymore")
+
+	case <-params.Context.Done():
+		writer.Stop()
+		return fmt.Errorf("terminated")
+	}
+}
+
+// APISourceDescribe implements StaticSource.
+func (*Source) APISourceDescribe() defs.APIPathSourceOrReader {
+	return defs.APIPathSourceOrReader{
+		Type: "composeSource",
+		ID:   "",
+	}
+}
@@ -0,0 +1,32 @@
+package compose
+
+import (
+	"testing"
+
+	"github.com/bluenviron/gortsplib/v4/pkg/description"
+	"github.com/bluenviron/gortsplib/v4/pkg/format"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCloneFormat(t *testing.T) {
+	forma := &format.H264{
+		PayloadTyp: 96,
+		SPS: []byte{ // 1920x1080 baseline
+			0x67, 0x42, 0xc0, 0x28, 0xd9, 0x00, 0x78, 0x02,
+			0x27, 0xe5, 0x84, 0x00, 0x00, 0x03, 0x00, 0x04,
+			0x00, 0x00, 0x03, 0x00, 0xf0, 0x3c, 0x60, 0xc9, 0x20,
+		},
+		PPS:               []byte{0x08, 0x06, 0x07, 0x08},
+		PacketizationMode: 1,
+	}
+
+	c, err := cloneFormat(description.MediaTypeVideo, forma)
+	require.NoError(t, err)
+	require.Equal(t, forma, c)
+
+	// parameters of the copy can be updated without affecting the original
+	sps := forma.SPS
+	c.(*format.H264).SafeSetParams([]byte{0x67, 0x64}, []byte{0x68})
+	require.Equal(t, sps, forma.SPS)
+	require.Equal(t, []byte{0x08, 0x06, 0x07, 0x08}, forma.PPS)
+}
@@ -70,7 +70,7 @@ func (s *Source) Run(params defs.StaticSourceRunParams) error {
 
 func (s *Source) runReader(sconn srt.Conn) error {
 	sconn.SetReadDeadline(time.Now().Add(time.Duration(s.ReadTimeout)))
-	r, err := mcmpegts.NewReader(mcmpegts.NewBufferedReader(sconn))
+	r, err := mpegts.NewReader(mcmpegts.NewBufferedReader(sconn))
 	if err != nil {
 		return err
 	}
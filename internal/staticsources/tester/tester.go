@@ -65,6 +65,8 @@ func (t *Tester) SetReady(req defs.PathSourceStaticSetReadyReq) defs.PathSourceS
 		1460,
 		req.Desc,
 		req.GenerateRTPPackets,
+		false,
+		0,
 		t,
 	)
 
@@ -106,7 +106,7 @@ func (s *Source) Run(params defs.StaticSourceRunParams) error {
 
 func (s *Source) runReader(pc net.PacketConn) error {
 	pc.SetReadDeadline(time.Now().Add(time.Duration(s.ReadTimeout)))
-	r, err := mcmpegts.NewReader(mcmpegts.NewBufferedReader(newPacketConnReader(pc)))
+	r, err := mpegts.NewReader(mcmpegts.NewBufferedReader(newPacketConnReader(pc)))
 	if err != nil {
 		return err
 	}
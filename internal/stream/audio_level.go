@@ -0,0 +1,187 @@
+package stream
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/bluenviron/gortsplib/v4/pkg/format"
+	"github.com/bluenviron/mediacommon/pkg/codecs/g711"
+
+	"github.com/bluenviron/mediamtx/internal/unit"
+)
+
+const (
+	// window used to compute audio levels.
+	audioLevelWindow = 1 * time.Second
+
+	// level that is reported in case of digital silence, in dBFS.
+	audioLevelMin = -100
+)
+
+func amplitudeToDBFS(v float64) float64 {
+	if v <= 0 {
+		return audioLevelMin
+	}
+	return max(20*math.Log10(v), audioLevelMin)
+}
+
+// AudioLevel is the level of an audio track.
+type AudioLevel struct {
+	// root mean square level, in dBFS.
+	RMS float64
+
+	// peak level, in dBFS.
+	Peak float64
+}
+
+// audioLevelMeter computes the level of a PCM-based track (G711, LPCM).
+type audioLevelMeter struct {
+	mulaw    bool
+	alaw     bool
+	bitDepth int
+
+	mutex       sync.Mutex
+	windowStart time.Time
+	sumSquares  float64
+	count       uint64
+	peak        float64
+	level       *AudioLevel
+}
+
+// newAudioLevelMeter allocates a audioLevelMeter.
+// It returns nil when the format is not supported.
+func newAudioLevelMeter(forma format.Format) *audioLevelMeter {
+	switch forma := forma.(type) {
+	case *format.G711:
+		return &audioLevelMeter{
+			mulaw:    forma.MULaw,
+			alaw:     !forma.MULaw,
+			bitDepth: 16,
+		}
+
+	case *format.LPCM:
+		if forma.BitDepth != 8 && forma.BitDepth != 16 && forma.BitDepth != 24 {
+			return nil
+		}
+
+		return &audioLevelMeter{
+			bitDepth: forma.BitDepth,
+		}
+	}
+
+	return nil
+}
+
+func (m *audioLevelMeter) add(now time.Time, u unit.Unit) {
+	var chunks [][]byte
+
+	switch tunit := u.(type) {
+	case *unit.G711:
+		if tunit.Samples != nil {
+			chunks = [][]byte{tunit.Samples}
+		}
+
+	case *unit.LPCM:
+		if tunit.Samples != nil {
+			chunks = [][]byte{tunit.Samples}
+		}
+	}
+
+	// when the track is not decoded, samples are read from RTP payloads
+	if chunks == nil {
+		for _, pkt := range u.GetRTPPackets() {
+			chunks = append(chunks, pkt.Payload)
+		}
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.windowStart.IsZero() {
+		m.windowStart = now
+	} else if now.Sub(m.windowStart) >= audioLevelWindow {
+		m.closeWindow(now)
+	}
+
+	for _, chunk := range chunks {
+		switch {
+		case m.mulaw:
+			chunk = g711.DecodeMulaw(chunk)
+
+		case m.alaw:
+			chunk = g711.DecodeAlaw(chunk)
+		}
+
+		m.addSamples(chunk)
+	}
+}
+
+// addSamples adds big-endian samples.
+func (m *audioLevelMeter) addSamples(buf []byte) {
+	switch m.bitDepth {
+	case 8: // offset binary
+		for _, b := range buf {
+			m.addSample(float64(int(b)-128) / 128)
+		}
+
+	case 16:
+		for i := 0; (i + 2) <= len(buf); i += 2 {
+			v := int16(uint16(buf[i])<<8 | uint16(buf[i+1]))
+			m.addSample(float64(v) / 32768)
+		}
+
+	case 24:
+		for i := 0; (i + 3) <= len(buf); i += 3 {
+			v := int32(uint32(buf[i])<<24|uint32(buf[i+1])<<16|uint32(buf[i+2])<<8) >> 8
+			m.addSample(float64(v) / 8388608)
+		}
+	}
+}
+
+func (m *audioLevelMeter) addSample(v float64) {
+	m.sumSquares += v * v
+	m.count++
+	if a := math.Abs(v); a > m.peak {
+		m.peak = a
+	}
+}
+
+func (m *audioLevelMeter) closeWindow(now time.Time) {
+	// windows without samples are reported as silent,
+	// in order not to keep the last level when the track stops.
+	if m.count != 0 {
+		m.level = &AudioLevel{
+			RMS:  amplitudeToDBFS(math.Sqrt(m.sumSquares / float64(m.count))),
+			Peak: amplitudeToDBFS(m.peak),
+		}
+	} else {
+		m.level = &AudioLevel{
+			RMS:  audioLevelMin,
+			Peak: audioLevelMin,
+		}
+	}
+
+	m.windowStart = now
+	m.sumSquares = 0
+	m.count = 0
+	m.peak = 0
+}
+
+// get returns the level computed over the last complete window.
+// Windows are also closed here, since units may stop arriving.
+func (m *audioLevelMeter) get(now time.Time) *AudioLevel {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if !m.windowStart.IsZero() && now.Sub(m.windowStart) >= audioLevelWindow {
+		m.closeWindow(now)
+	}
+
+	if m.level == nil {
+		return nil
+	}
+
+	l := *m.level
+	return &l
+}
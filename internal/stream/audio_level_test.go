@@ -0,0 +1,122 @@
+package stream
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/bluenviron/gortsplib/v4/pkg/format"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bluenviron/mediamtx/internal/unit"
+)
+
+func TestAudioLevelMeter(t *testing.T) {
+	// level of a square wave with amplitude 0.5
+	halfScale := 20 * math.Log10(0.5)
+
+	for _, ca := range []struct {
+		name    string
+		format  format.Format
+		samples []byte
+		rms     float64
+		peak    float64
+	}{
+		{
+			"lpcm 8-bit",
+			&format.LPCM{BitDepth: 8, SampleRate: 48000, ChannelCount: 1},
+			[]byte{0xc0, 0x40, 0xc0, 0x40},
+			halfScale,
+			halfScale,
+		},
+		{
+			"lpcm 16-bit",
+			&format.LPCM{BitDepth: 16, SampleRate: 48000, ChannelCount: 1},
+			[]byte{0x40, 0x00, 0xc0, 0x00, 0x40, 0x00, 0xc0, 0x00},
+			halfScale,
+			halfScale,
+		},
+		{
+			"lpcm 24-bit",
+			&format.LPCM{BitDepth: 24, SampleRate: 48000, ChannelCount: 1},
+			[]byte{0x40, 0x00, 0x00, 0xc0, 0x00, 0x00},
+			halfScale,
+			halfScale,
+		},
+		{
+			"lpcm 16-bit mixed",
+			&format.LPCM{BitDepth: 16, SampleRate: 48000, ChannelCount: 1},
+			[]byte{0x40, 0x00, 0x00, 0x00},
+			20 * math.Log10(math.Sqrt(0.125)),
+			halfScale,
+		},
+		{
+			"mu-law",
+			&format.G711{MULaw: true, SampleRate: 8000, ChannelCount: 1},
+			[]byte{0x80, 0x00, 0x80, 0x00}, // +-32124
+			20 * math.Log10(32124.0/32768),
+			20 * math.Log10(32124.0/32768),
+		},
+		{
+			"a-law",
+			&format.G711{MULaw: false, SampleRate: 8000, ChannelCount: 1},
+			[]byte{0xaa, 0x2a, 0xaa, 0x2a}, // +-32256
+			20 * math.Log10(32256.0/32768),
+			20 * math.Log10(32256.0/32768),
+		},
+		{
+			"digital silence",
+			&format.LPCM{BitDepth: 16, SampleRate: 48000, ChannelCount: 1},
+			[]byte{0x00, 0x00, 0x00, 0x00},
+			audioLevelMin,
+			audioLevelMin,
+		},
+	} {
+		t.Run(ca.name, func(t *testing.T) {
+			m := newAudioLevelMeter(ca.format)
+			require.NotNil(t, m)
+
+			var u unit.Unit
+			if _, ok := ca.format.(*format.G711); ok {
+				u = &unit.G711{Samples: ca.samples}
+			} else {
+				u = &unit.LPCM{Samples: ca.samples}
+			}
+
+			t0 := time.Date(2015, 2, 5, 1, 2, 2, 0, time.UTC)
+
+			m.add(t0, u)
+			require.Nil(t, m.get(t0.Add(500*time.Millisecond)))
+
+			l := m.get(t0.Add(time.Second))
+			require.NotNil(t, l)
+			require.InDelta(t, ca.rms, l.RMS, 0.001)
+			require.InDelta(t, ca.peak, l.Peak, 0.001)
+		})
+	}
+}
+
+func TestAudioLevelMeterUnsupported(t *testing.T) {
+	require.Nil(t, newAudioLevelMeter(&format.LPCM{BitDepth: 32}))
+	require.Nil(t, newAudioLevelMeter(&format.Opus{}))
+}
+
+func TestAudioLevelMeterExpire(t *testing.T) {
+	m := newAudioLevelMeter(&format.LPCM{BitDepth: 16, SampleRate: 48000, ChannelCount: 1})
+
+	t0 := time.Date(2015, 2, 5, 1, 2, 2, 0, time.UTC)
+
+	m.add(t0, &unit.LPCM{Samples: []byte{0x40, 0x00, 0xc0, 0x00}})
+	m.add(t0.Add(1100*time.Millisecond), &unit.LPCM{Samples: []byte{0x20, 0x00, 0xe0, 0x00}})
+
+	l := m.get(t0.Add(1500 * time.Millisecond))
+	require.InDelta(t, 20*math.Log10(0.5), l.RMS, 0.001)
+
+	// the window that contains the second unit is closed even if units stop arriving
+	l = m.get(t0.Add(2100 * time.Millisecond))
+	require.InDelta(t, 20*math.Log10(0.25), l.RMS, 0.001)
+
+	// following windows are empty
+	l = m.get(t0.Add(3100 * time.Millisecond))
+	require.Equal(t, &AudioLevel{RMS: audioLevelMin, Peak: audioLevelMin}, l)
+}
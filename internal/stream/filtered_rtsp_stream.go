@@ -0,0 +1,38 @@
+package stream
+
+import (
+	"time"
+
+	"github.com/bluenviron/gortsplib/v4"
+	"github.com/bluenviron/gortsplib/v4/pkg/description"
+	"github.com/pion/rtp"
+)
+
+type filteredRTSPStreamKey struct {
+	server *gortsplib.Server
+	medias string
+}
+
+type filteredRTSPStream struct {
+	stream *gortsplib.ServerStream
+	medias map[*description.Media]struct{}
+}
+
+func newFilteredRTSPStream(server *gortsplib.Server, desc *description.Session) *filteredRTSPStream {
+	fs := &filteredRTSPStream{
+		stream: gortsplib.NewServerStream(server, desc),
+		medias: make(map[*description.Media]struct{}),
+	}
+
+	for _, medi := range desc.Medias {
+		fs.medias[medi] = struct{}{}
+	}
+
+	return fs
+}
+
+func (fs *filteredRTSPStream) writePacketRTP(medi *description.Media, pkt *rtp.Packet, ntp time.Time) {
+	if _, ok := fs.medias[medi]; ok {
+		fs.stream.WritePacketRTPWithNTP(medi, pkt, ntp) //nolint:errcheck
+	}
+}
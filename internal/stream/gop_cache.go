@@ -0,0 +1,103 @@
+package stream
+
+import (
+	"sync"
+
+	"github.com/bluenviron/gortsplib/v4/pkg/description"
+	"github.com/bluenviron/gortsplib/v4/pkg/format"
+	"github.com/bluenviron/mediacommon/pkg/codecs/av1"
+	"github.com/bluenviron/mediacommon/pkg/codecs/h264"
+	"github.com/bluenviron/mediacommon/pkg/codecs/h265"
+	"github.com/bluenviron/mediacommon/pkg/codecs/vp9"
+
+	"github.com/bluenviron/mediamtx/internal/unit"
+)
+
+// maximum number of units stored in the GOP cache.
+// when it is exceeded, the cache is emptied until the next key frame.
+const gopCacheMaxUnits = 8192
+
+func isKeyFrame(u unit.Unit) bool {
+	switch tunit := u.(type) {
+	case *unit.H264:
+		return tunit.AU != nil && h264.IDRPresent(tunit.AU)
+
+	case *unit.H265:
+		return tunit.AU != nil && h265.IsRandomAccess(tunit.AU)
+
+	case *unit.AV1:
+		if tunit.TU == nil {
+			return false
+		}
+		ok, _ := av1.ContainsKeyFrame(tunit.TU)
+		return ok
+
+	case *unit.VP9:
+		if tunit.Frame == nil {
+			return false
+		}
+		var h vp9.Header
+		err := h.Unmarshal(tunit.Frame)
+		return err == nil && h.FrameType == vp9.FrameTypeKeyFrame
+	}
+
+	return false
+}
+
+// gopCacheFormat returns the format whose key frames delimit the GOP cache,
+// that is the first video format which allows to detect key frames.
+func gopCacheFormat(desc *description.Session) format.Format {
+	for _, medi := range desc.Medias {
+		for _, forma := range medi.Formats {
+			switch forma.(type) {
+			case *format.H264, *format.H265, *format.AV1, *format.VP9:
+				return forma
+			}
+		}
+	}
+	return nil
+}
+
+type gopCacheEntry struct {
+	media  *description.Media
+	format format.Format
+	unit   unit.Unit
+}
+
+// gopCache stores the units received since the last key frame.
+type gopCache struct {
+	keyFormat format.Format
+
+	mutex   sync.Mutex
+	entries []gopCacheEntry
+}
+
+func (c *gopCache) write(medi *description.Media, forma format.Format, u unit.Unit) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if forma == c.keyFormat && isKeyFrame(u) {
+		c.entries = c.entries[:0]
+	} else if len(c.entries) == 0 {
+		// wait for a key frame
+		return
+	}
+
+	if len(c.entries) >= gopCacheMaxUnits {
+		c.entries = c.entries[:0]
+		return
+	}
+
+	c.entries = append(c.entries, gopCacheEntry{
+		media:  medi,
+		format: forma,
+		unit:   u,
+	})
+}
+
+func (c *gopCache) snapshot() []gopCacheEntry {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return append([]gopCacheEntry(nil), c.entries...)
+}
@@ -0,0 +1,173 @@
+package stream
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bluenviron/gortsplib/v4/pkg/description"
+	"github.com/bluenviron/gortsplib/v4/pkg/format"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bluenviron/mediamtx/internal/asyncwriter"
+	"github.com/bluenviron/mediamtx/internal/unit"
+)
+
+var (
+	testIDR    = [][]byte{{0x65, 0x88, 0x84, 0x00}}
+	testNonIDR = [][]byte{{0x41, 0x9a, 0x24, 0x6c}}
+)
+
+func TestGOPCacheWrite(t *testing.T) {
+	videoMedia := &description.Media{
+		Type:    description.MediaTypeVideo,
+		Formats: []format.Format{&format.H264{PayloadTyp: 96, PacketizationMode: 1}},
+	}
+	audioMedia := &description.Media{
+		Type:    description.MediaTypeAudio,
+		Formats: []format.Format{&format.G711{PayloadTyp: 8, SampleRate: 8000, ChannelCount: 1}},
+	}
+
+	c := &gopCache{keyFormat: videoMedia.Formats[0]}
+
+	video := func(pts time.Duration, au [][]byte) unit.Unit {
+		return &unit.H264{Base: unit.Base{PTS: pts}, AU: au}
+	}
+	audio := func(pts time.Duration) unit.Unit {
+		return &unit.G711{Base: unit.Base{PTS: pts}, Samples: make([]byte, 160)}
+	}
+
+	ptses := func() []time.Duration {
+		var ret []time.Duration
+		for _, e := range c.snapshot() {
+			ret = append(ret, e.unit.GetPTS())
+		}
+		return ret
+	}
+
+	// units that precede the first key frame are not stored
+	c.write(videoMedia, videoMedia.Formats[0], video(0, testNonIDR))
+	c.write(audioMedia, audioMedia.Formats[0], audio(0))
+	require.Empty(t, ptses())
+
+	c.write(videoMedia, videoMedia.Formats[0], video(1, testIDR))
+	c.write(audioMedia, audioMedia.Formats[0], audio(2))
+	c.write(videoMedia, videoMedia.Formats[0], video(3, testNonIDR))
+	require.Equal(t, []time.Duration{1, 2, 3}, ptses())
+
+	// a key frame resets the cache
+	c.write(videoMedia, videoMedia.Formats[0], video(4, testIDR))
+	c.write(audioMedia, audioMedia.Formats[0], audio(5))
+	require.Equal(t, []time.Duration{4, 5}, ptses())
+
+	// when the maximum size is exceeded, the cache is emptied until the next key frame
+	for i := 0; i < gopCacheMaxUnits; i++ {
+		c.write(videoMedia, videoMedia.Formats[0], video(time.Duration(6+i), testNonIDR))
+	}
+	require.Empty(t, ptses())
+
+	c.write(audioMedia, audioMedia.Formats[0], audio(100000))
+	require.Empty(t, ptses())
+
+	c.write(videoMedia, videoMedia.Formats[0], video(100001, testIDR))
+	require.Equal(t, []time.Duration{100001}, ptses())
+}
+
+func TestStreamGOPCacheReplay(t *testing.T) {
+	desc := &description.Session{Medias: []*description.Media{
+		{
+			Type:    description.MediaTypeVideo,
+			Formats: []format.Format{&format.H264{PayloadTyp: 96, PacketizationMode: 1}},
+		},
+		{
+			Type:    description.MediaTypeAudio,
+			Formats: []format.Format{&format.G711{PayloadTyp: 8, SampleRate: 8000, ChannelCount: 1}},
+		},
+	}}
+
+	strm, err := New(1460, desc, true, true, 0, nilLogger{})
+	require.NoError(t, err)
+	defer strm.Close()
+
+	writeVideo := func(pts time.Duration, au [][]byte) {
+		strm.WriteUnit(desc.Medias[0], desc.Medias[0].Formats[0], &unit.H264{
+			Base: unit.Base{PTS: pts},
+			AU:   au,
+		})
+	}
+
+	writeAudio := func(pts time.Duration) {
+		strm.WriteUnit(desc.Medias[1], desc.Medias[1].Formats[0], &unit.G711{
+			Base:    unit.Base{PTS: pts},
+			Samples: make([]byte, 160),
+		})
+	}
+
+	type received struct {
+		isVideo bool
+		pts     time.Duration
+	}
+
+	addReader := func() (*asyncwriter.Writer, chan received) {
+		w := asyncwriter.New(1024, nilLogger{})
+		recv := make(chan received, 1024)
+
+		strm.AddReader(w, desc.Medias[0], desc.Medias[0].Formats[0], func(u unit.Unit) error {
+			recv <- received{true, u.GetPTS()}
+			return nil
+		})
+
+		// the reader is already known, units must not be replayed twice
+		strm.AddReader(w, desc.Medias[1], desc.Medias[1].Formats[0], func(u unit.Unit) error {
+			recv <- received{false, u.GetPTS()}
+			return nil
+		})
+
+		w.Start()
+		return w, recv
+	}
+
+	writeVideo(0, testNonIDR)
+	writeAudio(20 * time.Millisecond)
+	writeVideo(40*time.Millisecond, testIDR)
+	writeAudio(60 * time.Millisecond)
+	writeVideo(80*time.Millisecond, testNonIDR)
+	writeAudio(100 * time.Millisecond)
+
+	w, recv := addReader()
+
+	// live units follow replayed ones
+	writeVideo(120*time.Millisecond, testNonIDR)
+
+	for _, exp := range []received{
+		{true, 40 * time.Millisecond},
+		{false, 60 * time.Millisecond},
+		{true, 80 * time.Millisecond},
+		{false, 100 * time.Millisecond},
+		{true, 120 * time.Millisecond},
+	} {
+		require.Equal(t, exp, <-recv)
+	}
+
+	w.Stop()
+	strm.RemoveReader(w)
+
+	// a key frame resets the cache
+	writeVideo(140*time.Millisecond, testIDR)
+	writeAudio(160 * time.Millisecond)
+
+	w, recv = addReader()
+	defer w.Stop()
+
+	for _, exp := range []received{
+		{true, 140 * time.Millisecond},
+		{false, 160 * time.Millisecond},
+	} {
+		require.Equal(t, exp, <-recv)
+	}
+
+	select {
+	case r := <-recv:
+		t.Errorf("unexpected unit: %v", r)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -2,6 +2,10 @@
 package stream
 
 import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strconv"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -21,7 +25,8 @@ type readerFunc func(unit.Unit) error
 // Stream is a media stream.
 // It stores tracks, readers and allows to write data to readers.
 type Stream struct {
-	desc *description.Session
+	desc    *description.Session
+	created time.Time
 
 	bytesReceived *uint64
 	bytesSent     *uint64
@@ -29,6 +34,11 @@ type Stream struct {
 	mutex         sync.RWMutex
 	rtspStream    *gortsplib.ServerStream
 	rtspsStream   *gortsplib.ServerStream
+
+	filteredRTSPStreams map[filteredRTSPStreamKey]*filteredRTSPStream
+	gopCache            *gopCache
+	timeshiftBuffer     *timeshiftBuffer
+	timeshiftReaders    map[*asyncwriter.Writer]*timeshiftReader
 }
 
 // New allocates a Stream.
@@ -36,14 +46,27 @@ func New(
 	udpMaxPayloadSize int,
 	desc *description.Session,
 	generateRTPPackets bool,
+	gopCacheEnabled bool,
+	timeshiftBuffer time.Duration,
 	decodeErrLogger logger.Writer,
 ) (*Stream, error) {
 	s := &Stream{
 		desc:          desc,
+		created:       time.Now(),
 		bytesReceived: new(uint64),
 		bytesSent:     new(uint64),
 	}
 
+	if gopCacheEnabled {
+		if forma := gopCacheFormat(desc); forma != nil {
+			s.gopCache = &gopCache{keyFormat: forma}
+		}
+	}
+
+	if timeshiftBuffer > 0 {
+		s.timeshiftBuffer = newTimeshiftBuffer(timeshiftBuffer, desc)
+	}
+
 	s.smedias = make(map[*description.Media]*streamMedia)
 
 	for _, media := range desc.Medias {
@@ -57,6 +80,138 @@ func New(
 	return s, nil
 }
 
+func formatsAreCompatible(f1 format.Format, f2 format.Format) bool {
+	if f1.Codec() != f2.Codec() ||
+		f1.ClockRate() != f2.ClockRate() ||
+		f1.PayloadType() != f2.PayloadType() {
+		return false
+	}
+
+	// audio parameters are used by readers to set up decoders and muxers, therefore they must not change.
+	// video parameters are not compared since they can be updated in-band.
+	switch f1 := f1.(type) {
+	case *format.MPEG4Audio:
+		f2, ok := f2.(*format.MPEG4Audio)
+		if !ok || f1.LATM != f2.LATM {
+			return false
+		}
+
+		if f1.LATM {
+			return reflect.DeepEqual(f1.StreamMuxConfig, f2.StreamMuxConfig)
+		}
+
+		return reflect.DeepEqual(f1.Config, f2.Config) &&
+			f1.SizeLength == f2.SizeLength &&
+			f1.IndexLength == f2.IndexLength &&
+			f1.IndexDeltaLength == f2.IndexDeltaLength
+
+	case *format.LPCM:
+		f2, ok := f2.(*format.LPCM)
+		return ok &&
+			f1.BitDepth == f2.BitDepth &&
+			f1.SampleRate == f2.SampleRate &&
+			f1.ChannelCount == f2.ChannelCount
+
+	case *format.G711:
+		f2, ok := f2.(*format.G711)
+		return ok &&
+			f1.MULaw == f2.MULaw &&
+			f1.SampleRate == f2.SampleRate &&
+			f1.ChannelCount == f2.ChannelCount
+
+	case *format.Opus:
+		f2, ok := f2.(*format.Opus)
+		return ok && f1.IsStereo == f2.IsStereo
+
+	case *format.AC3:
+		f2, ok := f2.(*format.AC3)
+		return ok &&
+			f1.SampleRate == f2.SampleRate &&
+			f1.ChannelCount == f2.ChannelCount
+
+	case *format.Vorbis:
+		f2, ok := f2.(*format.Vorbis)
+		return ok &&
+			f1.SampleRate == f2.SampleRate &&
+			f1.ChannelCount == f2.ChannelCount &&
+			bytes.Equal(f1.Configuration, f2.Configuration)
+
+	case *format.G726:
+		f2, ok := f2.(*format.G726)
+		return ok &&
+			f1.BitRate == f2.BitRate &&
+			f1.BigEndian == f2.BigEndian
+	}
+
+	return true
+}
+
+// Reattach allows a new source to write into the stream, in place of the previous one.
+// The description of the new source must contain the same medias and formats of the stream,
+// with the same audio parameters.
+// Timestamps and sequence numbers of the new source are rebased in order to follow the ones of the previous source.
+func (s *Stream) Reattach(desc *description.Session) error {
+	if len(desc.Medias) != len(s.desc.Medias) {
+		return fmt.Errorf("the number of medias is different")
+	}
+
+	for i, medi := range desc.Medias {
+		orig := s.desc.Medias[i]
+
+		if medi.Type != orig.Type || len(medi.Formats) != len(orig.Formats) {
+			return fmt.Errorf("media %d is different", i+1)
+		}
+
+		for j, forma := range medi.Formats {
+			if !formatsAreCompatible(forma, orig.Formats[j]) {
+				return fmt.Errorf("format %d of media %d is different", j+1, i+1)
+			}
+		}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	// remove medias and formats of the previous source
+	for medi, sm := range s.smedias {
+		if medi != sm.media {
+			delete(s.smedias, medi)
+			continue
+		}
+
+		for forma := range sm.formats {
+			if !containsFormat(sm.media.Formats, forma) {
+				delete(sm.formats, forma)
+			}
+		}
+	}
+
+	for i, medi := range desc.Medias {
+		sm := s.smedias[s.desc.Medias[i]]
+		s.smedias[medi] = sm
+
+		for j, forma := range medi.Formats {
+			sf := sm.formats[sm.media.Formats[j]]
+			sf.rebase = true
+			sf.ptsOffset = 0
+			sf.rtpOffset = 0
+			sf.seqOffset = 0
+			sm.formats[forma] = sf
+		}
+	}
+
+	return nil
+}
+
+func containsFormat(formats []format.Format, forma format.Format) bool {
+	for _, f := range formats {
+		if f == forma {
+			return true
+		}
+	}
+	return false
+}
+
 // Close closes all resources of the stream.
 func (s *Stream) Close() {
 	if s.rtspStream != nil {
@@ -65,6 +220,9 @@ func (s *Stream) Close() {
 	if s.rtspsStream != nil {
 		s.rtspsStream.Close()
 	}
+	for _, fs := range s.filteredRTSPStreams {
+		fs.stream.Close()
+	}
 }
 
 // Desc returns the description of the stream.
@@ -89,9 +247,65 @@ func (s *Stream) BytesSent() uint64 {
 	if s.rtspsStream != nil {
 		bytesSent += s.rtspsStream.BytesSent()
 	}
+	for _, fs := range s.filteredRTSPStreams {
+		bytesSent += fs.stream.BytesSent()
+	}
 	return bytesSent
 }
 
+// StalledMedias returns the medias that didn't receive any data in the given period.
+// Medias that never received data are returned only when the whole stream didn't receive any data,
+// since sources often declare tracks that are never used.
+func (s *Stream) StalledMedias(period time.Duration) []*description.Media {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	now := time.Now()
+	received := false
+	var stalled []*description.Media
+
+	for _, medi := range s.desc.Medias {
+		last := s.smedias[medi].lastUnitTime()
+		if last.IsZero() {
+			continue
+		}
+
+		received = true
+		if now.Sub(last) >= period {
+			stalled = append(stalled, medi)
+		}
+	}
+
+	if !received && now.Sub(s.created) >= period {
+		return s.desc.Medias
+	}
+
+	return stalled
+}
+
+// TrackStats returns statistics of tracks, in the same order of the formats of the description.
+func (s *Stream) TrackStats() []TrackStats {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	now := time.Now()
+	var ret []TrackStats
+
+	for _, medi := range s.desc.Medias {
+		sm := s.smedias[medi]
+		for _, forma := range medi.Formats {
+			sf := sm.formats[forma]
+			st := sf.stats.stats(now)
+			if sf.audioLevel != nil {
+				st.AudioLevel = sf.audioLevel.get(now)
+			}
+			ret = append(ret, st)
+		}
+	}
+
+	return ret
+}
+
 // RTSPStream returns the RTSP stream.
 func (s *Stream) RTSPStream(server *gortsplib.Server) *gortsplib.ServerStream {
 	s.mutex.Lock()
@@ -114,21 +328,101 @@ func (s *Stream) RTSPSStream(server *gortsplib.Server) *gortsplib.ServerStream {
 	return s.rtspsStream
 }
 
+// FilteredRTSPStream returns a RTSP stream that contains only a subset of the medias of the stream.
+// desc must contain medias of the stream.
+func (s *Stream) FilteredRTSPStream(server *gortsplib.Server, desc *description.Session) *gortsplib.ServerStream {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	key := filteredRTSPStreamKey{server: server}
+	for _, medi := range desc.Medias {
+		key.medias += strconv.FormatInt(int64(s.mediaIndex(medi)), 10) + ","
+	}
+
+	fs, ok := s.filteredRTSPStreams[key]
+	if !ok {
+		fs = newFilteredRTSPStream(server, desc)
+
+		if s.filteredRTSPStreams == nil {
+			s.filteredRTSPStreams = make(map[filteredRTSPStreamKey]*filteredRTSPStream)
+		}
+		s.filteredRTSPStreams[key] = fs
+	}
+
+	return fs.stream
+}
+
+func (s *Stream) mediaIndex(medi *description.Media) int {
+	for i, m := range s.desc.Medias {
+		if m == medi {
+			return i
+		}
+	}
+	return -1
+}
+
 // AddReader adds a reader.
+// When the GOP cache is enabled, units received since the last key frame
+// are replayed to the reader before live units.
 func (s *Stream) AddReader(r *asyncwriter.Writer, medi *description.Media, forma format.Format, cb readerFunc) {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
+	_, timeshifted := s.timeshiftReaders[r]
+
+	if s.gopCache != nil && !timeshifted && !s.hasReader(r) {
+		// the replay is queued before any live unit and runs after the writer is started,
+		// when the reader has been added to all its formats.
+		if entries := s.gopCache.snapshot(); len(entries) != 0 {
+			r.Push(func() error {
+				return s.replayGOPCache(r, entries)
+			})
+		}
+	}
+
 	sm := s.smedias[medi]
 	sf := sm.formats[forma]
 	sf.addReader(r, cb)
 }
 
+func (s *Stream) hasReader(r *asyncwriter.Writer) bool {
+	for _, sm := range s.smedias {
+		for _, sf := range sm.formats {
+			if _, ok := sf.readers[r]; ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (s *Stream) replayGOPCache(r *asyncwriter.Writer, entries []gopCacheEntry) error {
+	for _, e := range entries {
+		s.mutex.RLock()
+		cb, ok := s.smedias[e.media].formats[e.format].readers[r]
+		s.mutex.RUnlock()
+
+		if ok {
+			err := cb(e.unit)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
 // RemoveReader removes a reader.
 func (s *Stream) RemoveReader(r *asyncwriter.Writer) {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
+	if tr, ok := s.timeshiftReaders[r]; ok {
+		tr.close()
+		delete(s.timeshiftReaders, r)
+	}
+
 	for _, sm := range s.smedias {
 		for _, sf := range sm.formats {
 			sf.removeReader(r)
@@ -143,9 +437,10 @@ func (s *Stream) FormatsForReader(r *asyncwriter.Writer) []format.Format {
 
 	var formats []format.Format
 
-	for _, sm := range s.smedias {
-		for forma, sf := range sm.formats {
-			if _, ok := sf.readers[r]; ok {
+	for _, medi := range s.desc.Medias {
+		sm := s.smedias[medi]
+		for _, forma := range medi.Formats {
+			if _, ok := sm.formats[forma].readers[r]; ok {
 				formats = append(formats, forma)
 			}
 		}
@@ -156,13 +451,16 @@ func (s *Stream) FormatsForReader(r *asyncwriter.Writer) []format.Format {
 
 // WriteUnit writes a Unit.
 func (s *Stream) WriteUnit(medi *description.Media, forma format.Format, u unit.Unit) {
-	sm := s.smedias[medi]
-	sf := sm.formats[forma]
-
 	s.mutex.RLock()
 	defer s.mutex.RUnlock()
 
-	sf.writeUnit(s, medi, u)
+	sm, ok := s.smedias[medi]
+	if !ok { // media has been excluded from the stream
+		return
+	}
+	sf := sm.formats[forma]
+
+	sf.writeUnit(s, sm.media, u)
 }
 
 // WriteRTPPacket writes a RTP packet.
@@ -173,11 +471,14 @@ func (s *Stream) WriteRTPPacket(
 	ntp time.Time,
 	pts time.Duration,
 ) {
-	sm := s.smedias[medi]
-	sf := sm.formats[forma]
-
 	s.mutex.RLock()
 	defer s.mutex.RUnlock()
 
-	sf.writeRTPPacket(s, medi, pkt, ntp, pts)
+	sm, ok := s.smedias[medi]
+	if !ok { // media has been excluded from the stream
+		return
+	}
+	sf := sm.formats[forma]
+
+	sf.writeRTPPacket(s, sm.media, pkt, ntp, pts)
 }
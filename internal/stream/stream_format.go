@@ -11,9 +11,20 @@ import (
 	"github.com/bluenviron/mediamtx/internal/asyncwriter"
 	"github.com/bluenviron/mediamtx/internal/formatprocessor"
 	"github.com/bluenviron/mediamtx/internal/logger"
+	"github.com/bluenviron/mediamtx/internal/metadata"
 	"github.com/bluenviron/mediamtx/internal/unit"
 )
 
+const (
+	// maximum backward jump of timestamps that is not considered a discontinuity.
+	// Timestamps of video tracks with B-frames are not monotonic.
+	discontinuityMaxBackwardJump = 1 * time.Second
+
+	// maximum difference between the timestamp increment and the time passed
+	// since the previous unit that is not considered a discontinuity.
+	discontinuityMaxForwardJump = 10 * time.Second
+)
+
 func unitSize(u unit.Unit) uint64 {
 	n := uint64(0)
 	for _, pkt := range u.GetRTPPackets() {
@@ -22,15 +33,49 @@ func unitSize(u unit.Unit) uint64 {
 	return n
 }
 
+// isRandomAccess returns whether decoding can start from the unit.
+// Units of formats whose key frames can't be detected are always random access.
+func isRandomAccess(u unit.Unit) bool {
+	switch tunit := u.(type) {
+	case *unit.H264, *unit.H265, *unit.AV1, *unit.VP9:
+		return isKeyFrame(u)
+
+	case *unit.VP8:
+		// the first bit of the frame tag is zero in key frames
+		return len(tunit.Frame) != 0 && (tunit.Frame[0]&0x01) == 0
+	}
+
+	return true
+}
+
 type streamFormat struct {
+	format          format.Format
+	sparse          bool
+	scte35          bool
 	decodeErrLogger logger.Writer
 	proc            formatprocessor.Processor
+	clockRate       int
 	readers         map[*asyncwriter.Writer]readerFunc
+	lastUnitTime    *int64 // unix nanoseconds, read by the stall detector
+	stats           *trackStats
+	audioLevel      *audioLevelMeter
+
+	// timestamps of the last unit, used to rebase timestamps
+	// when a new source replaces the previous one.
+	lastPTS     time.Duration
+	lastRTPTime uint32
+	lastSeqNum  uint16
+	lastTime    time.Time
+	rebase      bool
+	ptsOffset   time.Duration
+	rtpOffset   uint32
+	seqOffset   uint16
 }
 
 func newStreamFormat(
 	udpMaxPayloadSize int,
 	forma format.Format,
+	mediaType description.MediaType,
 	generateRTPPackets bool,
 	decodeErrLogger logger.Writer,
 ) (*streamFormat, error) {
@@ -40,9 +85,16 @@ func newStreamFormat(
 	}
 
 	sf := &streamFormat{
+		format:          forma,
+		sparse:          mediaType == description.MediaTypeApplication,
+		scte35:          metadata.IsSCTE35(forma),
 		decodeErrLogger: decodeErrLogger,
 		proc:            proc,
+		clockRate:       forma.ClockRate(),
 		readers:         make(map[*asyncwriter.Writer]readerFunc),
+		lastUnitTime:    new(int64),
+		stats:           &trackStats{isVideo: mediaType == description.MediaTypeVideo},
+		audioLevel:      newAudioLevelMeter(forma),
 	}
 
 	return sf, nil
@@ -56,13 +108,82 @@ func (sf *streamFormat) removeReader(r *asyncwriter.Writer) {
 	delete(sf.readers, r)
 }
 
+// elapsed returns the time passed since the last unit.
+func (sf *streamFormat) elapsed() (time.Duration, bool) {
+	sf.rebase = false
+
+	if sf.lastTime.IsZero() {
+		return 0, false
+	}
+
+	return time.Since(sf.lastTime), true
+}
+
+// detectDiscontinuity checks whether timestamps jumped with respect to the previous unit,
+// because the source restarted or timestamps wrapped around.
+// It returns the offset that has to be added to timestamps in order to keep them monotonic.
+//
+// Forward jumps are detected by comparing the timestamp increment with the time passed
+// since the previous unit, therefore publishers that are faster than real time
+// (i.e. FFmpeg without -re) are not rebased, since the increment between consecutive units is small.
+// This doesn't hold for sparse tracks (metadata), whose units can be distant in time,
+// therefore only backward jumps are detected on them.
+func (sf *streamFormat) detectDiscontinuity(now time.Time, pts time.Duration) (time.Duration, bool) {
+	if sf.lastTime.IsZero() {
+		return 0, false
+	}
+
+	elapsed := now.Sub(sf.lastTime)
+	diff := pts - sf.lastPTS
+
+	if diff >= -discontinuityMaxBackwardJump &&
+		(sf.sparse || diff <= (elapsed+discontinuityMaxForwardJump)) {
+		return 0, false
+	}
+
+	sf.decodeErrLogger.Log(logger.Warn, "%s: timestamps jumped by %v, rebasing them",
+		sf.format.Codec(), diff)
+
+	return sf.lastPTS + elapsed - pts, true
+}
+
+// scte35Offset returns the offset that has to be added to pts_adjustment of SCTE-35 sections,
+// in order to shift splice times together with timestamps.
+func (sf *streamFormat) scte35Offset() int64 {
+	return int64(sf.ptsOffset.Seconds() * 90000)
+}
+
 func (sf *streamFormat) writeUnit(s *Stream, medi *description.Media, u unit.Unit) {
+	if sf.rebase {
+		if elapsed, ok := sf.elapsed(); ok {
+			sf.ptsOffset = sf.lastPTS + elapsed - u.GetPTS()
+		}
+	}
+
+	offset, discontinuity := sf.detectDiscontinuity(time.Now(), u.GetPTS()+sf.ptsOffset)
+	if discontinuity {
+		sf.ptsOffset += offset
+	}
+
+	if sf.ptsOffset != 0 {
+		u.SetPTS(u.GetPTS() + sf.ptsOffset)
+
+		if tunit, ok := u.(*unit.Generic); ok && sf.scte35 && tunit.Payload != nil {
+			tunit.Payload = metadata.SCTE35AddPTSAdjustment(tunit.Payload, sf.scte35Offset())
+		}
+	}
+
 	err := sf.proc.ProcessUnit(u)
 	if err != nil {
+		sf.stats.addDecodeError()
 		sf.decodeErrLogger.Log(logger.Warn, err.Error())
 		return
 	}
 
+	if discontinuity {
+		u.SetDiscontinuity(true)
+	}
+
 	sf.writeUnitInner(s, medi, u)
 }
 
@@ -73,19 +194,87 @@ func (sf *streamFormat) writeRTPPacket(
 	ntp time.Time,
 	pts time.Duration,
 ) {
-	hasNonRTSPReaders := len(sf.readers) > 0
+	if sf.rebase {
+		if elapsed, ok := sf.elapsed(); ok {
+			sf.ptsOffset = sf.lastPTS + elapsed - pts
+			sf.rtpOffset = sf.lastRTPTime +
+				uint32(elapsed*time.Duration(sf.clockRate)/time.Second) - pkt.Timestamp
+			sf.seqOffset = sf.lastSeqNum + 1 - pkt.SequenceNumber
+		}
+	}
+
+	pts += sf.ptsOffset
+	pkt.Timestamp += sf.rtpOffset
+	pkt.SequenceNumber += sf.seqOffset
+
+	offset, discontinuity := sf.detectDiscontinuity(time.Now(), pts)
+	if discontinuity {
+		increment := pts + offset - sf.lastPTS
+		rtpOffset := sf.lastRTPTime +
+			uint32(increment*time.Duration(sf.clockRate)/time.Second) - pkt.Timestamp
+		seqOffset := sf.lastSeqNum + 1 - pkt.SequenceNumber
+
+		sf.ptsOffset += offset
+		sf.rtpOffset += rtpOffset
+		sf.seqOffset += seqOffset
+
+		pts += offset
+		pkt.Timestamp += rtpOffset
+		pkt.SequenceNumber += seqOffset
+	}
+
+	// sections contained in a single packet are shifted in place,
+	// in order to deliver them shifted to RTSP readers too.
+	if sf.scte35 && sf.ptsOffset != 0 && pkt.Marker {
+		pkt.Payload = metadata.SCTE35AddPTSAdjustment(pkt.Payload, sf.scte35Offset())
+	}
+
+	hasNonRTSPReaders := len(sf.readers) > 0 || s.gopCache != nil || s.timeshiftBuffer != nil
 
 	u, err := sf.proc.ProcessRTPPacket(pkt, ntp, pts, hasNonRTSPReaders)
 	if err != nil {
+		sf.stats.addDecodeError()
 		sf.decodeErrLogger.Log(logger.Warn, err.Error())
 		return
 	}
 
+	// sections split into multiple packets are shifted after being decoded
+	if tunit, ok := u.(*unit.Generic); ok && sf.scte35 && sf.ptsOffset != 0 &&
+		len(tunit.Payload) > len(pkt.Payload) {
+		tunit.Payload = metadata.SCTE35AddPTSAdjustment(tunit.Payload, sf.scte35Offset())
+	}
+
+	if discontinuity {
+		u.SetDiscontinuity(true)
+	}
+
 	sf.writeUnitInner(s, medi, u)
 }
 
 func (sf *streamFormat) writeUnitInner(s *Stream, medi *description.Media, u unit.Unit) {
 	size := unitSize(u)
+	pkts := u.GetRTPPackets()
+	isVideo := medi.Type == description.MediaTypeVideo
+
+	sf.lastPTS = u.GetPTS()
+	sf.lastTime = time.Now()
+	atomic.StoreInt64(sf.lastUnitTime, sf.lastTime.UnixNano())
+	if len(pkts) != 0 {
+		sf.lastRTPTime = pkts[len(pkts)-1].Timestamp
+		sf.lastSeqNum = pkts[len(pkts)-1].SequenceNumber
+	}
+
+	// the RTP marker is set on the last packet of video frames
+	sf.stats.addUnit(
+		sf.lastTime,
+		size,
+		u.GetPTS(),
+		!isVideo || len(pkts) == 0 || pkts[len(pkts)-1].Marker,
+		isVideo && (isRandomAccess(u) || rtpRandomAccess(sf.format, pkts)))
+
+	if sf.audioLevel != nil {
+		sf.audioLevel.add(sf.lastTime, u)
+	}
 
 	atomic.AddUint64(s.bytesReceived, size)
 
@@ -101,11 +290,42 @@ func (sf *streamFormat) writeUnitInner(s *Stream, medi *description.Media, u uni
 		}
 	}
 
+	if s.gopCache != nil {
+		s.gopCache.write(medi, sf.format, u)
+	}
+
+	if s.timeshiftBuffer != nil {
+		s.timeshiftBuffer.write(sf.lastTime, medi, sf.format, u, size)
+	}
+
+	for _, fs := range s.filteredRTSPStreams {
+		for _, pkt := range u.GetRTPPackets() {
+			fs.writePacketRTP(medi, pkt, u.GetNTP())
+		}
+	}
+
+	if len(sf.readers) == 0 {
+		return
+	}
+
+	randomAccess := isVideo && isRandomAccess(u)
+
 	for writer, cb := range sf.readers {
+		// timeshifted readers are fed by the timeshift buffer
+		if _, ok := s.timeshiftReaders[writer]; ok {
+			continue
+		}
+
 		ccb := cb
-		writer.Push(func() error {
+		wcb := func() error {
 			atomic.AddUint64(s.bytesSent, size)
 			return ccb(u)
-		})
+		}
+
+		if isVideo {
+			writer.PushVideo(wcb, randomAccess)
+		} else {
+			writer.Push(wcb)
+		}
 	}
 }
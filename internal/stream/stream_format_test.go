@@ -0,0 +1,108 @@
+package stream
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bluenviron/gortsplib/v4/pkg/format"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamFormatDetectDiscontinuity(t *testing.T) {
+	now := time.Date(2015, 2, 5, 1, 2, 2, 0, time.UTC)
+
+	for _, ca := range []struct {
+		name          string
+		sparse        bool
+		elapsed       time.Duration
+		pts           time.Duration
+		discontinuity bool
+		offset        time.Duration
+	}{
+		{
+			"regular",
+			false,
+			20 * time.Millisecond,
+			50*time.Second + 20*time.Millisecond,
+			false,
+			0,
+		},
+		{
+			"b-frames",
+			false,
+			20 * time.Millisecond,
+			50*time.Second - 500*time.Millisecond,
+			false,
+			0,
+		},
+		{
+			"backward",
+			false,
+			20 * time.Millisecond,
+			0,
+			true,
+			50*time.Second + 20*time.Millisecond,
+		},
+		{
+			"faster than real time",
+			false,
+			0,
+			55 * time.Second,
+			false,
+			0,
+		},
+		{
+			"forward",
+			false,
+			1 * time.Second,
+			70 * time.Second,
+			true,
+			-19 * time.Second,
+		},
+		{
+			"forward after a pause",
+			false,
+			15 * time.Second,
+			70 * time.Second,
+			false,
+			0,
+		},
+		{
+			"sparse forward",
+			true,
+			1 * time.Second,
+			110 * time.Second,
+			false,
+			0,
+		},
+		{
+			"sparse backward",
+			true,
+			1 * time.Second,
+			10 * time.Second,
+			true,
+			41 * time.Second,
+		},
+	} {
+		t.Run(ca.name, func(t *testing.T) {
+			sf := &streamFormat{
+				format:          &format.G711{},
+				sparse:          ca.sparse,
+				decodeErrLogger: nilLogger{},
+				lastPTS:         50 * time.Second,
+				lastTime:        now,
+			}
+
+			offset, discontinuity := sf.detectDiscontinuity(now.Add(ca.elapsed), ca.pts)
+			require.Equal(t, ca.discontinuity, discontinuity)
+			require.Equal(t, ca.offset, offset)
+		})
+	}
+
+	t.Run("first unit", func(t *testing.T) {
+		sf := &streamFormat{format: &format.G711{}, decodeErrLogger: nilLogger{}}
+
+		_, discontinuity := sf.detectDiscontinuity(now, 50*time.Second)
+		require.False(t, discontinuity)
+	})
+}
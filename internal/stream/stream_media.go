@@ -1,6 +1,9 @@
 package stream
 
 import (
+	"sync/atomic"
+	"time"
+
 	"github.com/bluenviron/gortsplib/v4/pkg/description"
 	"github.com/bluenviron/gortsplib/v4/pkg/format"
 
@@ -8,6 +11,7 @@ import (
 )
 
 type streamMedia struct {
+	media   *description.Media
 	formats map[format.Format]*streamFormat
 }
 
@@ -17,12 +21,14 @@ func newStreamMedia(udpMaxPayloadSize int,
 	decodeErrLogger logger.Writer,
 ) (*streamMedia, error) {
 	sm := &streamMedia{
+		media:   medi,
 		formats: make(map[format.Format]*streamFormat),
 	}
 
 	for _, forma := range medi.Formats {
 		var err error
-		sm.formats[forma], err = newStreamFormat(udpMaxPayloadSize, forma, generateRTPPackets, decodeErrLogger)
+		sm.formats[forma], err = newStreamFormat(udpMaxPayloadSize, forma,
+			medi.Type, generateRTPPackets, decodeErrLogger)
 		if err != nil {
 			return nil, err
 		}
@@ -30,3 +36,18 @@ func newStreamMedia(udpMaxPayloadSize int,
 
 	return sm, nil
 }
+
+// lastUnitTime returns the time of the last unit received by any format of the media.
+func (sm *streamMedia) lastUnitTime() time.Time {
+	var last int64
+	for _, sf := range sm.formats {
+		if t := atomic.LoadInt64(sf.lastUnitTime); t > last {
+			last = t
+		}
+	}
+
+	if last == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, last)
+}
@@ -0,0 +1,207 @@
+package stream
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/bluenviron/gortsplib/v4/pkg/description"
+	"github.com/bluenviron/gortsplib/v4/pkg/format"
+	"github.com/bluenviron/mediacommon/pkg/codecs/mpeg4audio"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bluenviron/mediamtx/internal/asyncwriter"
+	"github.com/bluenviron/mediamtx/internal/logger"
+	"github.com/bluenviron/mediamtx/internal/metadata"
+	"github.com/bluenviron/mediamtx/internal/unit"
+)
+
+type nilLogger struct{}
+
+func (nilLogger) Log(_ logger.Level, _ string, _ ...interface{}) {
+}
+
+type receivedUnit struct {
+	pts           time.Duration
+	discontinuity bool
+}
+
+func TestStreamDiscontinuity(t *testing.T) {
+	desc := &description.Session{Medias: []*description.Media{{
+		Type:    description.MediaTypeAudio,
+		Formats: []format.Format{&format.G711{PayloadTyp: 8, SampleRate: 8000, ChannelCount: 1}},
+	}}}
+
+	strm, err := New(1460, desc, true, false, 0, nilLogger{})
+	require.NoError(t, err)
+	defer strm.Close()
+
+	w := asyncwriter.New(1024, nilLogger{})
+
+	recv := make(chan receivedUnit, 1024)
+
+	strm.AddReader(w, desc.Medias[0], desc.Medias[0].Formats[0], func(u unit.Unit) error {
+		recv <- receivedUnit{pts: u.GetPTS(), discontinuity: u.GetDiscontinuity()}
+		return nil
+	})
+
+	w.Start()
+	defer w.Stop()
+
+	write := func(pts time.Duration) receivedUnit {
+		strm.WriteUnit(desc.Medias[0], desc.Medias[0].Formats[0], &unit.G711{
+			Base:    unit.Base{PTS: pts},
+			Samples: make([]byte, 160),
+		})
+		return <-recv
+	}
+
+	// a publisher that is faster than real time is not rebased
+	for i := 0; i < 1500; i++ {
+		pts := time.Duration(i) * 20 * time.Millisecond
+		require.Equal(t, receivedUnit{pts: pts}, write(pts))
+	}
+
+	// timestamps jump back, as if the source restarted
+	last := 1499 * 20 * time.Millisecond
+	u := write(0)
+	require.True(t, u.discontinuity)
+	require.GreaterOrEqual(t, u.pts, last)
+	require.Less(t, u.pts, last+time.Second)
+
+	// following units keep the same offset
+	require.Equal(t, receivedUnit{pts: u.pts + 20*time.Millisecond}, write(20*time.Millisecond))
+}
+
+func TestStreamSCTE35Rebase(t *testing.T) {
+	section, err := base64.StdEncoding.DecodeString(
+		"/DAvAAAAAAAA///wFAVIAACPf+/+c2nALv4AUsz1AAAAAAAKAAhDVUVJAAABNWLbowo=")
+	require.NoError(t, err)
+
+	desc := &description.Session{Medias: []*description.Media{{
+		Type:    description.MediaTypeApplication,
+		Formats: []format.Format{metadata.NewSCTE35Format()},
+	}}}
+
+	strm, err := New(1460, desc, true, false, 0, nilLogger{})
+	require.NoError(t, err)
+	defer strm.Close()
+
+	w := asyncwriter.New(1024, nilLogger{})
+
+	recv := make(chan *unit.Generic, 1024)
+
+	strm.AddReader(w, desc.Medias[0], desc.Medias[0].Formats[0], func(u unit.Unit) error {
+		recv <- u.(*unit.Generic)
+		return nil
+	})
+
+	w.Start()
+	defer w.Stop()
+
+	write := func(pts time.Duration) *unit.Generic {
+		strm.WriteUnit(desc.Medias[0], desc.Medias[0].Formats[0], &unit.Generic{
+			Base:    unit.Base{PTS: pts},
+			Payload: section,
+		})
+		return <-recv
+	}
+
+	spliceTime := func(u *unit.Generic) int64 {
+		var s metadata.SCTE35
+		err2 := s.Unmarshal(u.Payload)
+		require.NoError(t, err2)
+		return *s.SpliceTime
+	}
+
+	u := write(50 * time.Second)
+	require.Equal(t, int64(0x07369c02e), spliceTime(u))
+
+	// timestamps jump back, splice times are shifted together with them
+	u = write(10 * time.Second)
+	require.True(t, u.GetDiscontinuity())
+	offset := u.GetPTS() - 10*time.Second
+	require.Equal(t, int64(0x07369c02e)+int64(offset.Seconds()*90000), spliceTime(u))
+}
+
+func TestFormatsAreCompatible(t *testing.T) {
+	aacConfig := func(channelCount int) *mpeg4audio.Config {
+		return &mpeg4audio.Config{
+			Type:         mpeg4audio.ObjectTypeAACLC,
+			SampleRate:   48000,
+			ChannelCount: channelCount,
+		}
+	}
+
+	for _, ca := range []struct {
+		name string
+		f1   format.Format
+		f2   format.Format
+		ok   bool
+	}{
+		{
+			"same",
+			&format.LPCM{PayloadTyp: 96, BitDepth: 16, SampleRate: 44100, ChannelCount: 2},
+			&format.LPCM{PayloadTyp: 96, BitDepth: 16, SampleRate: 44100, ChannelCount: 2},
+			true,
+		},
+		{
+			"different codec",
+			&format.Opus{PayloadTyp: 96, IsStereo: true},
+			&format.VP8{PayloadTyp: 96},
+			false,
+		},
+		{
+			"different payload type",
+			&format.Opus{PayloadTyp: 96, IsStereo: true},
+			&format.Opus{PayloadTyp: 97, IsStereo: true},
+			false,
+		},
+		{
+			"lpcm bit depth",
+			&format.LPCM{PayloadTyp: 96, BitDepth: 16, SampleRate: 44100, ChannelCount: 2},
+			&format.LPCM{PayloadTyp: 96, BitDepth: 24, SampleRate: 44100, ChannelCount: 2},
+			false,
+		},
+		{
+			"lpcm channel count",
+			&format.LPCM{PayloadTyp: 96, BitDepth: 16, SampleRate: 44100, ChannelCount: 2},
+			&format.LPCM{PayloadTyp: 96, BitDepth: 16, SampleRate: 44100, ChannelCount: 1},
+			false,
+		},
+		{
+			"g711 channel count",
+			&format.G711{PayloadTyp: 8, SampleRate: 8000, ChannelCount: 1},
+			&format.G711{PayloadTyp: 8, SampleRate: 8000, ChannelCount: 2},
+			false,
+		},
+		{
+			"opus stereo",
+			&format.Opus{PayloadTyp: 96, IsStereo: true},
+			&format.Opus{PayloadTyp: 96, IsStereo: false},
+			false,
+		},
+		{
+			"mpeg-4 audio same config",
+			&format.MPEG4Audio{PayloadTyp: 96, Config: aacConfig(2), SizeLength: 13, IndexLength: 3, IndexDeltaLength: 3},
+			&format.MPEG4Audio{PayloadTyp: 96, Config: aacConfig(2), SizeLength: 13, IndexLength: 3, IndexDeltaLength: 3},
+			true,
+		},
+		{
+			"mpeg-4 audio channel count",
+			&format.MPEG4Audio{PayloadTyp: 96, Config: aacConfig(2), SizeLength: 13, IndexLength: 3, IndexDeltaLength: 3},
+			&format.MPEG4Audio{PayloadTyp: 96, Config: aacConfig(1), SizeLength: 13, IndexLength: 3, IndexDeltaLength: 3},
+			false,
+		},
+		{
+			"h264 parameters can change",
+			&format.H264{PayloadTyp: 96, PacketizationMode: 1, SPS: []byte{0x67, 0x42}},
+			&format.H264{PayloadTyp: 96, PacketizationMode: 1, SPS: []byte{0x67, 0x64}},
+			true,
+		},
+	} {
+		t.Run(ca.name, func(t *testing.T) {
+			require.Equal(t, ca.ok, formatsAreCompatible(ca.f1, ca.f2))
+		})
+	}
+}
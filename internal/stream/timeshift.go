@@ -0,0 +1,147 @@
+package stream
+
+import (
+	"fmt"
+	"net/url"
+	"sync/atomic"
+	"time"
+
+	"github.com/bluenviron/gortsplib/v4/pkg/description"
+	"github.com/pion/rtp"
+
+	"github.com/bluenviron/mediamtx/internal/asyncwriter"
+)
+
+// TimeshiftFromQuery returns the delay contained in the 'timeshift' parameter of a query,
+// for instance "timeshift=-60s". It returns zero when the parameter is not present.
+func TimeshiftFromQuery(rawQuery string) (time.Duration, error) {
+	q, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return 0, err
+	}
+
+	v := q.Get("timeshift")
+	if v == "" {
+		return 0, nil
+	}
+
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, fmt.Errorf("invalid timeshift: %w", err)
+	}
+
+	if d < 0 {
+		d = -d
+	}
+
+	return d, nil
+}
+
+func (s *Stream) checkTimeshift(delay time.Duration) error {
+	if s.timeshiftBuffer == nil {
+		return fmt.Errorf("timeshift is not enabled on this path")
+	}
+
+	if delay >= s.timeshiftBuffer.duration {
+		return fmt.Errorf("timeshift must be lower than the timeshift buffer (%v)", s.timeshiftBuffer.duration)
+	}
+
+	return nil
+}
+
+// TimeshiftBufferMemoryUsage returns the size of the units stored in the timeshift buffer.
+func (s *Stream) TimeshiftBufferMemoryUsage() uint64 {
+	if s.timeshiftBuffer == nil {
+		return 0
+	}
+	return s.timeshiftBuffer.memoryUsage()
+}
+
+// SetReaderTimeshift makes a reader receive units from the timeshift buffer,
+// delayed by the given duration, instead of live units.
+// It must be called before AddReader().
+func (s *Stream) SetReaderTimeshift(r *asyncwriter.Writer, delay time.Duration) error {
+	err := s.checkTimeshift(delay)
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	tr := &timeshiftReader{
+		buffer: s.timeshiftBuffer,
+		delay:  delay,
+		write: func(e *timeshiftEntry) {
+			s.writeTimeshiftEntry(r, e)
+		},
+	}
+	tr.start()
+
+	if s.timeshiftReaders == nil {
+		s.timeshiftReaders = make(map[*asyncwriter.Writer]*timeshiftReader)
+	}
+	s.timeshiftReaders[r] = tr
+
+	return nil
+}
+
+func (s *Stream) writeTimeshiftEntry(r *asyncwriter.Writer, e *timeshiftEntry) {
+	cb := func() error {
+		// callbacks are looked up when the writer processes the entry,
+		// in order to take into account readers added after the entry was pushed.
+		s.mutex.RLock()
+		ccb, ok := s.smedias[e.media].formats[e.format].readers[r]
+		s.mutex.RUnlock()
+
+		if !ok {
+			return nil
+		}
+
+		atomic.AddUint64(s.bytesSent, e.size)
+		return ccb(e.unit)
+	}
+
+	if e.media.Type == description.MediaTypeVideo {
+		r.PushVideo(cb, isRandomAccess(e.unit))
+	} else {
+		r.Push(cb)
+	}
+}
+
+// TimeshiftRTPReader reads RTP packets from the timeshift buffer.
+type TimeshiftRTPReader struct {
+	tr *timeshiftReader
+}
+
+// NewTimeshiftRTPReader allocates a TimeshiftRTPReader, that calls cb with
+// the RTP packets of the stream, delayed by the given duration.
+func (s *Stream) NewTimeshiftRTPReader(
+	delay time.Duration,
+	cb func(*description.Media, *rtp.Packet, time.Time),
+) (*TimeshiftRTPReader, error) {
+	err := s.checkTimeshift(delay)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &TimeshiftRTPReader{
+		tr: &timeshiftReader{
+			buffer: s.timeshiftBuffer,
+			delay:  delay,
+			write: func(e *timeshiftEntry) {
+				for _, pkt := range e.unit.GetRTPPackets() {
+					cb(e.media, pkt, e.unit.GetNTP())
+				}
+			},
+		},
+	}
+	r.tr.start()
+
+	return r, nil
+}
+
+// Close closes the TimeshiftRTPReader.
+func (r *TimeshiftRTPReader) Close() {
+	r.tr.close()
+}
@@ -0,0 +1,184 @@
+package stream
+
+import (
+	"sync"
+	"time"
+
+	"github.com/bluenviron/gortsplib/v4/pkg/description"
+	"github.com/bluenviron/gortsplib/v4/pkg/format"
+
+	"github.com/bluenviron/mediamtx/internal/unit"
+)
+
+type timeshiftEntry struct {
+	media    *description.Media
+	format   format.Format
+	unit     unit.Unit
+	size     uint64
+	recvTime time.Time
+	keyFrame bool
+}
+
+// timeshiftBuffer stores the units received in the last period of time.
+type timeshiftBuffer struct {
+	duration  time.Duration
+	keyFormat format.Format
+
+	mutex    sync.Mutex
+	entries  []timeshiftEntry
+	firstSeq uint64
+	size     uint64
+	newEntry chan struct{}
+}
+
+func newTimeshiftBuffer(duration time.Duration, desc *description.Session) *timeshiftBuffer {
+	return &timeshiftBuffer{
+		duration:  duration,
+		keyFormat: gopCacheFormat(desc),
+		newEntry:  make(chan struct{}),
+	}
+}
+
+func (b *timeshiftBuffer) write(
+	now time.Time,
+	medi *description.Media,
+	forma format.Format,
+	u unit.Unit,
+	size uint64,
+) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.entries = append(b.entries, timeshiftEntry{
+		media:    medi,
+		format:   forma,
+		unit:     u,
+		size:     size,
+		recvTime: now,
+		// when there are no video formats, decoding can start from any unit.
+		keyFrame: b.keyFormat == nil || (forma == b.keyFormat && isKeyFrame(u)),
+	})
+	b.size += size
+
+	for len(b.entries) != 0 && now.Sub(b.entries[0].recvTime) > b.duration {
+		b.size -= b.entries[0].size
+		b.entries[0] = timeshiftEntry{}
+		b.entries = b.entries[1:]
+		b.firstSeq++
+	}
+
+	close(b.newEntry)
+	b.newEntry = make(chan struct{})
+}
+
+// memoryUsage returns the size of the stored units.
+func (b *timeshiftBuffer) memoryUsage() uint64 {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return b.size
+}
+
+// seek returns the sequence number of the nearest key frame that precedes t.
+func (b *timeshiftBuffer) seek(t time.Time) uint64 {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	ret := -1
+
+	for i, e := range b.entries {
+		if !e.keyFrame {
+			continue
+		}
+
+		if e.recvTime.After(t) {
+			if ret < 0 {
+				ret = i
+			}
+			break
+		}
+
+		ret = i
+	}
+
+	if ret < 0 {
+		return b.firstSeq + uint64(len(b.entries))
+	}
+
+	return b.firstSeq + uint64(ret)
+}
+
+// get returns the entry with the given sequence number, or the first available one
+// if it has already been removed. When the entry has not been received yet,
+// it returns a channel that is closed when a new entry is available.
+func (b *timeshiftBuffer) get(seq uint64) (timeshiftEntry, uint64, chan struct{}) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if seq < b.firstSeq {
+		seq = b.firstSeq
+	}
+
+	i := seq - b.firstSeq
+	if i >= uint64(len(b.entries)) {
+		return timeshiftEntry{}, seq, b.newEntry
+	}
+
+	return b.entries[i], seq, nil
+}
+
+// timeshiftReader reads the entries of a timeshift buffer with a fixed delay,
+// starting from the nearest key frame.
+type timeshiftReader struct {
+	buffer *timeshiftBuffer
+	delay  time.Duration
+	write  func(*timeshiftEntry)
+
+	terminate chan struct{}
+	done      chan struct{}
+}
+
+func (r *timeshiftReader) start() {
+	r.terminate = make(chan struct{})
+	r.done = make(chan struct{})
+
+	go r.run()
+}
+
+func (r *timeshiftReader) close() {
+	close(r.terminate)
+	<-r.done
+}
+
+func (r *timeshiftReader) run() {
+	defer close(r.done)
+
+	seq := r.buffer.seek(time.Now().Add(-r.delay))
+
+	for {
+		e, curSeq, newEntry := r.buffer.get(seq)
+
+		if newEntry != nil {
+			select {
+			case <-newEntry:
+				continue
+			case <-r.terminate:
+				return
+			}
+		}
+
+		// entries are sent at the same pace they were received.
+		if wait := time.Until(e.recvTime.Add(r.delay)); wait > 0 {
+			t := time.NewTimer(wait)
+			select {
+			case <-t.C:
+			case <-r.terminate:
+				t.Stop()
+				return
+			}
+		}
+
+		r.write(&e)
+		seq = curSeq + 1
+	}
+}
@@ -0,0 +1,122 @@
+package stream
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bluenviron/gortsplib/v4/pkg/description"
+	"github.com/bluenviron/gortsplib/v4/pkg/format"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bluenviron/mediamtx/internal/unit"
+)
+
+func TestTimeshiftBufferPrune(t *testing.T) {
+	desc := &description.Session{Medias: []*description.Media{{
+		Type:    description.MediaTypeAudio,
+		Formats: []format.Format{&format.G711{PayloadTyp: 8, SampleRate: 8000, ChannelCount: 1}},
+	}}}
+
+	b := newTimeshiftBuffer(10*time.Second, desc)
+
+	t0 := time.Date(2015, 2, 5, 1, 2, 2, 0, time.UTC)
+
+	write := func(d time.Duration, size uint64) {
+		b.write(t0.Add(d), desc.Medias[0], desc.Medias[0].Formats[0], &unit.G711{
+			Base: unit.Base{PTS: d},
+		}, size)
+	}
+
+	write(0, 100)
+	write(5*time.Second, 200)
+	write(10*time.Second, 300)
+	require.Equal(t, uint64(600), b.memoryUsage())
+
+	write(11*time.Second, 400)
+	require.Equal(t, uint64(900), b.memoryUsage())
+
+	// removed entries are replaced by the first available one
+	e, seq, newEntry := b.get(0)
+	require.Nil(t, newEntry)
+	require.Equal(t, uint64(1), seq)
+	require.Equal(t, 5*time.Second, e.unit.GetPTS())
+
+	write(30*time.Second, 500)
+	require.Equal(t, uint64(500), b.memoryUsage())
+
+	e, seq, _ = b.get(0)
+	require.Equal(t, uint64(4), seq)
+	require.Equal(t, 30*time.Second, e.unit.GetPTS())
+
+	// entries that have not been received yet are signaled
+	_, seq, newEntry = b.get(5)
+	require.Equal(t, uint64(5), seq)
+	require.NotNil(t, newEntry)
+
+	write(31*time.Second, 600)
+
+	select {
+	case <-newEntry:
+	default:
+		t.Errorf("channel should be closed")
+	}
+
+	// without video formats, readers can start from any unit
+	require.Equal(t, uint64(4), b.seek(t0.Add(30*time.Second)))
+	require.Equal(t, uint64(5), b.seek(t0.Add(40*time.Second)))
+}
+
+func TestTimeshiftBufferSeek(t *testing.T) {
+	desc := &description.Session{Medias: []*description.Media{
+		{
+			Type:    description.MediaTypeVideo,
+			Formats: []format.Format{&format.H264{PayloadTyp: 96, PacketizationMode: 1}},
+		},
+		{
+			Type:    description.MediaTypeAudio,
+			Formats: []format.Format{&format.G711{PayloadTyp: 8, SampleRate: 8000, ChannelCount: 1}},
+		},
+	}}
+
+	b := newTimeshiftBuffer(time.Minute, desc)
+
+	t0 := time.Date(2015, 2, 5, 1, 2, 2, 0, time.UTC)
+
+	// no key frames yet
+	require.Equal(t, uint64(0), b.seek(t0))
+
+	for _, e := range []struct {
+		d     time.Duration
+		video bool
+		au    [][]byte
+	}{
+		{0, true, testNonIDR},
+		{1 * time.Second, true, testIDR},
+		{2 * time.Second, false, nil},
+		{3 * time.Second, true, testNonIDR},
+		{4 * time.Second, true, testIDR},
+		{5 * time.Second, false, nil},
+	} {
+		if e.video {
+			b.write(t0.Add(e.d), desc.Medias[0], desc.Medias[0].Formats[0], &unit.H264{AU: e.au}, 1)
+		} else {
+			b.write(t0.Add(e.d), desc.Medias[1], desc.Medias[1].Formats[0], &unit.G711{}, 1)
+		}
+	}
+
+	for _, ca := range []struct {
+		name string
+		t    time.Time
+		seq  uint64
+	}{
+		{"before first key frame", t0, 1},
+		{"on key frame", t0.Add(1 * time.Second), 1},
+		{"after key frame", t0.Add(3500 * time.Millisecond), 1},
+		{"on second key frame", t0.Add(4 * time.Second), 4},
+		{"after last entry", t0.Add(time.Minute), 4},
+	} {
+		t.Run(ca.name, func(t *testing.T) {
+			require.Equal(t, ca.seq, b.seek(ca.t))
+		})
+	}
+}
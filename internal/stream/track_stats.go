@@ -0,0 +1,269 @@
+package stream
+
+import (
+	"encoding/binary"
+	"sync"
+	"time"
+
+	"github.com/bluenviron/gortsplib/v4/pkg/format"
+	"github.com/pion/rtp"
+)
+
+const (
+	// window used to compute rolling statistics.
+	trackStatsWindow = 10 * time.Second
+
+	trackStatsBucketCount = int64(trackStatsWindow / time.Second)
+)
+
+// TrackStats are statistics of a track.
+type TrackStats struct {
+	// bitrate in bits per second, computed over the last second.
+	Bitrate1s uint64
+
+	// bitrate in bits per second, computed over the last 10 seconds.
+	Bitrate10s uint64
+
+	// frames per second, computed over the last 10 seconds.
+	// It is filled only for video tracks.
+	FPS float64
+
+	// number of frames and duration of the last complete GOP.
+	// They are filled only for video tracks.
+	GOPLength   uint64
+	GOPDuration time.Duration
+
+	// size of the biggest frame received in the last 10 seconds.
+	MaxFrameSize uint64
+
+	// number of errors that occurred while decoding the track.
+	DecodeErrors uint64
+
+	// audio level, computed over the last second.
+	// It is filled only for PCM-based tracks (G711, LPCM).
+	AudioLevel *AudioLevel
+}
+
+func h264NALUIsRandomAccess(typ byte) bool {
+	return typ == 5 // IDR
+}
+
+func h265NALUIsRandomAccess(typ byte) bool {
+	return typ >= 16 && typ <= 21 // BLA, IDR, CRA
+}
+
+// rtpRandomAccess returns whether RTP packets contain the beginning of a H264 or H265 key frame.
+// It allows to detect key frames of tracks that are not decoded.
+func rtpRandomAccess(forma format.Format, pkts []*rtp.Packet) bool {
+	for _, pkt := range pkts {
+		pl := pkt.Payload
+
+		switch forma.(type) {
+		case *format.H264:
+			if len(pl) < 1 {
+				continue
+			}
+
+			switch typ := pl[0] & 0x1F; typ {
+			case 24: // STAP-A
+				pl = pl[1:]
+				for len(pl) >= 3 {
+					size := int(binary.BigEndian.Uint16(pl))
+					if h264NALUIsRandomAccess(pl[2] & 0x1F) {
+						return true
+					}
+					if len(pl) < (2 + size) {
+						break
+					}
+					pl = pl[2+size:]
+				}
+
+			case 28: // FU-A
+				if len(pl) >= 2 && (pl[1]&0x80) != 0 && h264NALUIsRandomAccess(pl[1]&0x1F) {
+					return true
+				}
+
+			default:
+				if h264NALUIsRandomAccess(typ) {
+					return true
+				}
+			}
+
+		case *format.H265:
+			if len(pl) < 2 {
+				continue
+			}
+
+			switch typ := (pl[0] >> 1) & 0x3F; typ {
+			case 48: // aggregation packet
+				pl = pl[2:]
+				for len(pl) >= 3 {
+					size := int(binary.BigEndian.Uint16(pl))
+					if h265NALUIsRandomAccess((pl[2] >> 1) & 0x3F) {
+						return true
+					}
+					if len(pl) < (2 + size) {
+						break
+					}
+					pl = pl[2+size:]
+				}
+
+			case 49: // fragmentation unit
+				if len(pl) >= 3 && (pl[2]&0x80) != 0 && h265NALUIsRandomAccess(pl[2]&0x3F) {
+					return true
+				}
+
+			default:
+				if h265NALUIsRandomAccess(typ) {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
+
+// trackStatsBucket contains data received in a second.
+type trackStatsBucket struct {
+	second       int64
+	bytes        uint64
+	frames       uint64
+	maxFrameSize uint64
+}
+
+// trackStats computes statistics of a track.
+// Data is grouped into buckets of one second, in order to compute rolling statistics
+// without storing every unit.
+type trackStats struct {
+	isVideo bool
+
+	mutex           sync.Mutex
+	buckets         [trackStatsBucketCount]trackStatsBucket
+	firstSecond     int64
+	frameSize       uint64
+	frameRandom     bool
+	gopFrames       uint64
+	gopStart        time.Duration
+	gopStartFound   bool
+	lastGOPLength   uint64
+	lastGOPDuration time.Duration
+	decodeErrors    uint64
+}
+
+// addUnit adds a unit.
+// A video frame can be split into multiple units when the track is not decoded,
+// therefore frames are delimited by frameEnd.
+func (ts *trackStats) addUnit(
+	now time.Time,
+	size uint64,
+	pts time.Duration,
+	frameEnd bool,
+	randomAccess bool,
+) {
+	ts.mutex.Lock()
+	defer ts.mutex.Unlock()
+
+	second := now.Unix()
+	if ts.firstSecond == 0 {
+		ts.firstSecond = second
+	}
+
+	b := &ts.buckets[second%trackStatsBucketCount]
+	if b.second != second {
+		*b = trackStatsBucket{second: second}
+	}
+
+	b.bytes += size
+	ts.frameSize += size
+	ts.frameRandom = ts.frameRandom || randomAccess
+
+	if !frameEnd {
+		return
+	}
+
+	b.frames++
+	if ts.frameSize > b.maxFrameSize {
+		b.maxFrameSize = ts.frameSize
+	}
+
+	randomAccess = ts.frameRandom
+	ts.frameSize = 0
+	ts.frameRandom = false
+
+	if !ts.isVideo {
+		return
+	}
+
+	if randomAccess {
+		if ts.gopStartFound {
+			ts.lastGOPLength = ts.gopFrames
+			ts.lastGOPDuration = pts - ts.gopStart
+		}
+
+		ts.gopStartFound = true
+		ts.gopStart = pts
+		ts.gopFrames = 0
+	}
+
+	ts.gopFrames++
+}
+
+func (ts *trackStats) addDecodeError() {
+	ts.mutex.Lock()
+	defer ts.mutex.Unlock()
+
+	ts.decodeErrors++
+}
+
+// stats returns statistics computed on seconds that are complete.
+func (ts *trackStats) stats(now time.Time) TrackStats {
+	ts.mutex.Lock()
+	defer ts.mutex.Unlock()
+
+	ret := TrackStats{
+		GOPLength:    ts.lastGOPLength,
+		GOPDuration:  ts.lastGOPDuration,
+		DecodeErrors: ts.decodeErrors,
+	}
+
+	if ts.firstSecond == 0 {
+		return ret
+	}
+
+	second := now.Unix()
+
+	// at startup, compute rates over the time passed since the first unit
+	seconds := min(second-ts.firstSecond, trackStatsBucketCount)
+	if seconds == 0 {
+		return ret
+	}
+
+	var bytes uint64
+	var frames uint64
+
+	for i := int64(1); i <= seconds; i++ {
+		b := &ts.buckets[(second-i)%trackStatsBucketCount]
+		if b.second != (second - i) {
+			continue
+		}
+
+		if i == 1 {
+			ret.Bitrate1s = b.bytes * 8
+		}
+
+		bytes += b.bytes
+		frames += b.frames
+		if b.maxFrameSize > ret.MaxFrameSize {
+			ret.MaxFrameSize = b.maxFrameSize
+		}
+	}
+
+	ret.Bitrate10s = bytes * 8 / uint64(seconds)
+
+	if ts.isVideo {
+		ret.FPS = float64(frames) / float64(seconds)
+	}
+
+	return ret
+}
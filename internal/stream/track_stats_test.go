@@ -0,0 +1,228 @@
+package stream
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bluenviron/gortsplib/v4/pkg/format"
+	"github.com/pion/rtp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrackStatsStartup(t *testing.T) {
+	ts := &trackStats{isVideo: true}
+
+	t0 := time.Unix(1000, 0)
+
+	require.Equal(t, TrackStats{}, ts.stats(t0))
+
+	for i := 0; i < 30; i++ {
+		d := time.Duration(i) * 100 * time.Millisecond
+		ts.addUnit(t0.Add(d), 100, d, true, false)
+	}
+
+	// the current second is not complete
+	require.Equal(t, TrackStats{}, ts.stats(t0.Add(500*time.Millisecond)))
+
+	// rates are computed over the time passed since the first unit
+	require.Equal(t, TrackStats{
+		Bitrate1s:    8000,
+		Bitrate10s:   8000,
+		FPS:          10,
+		MaxFrameSize: 100,
+	}, ts.stats(t0.Add(1*time.Second)))
+
+	require.Equal(t, TrackStats{
+		Bitrate1s:    8000,
+		Bitrate10s:   8000,
+		FPS:          10,
+		MaxFrameSize: 100,
+	}, ts.stats(t0.Add(3*time.Second)))
+}
+
+func TestTrackStatsRollover(t *testing.T) {
+	ts := &trackStats{}
+
+	t0 := time.Unix(1000, 0)
+
+	// one unit per second, with increasing size
+	for i := 0; i < 15; i++ {
+		d := time.Duration(i) * time.Second
+		ts.addUnit(t0.Add(d), uint64(100*(i+1)), d, true, false)
+	}
+
+	// seconds 5 to 14
+	require.Equal(t, TrackStats{
+		Bitrate1s:    1500 * 8,
+		Bitrate10s:   8400, // (600 + 700 + ... + 1500) * 8 / 10
+		MaxFrameSize: 1500,
+	}, ts.stats(t0.Add(15*time.Second)))
+
+	// buckets of seconds without data are not reused
+	require.Equal(t, TrackStats{
+		Bitrate1s:    0,
+		Bitrate10s:   5200, // (1100 + 1200 + ... + 1500) * 8 / 10
+		MaxFrameSize: 1500,
+	}, ts.stats(t0.Add(20*time.Second)))
+
+	require.Equal(t, TrackStats{}, ts.stats(t0.Add(25*time.Second)))
+
+	// new data is written into buckets of past seconds
+	ts.addUnit(t0.Add(25*time.Second), 200, 25*time.Second, true, false)
+
+	require.Equal(t, TrackStats{
+		Bitrate1s:    1600,
+		Bitrate10s:   160,
+		MaxFrameSize: 200,
+	}, ts.stats(t0.Add(26*time.Second)))
+}
+
+func TestTrackStatsGOP(t *testing.T) {
+	ts := &trackStats{isVideo: true}
+
+	t0 := time.Unix(1000, 0)
+
+	addFrame := func(d time.Duration, randomAccess bool) {
+		// frames are split into two units, the first one contains the random access flag
+		ts.addUnit(t0.Add(d), 50, d, false, randomAccess)
+		ts.addUnit(t0.Add(d), 150, d, true, false)
+	}
+
+	// frames that precede the first key frame are ignored
+	addFrame(0, false)
+	addFrame(100*time.Millisecond, false)
+
+	for i := 2; i < 27; i++ {
+		addFrame(time.Duration(i)*100*time.Millisecond, i%12 == 2)
+	}
+
+	// key frames at 0.2s, 1.4s and 2.6s
+	st := ts.stats(t0.Add(2 * time.Second))
+	require.Equal(t, uint64(12), st.GOPLength)
+	require.Equal(t, 1200*time.Millisecond, st.GOPDuration)
+	require.Equal(t, uint64(200), st.MaxFrameSize)
+	require.Equal(t, float64(10), st.FPS)
+
+	// statistics of audio tracks don't include frame rate and GOPs
+	ts = &trackStats{}
+
+	addFrame(0, true)
+	addFrame(time.Second, true)
+
+	st = ts.stats(t0.Add(2 * time.Second))
+	require.Equal(t, TrackStats{
+		Bitrate1s:    1600,
+		Bitrate10s:   1600,
+		MaxFrameSize: 200,
+	}, st)
+}
+
+func TestRTPRandomAccess(t *testing.T) {
+	for _, ca := range []struct {
+		name    string
+		format  format.Format
+		payload []byte
+		ok      bool
+	}{
+		{
+			"h264 idr",
+			&format.H264{},
+			[]byte{0x65, 0x88},
+			true,
+		},
+		{
+			"h264 non-idr",
+			&format.H264{},
+			[]byte{0x41, 0x9a},
+			false,
+		},
+		{
+			"h264 stap-a with idr",
+			&format.H264{},
+			[]byte{0x18, 0x00, 0x02, 0x67, 0x42, 0x00, 0x02, 0x65, 0x88},
+			true,
+		},
+		{
+			"h264 stap-a without idr",
+			&format.H264{},
+			[]byte{0x18, 0x00, 0x02, 0x67, 0x42, 0x00, 0x02, 0x68, 0xce},
+			false,
+		},
+		{
+			"h264 stap-a truncated",
+			&format.H264{},
+			[]byte{0x18, 0x00, 0x10, 0x67, 0x42},
+			false,
+		},
+		{
+			"h264 fu-a start of idr",
+			&format.H264{},
+			[]byte{0x7c, 0x85, 0x88},
+			true,
+		},
+		{
+			"h264 fu-a continuation of idr",
+			&format.H264{},
+			[]byte{0x7c, 0x05, 0x88},
+			false,
+		},
+		{
+			"h264 fu-a start of non-idr",
+			&format.H264{},
+			[]byte{0x7c, 0x81, 0x9a},
+			false,
+		},
+		{
+			"h265 idr",
+			&format.H265{},
+			[]byte{0x26, 0x01, 0xaf},
+			true,
+		},
+		{
+			"h265 cra",
+			&format.H265{},
+			[]byte{0x2a, 0x01, 0xaf},
+			true,
+		},
+		{
+			"h265 trail",
+			&format.H265{},
+			[]byte{0x02, 0x01, 0xd0},
+			false,
+		},
+		{
+			"h265 aggregation packet with idr",
+			&format.H265{},
+			[]byte{0x60, 0x01, 0x00, 0x03, 0x40, 0x01, 0x0c, 0x00, 0x03, 0x26, 0x01, 0xaf},
+			true,
+		},
+		{
+			"h265 aggregation packet without idr",
+			&format.H265{},
+			[]byte{0x60, 0x01, 0x00, 0x03, 0x40, 0x01, 0x0c, 0x00, 0x03, 0x02, 0x01, 0xd0},
+			false,
+		},
+		{
+			"h265 fragmentation unit start of idr",
+			&format.H265{},
+			[]byte{0x62, 0x01, 0x93, 0xaf},
+			true,
+		},
+		{
+			"h265 fragmentation unit continuation of idr",
+			&format.H265{},
+			[]byte{0x62, 0x01, 0x13, 0xaf},
+			false,
+		},
+		{
+			"other format",
+			&format.VP8{},
+			[]byte{0x10, 0x00},
+			false,
+		},
+	} {
+		t.Run(ca.name, func(t *testing.T) {
+			require.Equal(t, ca.ok, rtpRandomAccess(ca.format, []*rtp.Packet{{Payload: ca.payload}}))
+		})
+	}
+}
@@ -11,6 +11,9 @@ type Base struct {
 	RTPPackets []*rtp.Packet
 	NTP        time.Time
 	PTS        time.Duration
+
+	// whether timestamps have been rebased since they jumped with respect to the previous unit.
+	Discontinuity bool
 }
 
 // GetRTPPackets implements Unit.
@@ -27,3 +30,18 @@ func (u *Base) GetNTP() time.Time {
 func (u *Base) GetPTS() time.Duration {
 	return u.PTS
 }
+
+// SetPTS implements Unit.
+func (u *Base) SetPTS(v time.Duration) {
+	u.PTS = v
+}
+
+// GetDiscontinuity implements Unit.
+func (u *Base) GetDiscontinuity() bool {
+	return u.Discontinuity
+}
+
+// SetDiscontinuity implements Unit.
+func (u *Base) SetDiscontinuity(v bool) {
+	u.Discontinuity = v
+}
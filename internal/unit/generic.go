@@ -3,4 +3,8 @@ package unit
 // Generic is a generic data unit.
 type Generic struct {
 	Base
+
+	// content of metadata units (KLV, SCTE-35).
+	// It is filled only when the format is a metadata format.
+	Payload []byte
 }
@@ -1,7 +1,14 @@
 package unit
 
+import (
+	"github.com/bluenviron/mediamtx/internal/captions"
+)
+
 // H264 is a H264 data unit.
 type H264 struct {
 	Base
 	AU [][]byte
+
+	// closed captions contained in SEI NALUs.
+	Captions []captions.CCData
 }
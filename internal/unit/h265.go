@@ -1,7 +1,14 @@
 package unit
 
+import (
+	"github.com/bluenviron/mediamtx/internal/captions"
+)
+
 // H265 is a H265 data unit.
 type H265 struct {
 	Base
 	AU [][]byte
+
+	// closed captions contained in SEI NALUs.
+	Captions []captions.CCData
 }
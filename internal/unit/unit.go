@@ -17,4 +17,13 @@ type Unit interface {
 
 	// returns the PTS of the unit.
 	GetPTS() time.Duration
+
+	// sets the PTS of the unit.
+	SetPTS(time.Duration)
+
+	// returns whether there's a timestamp discontinuity before the unit.
+	GetDiscontinuity() bool
+
+	// sets whether there's a timestamp discontinuity before the unit.
+	SetDiscontinuity(bool)
 }
@@ -0,0 +1,192 @@
+// Package webhook contains a webhook sender.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bluenviron/mediamtx/internal/conf"
+	"github.com/bluenviron/mediamtx/internal/logger"
+)
+
+const (
+	retryMinPause = 1 * time.Second
+	retryMaxPause = 30 * time.Second
+)
+
+// IsURL checks whether a hook is a webhook URL instead of a command.
+func IsURL(v string) bool {
+	return strings.HasPrefix(v, "http://") || strings.HasPrefix(v, "https://")
+}
+
+// Sign computes the signature of a payload.
+func Sign(secret string, body []byte) string {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write(body)
+	return "sha256=" + hex.EncodeToString(h.Sum(nil))
+}
+
+// Payload is the body of a webhook request.
+type Payload struct {
+	Event string            `json:"event"`
+	Time  time.Time         `json:"time"`
+	Env   map[string]string `json:"env"`
+}
+
+type request struct {
+	url     string
+	event   string
+	payload []byte
+}
+
+type senderParent interface {
+	logger.Writer
+}
+
+// Sender sends webhooks.
+// Requests are put in a bounded queue and are delivered in order
+// by a single routine, with retries and exponential backoff.
+type Sender struct {
+	Secret     string
+	Timeout    conf.StringDuration
+	MaxRetries int
+	QueueSize  int
+	Parent     senderParent
+
+	ctx       context.Context
+	ctxCancel func()
+	client    *http.Client
+	queue     chan *request
+
+	done chan struct{}
+}
+
+// Initialize initializes a Sender.
+func (s *Sender) Initialize() {
+	s.ctx, s.ctxCancel = context.WithCancel(context.Background())
+	s.client = &http.Client{
+		Timeout: time.Duration(s.Timeout),
+	}
+	s.queue = make(chan *request, s.QueueSize)
+	s.done = make(chan struct{})
+
+	go s.run()
+}
+
+// Close closes the Sender.
+// Requests that are still in queue are delivered once, without retries.
+func (s *Sender) Close() {
+	s.ctxCancel()
+	<-s.done
+}
+
+// Log implements logger.Writer.
+func (s *Sender) Log(level logger.Level, format string, args ...interface{}) {
+	s.Parent.Log(level, "[webhook] "+format, args...)
+}
+
+func (s *Sender) run() {
+	defer close(s.done)
+
+	for {
+		select {
+		case req := <-s.queue:
+			s.deliver(req, s.MaxRetries)
+
+		case <-s.ctx.Done():
+			for {
+				select {
+				case req := <-s.queue:
+					s.deliver(req, 0)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (s *Sender) deliver(req *request, maxRetries int) {
+	pause := retryMinPause
+
+	for attempt := 0; ; attempt++ {
+		err := s.post(req)
+		if err == nil {
+			return
+		}
+
+		if attempt >= maxRetries {
+			s.Log(logger.Warn, "unable to send '%s' event to %s: %v", req.event, req.url, err)
+			return
+		}
+
+		s.Log(logger.Debug, "unable to send '%s' event to %s: %v, retrying in %v", req.event, req.url, err, pause)
+
+		select {
+		case <-time.After(pause):
+		case <-s.ctx.Done():
+			maxRetries = 0
+		}
+
+		pause *= 2
+		if pause > retryMaxPause {
+			pause = retryMaxPause
+		}
+	}
+}
+
+func (s *Sender) post(req *request) error {
+	hreq, err := http.NewRequest(http.MethodPost, req.url, bytes.NewReader(req.payload))
+	if err != nil {
+		return err
+	}
+
+	hreq.Header.Set("Content-Type", "application/json")
+	hreq.Header.Set("X-MTX-Event", req.event)
+	if s.Secret != "" {
+		hreq.Header.Set("X-MTX-Signature", Sign(s.Secret, req.payload))
+	}
+
+	res, err := s.client.Do(hreq)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	io.Copy(io.Discard, res.Body) //nolint:errcheck
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("server replied with code %d", res.StatusCode)
+	}
+
+	return nil
+}
+
+// Send enqueues a webhook request.
+// It doesn't wait for the request to be delivered.
+func (s *Sender) Send(url string, event string, env map[string]string) {
+	byts, _ := json.Marshal(Payload{
+		Event: event,
+		Time:  time.Now(),
+		Env:   env,
+	})
+
+	select {
+	case s.queue <- &request{
+		url:     url,
+		event:   event,
+		payload: byts,
+	}:
+	default:
+		s.Log(logger.Warn, "queue is full, discarding '%s' event", event)
+	}
+}
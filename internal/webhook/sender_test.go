@@ -0,0 +1,86 @@
+package webhook
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bluenviron/mediamtx/internal/conf"
+	"github.com/bluenviron/mediamtx/internal/logger"
+)
+
+type nilLogger struct{}
+
+func (nilLogger) Log(logger.Level, string, ...interface{}) {
+}
+
+func TestSender(t *testing.T) {
+	received := make(chan *Payload)
+	attempts := 0
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, "ready", r.Header.Get("X-MTX-Event"))
+
+		byts, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.Equal(t, Sign("mysecret", byts), r.Header.Get("X-MTX-Signature"))
+
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		var p Payload
+		err = json.Unmarshal(byts, &p)
+		require.NoError(t, err)
+		received <- &p
+	}))
+	defer ts.Close()
+
+	s := &Sender{
+		Secret:     "mysecret",
+		Timeout:    conf.StringDuration(5 * time.Second),
+		MaxRetries: 1,
+		QueueSize:  4,
+		Parent:     nilLogger{},
+	}
+	s.Initialize()
+	defer s.Close()
+
+	s.Send(ts.URL, "ready", map[string]string{
+		"MTX_PATH":      "mypath",
+		"MTX_SOURCE_ID": "123",
+	})
+
+	p := <-received
+	require.Equal(t, "ready", p.Event)
+	require.Equal(t, map[string]string{
+		"MTX_PATH":      "mypath",
+		"MTX_SOURCE_ID": "123",
+	}, p.Env)
+	require.Equal(t, 2, attempts)
+}
+
+func TestSenderQueueFull(t *testing.T) {
+	s := &Sender{
+		Timeout:    conf.StringDuration(5 * time.Second),
+		MaxRetries: 0,
+		QueueSize:  1,
+		Parent:     nilLogger{},
+	}
+	s.queue = make(chan *request, s.QueueSize)
+
+	s.Send("http://localhost:9999", "ready", nil)
+	s.Send("http://localhost:9999", "notReady", nil)
+
+	require.Equal(t, 1, len(s.queue))
+	req := <-s.queue
+	require.Equal(t, "ready", req.event)
+}